@@ -0,0 +1,11 @@
+#!/bin/sh
+# Installed as /usr/local/bin/curl in the test containers, ahead of the real curl in the PATH. When the
+# artifacts fetched by docker/airgap/fetch.sh are mounted, the install script requested from
+# https://get.k3s.io is replaced by get-k3s.sh, every other request goes to the real curl.
+for arg in "$@"; do
+    if [ "$arg" = "https://get.k3s.io" ] && [ -f /opt/k3s-airgap/install.sh ]; then
+        exec cat /usr/local/share/k3s-airgap/get-k3s.sh
+    fi
+done
+
+exec /usr/bin/curl "$@"
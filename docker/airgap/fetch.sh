@@ -0,0 +1,42 @@
+#!/bin/sh
+# Downloads the K3S install script, together with the binary and the airgap images of every given version,
+# into docker/airgap/artifacts. The directory is mounted in the test containers, which then install K3S
+# from it without network access. Versions already downloaded are skipped.
+#
+# Usage: docker/airgap/fetch.sh [version...]
+set -e
+
+ARCH="${ARCH:-amd64}"
+ARTIFACTS="$(cd "$(dirname "$0")" && pwd)/artifacts"
+
+if [ "$#" -eq 0 ]; then
+    set -- v1.30.6+k3s1 v1.31.2+k3s1
+fi
+
+mkdir -p "$ARTIFACTS"
+if [ ! -f "$ARTIFACTS/install.sh" ]; then
+    curl -sfL https://get.k3s.io -o "$ARTIFACTS/install.sh"
+fi
+
+binary=k3s
+if [ "$ARCH" != "amd64" ]; then
+    binary="k3s-$ARCH"
+fi
+
+for version in "$@"; do
+    release="https://github.com/k3s-io/k3s/releases/download/$(echo "$version" | sed 's/+/%2B/')"
+    mkdir -p "$ARTIFACTS/$version"
+
+    if [ ! -f "$ARTIFACTS/$version/k3s" ]; then
+        echo "Downloading the K3S $version binary"
+        curl -sfL "$release/$binary" -o "$ARTIFACTS/$version/k3s.part"
+        mv "$ARTIFACTS/$version/k3s.part" "$ARTIFACTS/$version/k3s"
+    fi
+
+    images="k3s-airgap-images-$ARCH.tar.zst"
+    if [ ! -f "$ARTIFACTS/$version/$images" ]; then
+        echo "Downloading the K3S $version airgap images"
+        curl -sfL "$release/$images" -o "$ARTIFACTS/$version/$images.part"
+        mv "$ARTIFACTS/$version/$images.part" "$ARTIFACTS/$version/$images"
+    fi
+done
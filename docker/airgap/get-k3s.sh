@@ -0,0 +1,24 @@
+#!/bin/sh
+# Piped into "sh -s -" in place of the script served by https://get.k3s.io. Installs the binary and the
+# airgap images of INSTALL_K3S_VERSION from /opt/k3s-airgap, then runs the install script without
+# downloading anything.
+set -e
+
+ARTIFACTS=/opt/k3s-airgap
+VERSION="${INSTALL_K3S_VERSION:?INSTALL_K3S_VERSION must be set to install K3S from the airgap artifacts}"
+
+if [ ! -f "$ARTIFACTS/$VERSION/k3s" ]; then
+    echo "[ERROR]  No airgap artifacts for K3S $VERSION, run docker/airgap/fetch.sh $VERSION" >&2
+    exit 1
+fi
+
+SUDO=sudo
+if [ "$(id -u)" -eq 0 ]; then
+    SUDO=
+fi
+
+$SUDO mkdir -p /var/lib/rancher/k3s/agent/images
+$SUDO install -m 0755 "$ARTIFACTS/$VERSION/k3s" /usr/local/bin/k3s
+$SUDO cp "$ARTIFACTS/$VERSION"/k3s-airgap-images-* /var/lib/rancher/k3s/agent/images/
+
+INSTALL_K3S_SKIP_DOWNLOAD=true exec sh "$ARTIFACTS/install.sh" "$@"
@@ -19,7 +19,7 @@ resource "yoshik3s_cluster" "example_cluster" {
 
 
 resource "yoshik3s_master_node" "example_master_node" {
-  cluster = yoshik3s_cluster.example_cluster
+  cluster_id = yoshik3s_cluster.example_cluster.id
 
   node_connection = {
     host     = "localhost"
@@ -47,7 +47,7 @@ locals {
 
 
 resource "yoshik3s_worker_node" "example_worker_node" {
-  cluster = yoshik3s_cluster.example_cluster
+  cluster_id = yoshik3s_cluster.example_cluster.id
 
   for_each = local.example_cluster_workers
 
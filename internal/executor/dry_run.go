@@ -0,0 +1,286 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/remote"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const redacted = "<redacted>"
+
+// sensitiveOptionNames are the words identifying the K3S flags whose value is a secret, e.g. --token or
+// --etcd-s3-secret-key.
+var sensitiveOptionNames = []string{"token", "secret", "password", "key"}
+
+var _ Executor = &DryRunExecutor{}
+
+// DryRunExecutor records the operations changing the hosts in the provider logs, and optionally in a transcript
+// file, instead of performing them. Status and ReadFile only inspect the hosts, they are delegated to the wrapped
+// executor so that plans keep reflecting the hosts, as well as the commands run with Query.
+type DryRunExecutor struct {
+	executor   Executor
+	transcript string
+
+	mutex sync.Mutex
+}
+
+// NewDryRunExecutor wraps the executor, appending the recorded operations to the transcript file when its path
+// is not empty.
+func NewDryRunExecutor(executor Executor, transcript string) *DryRunExecutor {
+	return &DryRunExecutor{
+		executor:   executor,
+		transcript: transcript,
+	}
+}
+
+// Operation is an operation recorded by a dry run.
+type Operation struct {
+	// Description tells what the operation would do, e.g. "install k3s".
+	Description string
+	// Commands are the commands that would run, in order.
+	Commands []string
+	// Env are the environment variables the commands would run with.
+	Env map[string]string
+	// Content is the content of the uploaded file.
+	Content string
+}
+
+func (e *DryRunExecutor) Install(ctx context.Context, host *ssh_handler.SshConfig, install Install) ([]byte, error) {
+	env, err := installEnv(install, redacted)
+	if err != nil {
+		return nil, err
+	}
+
+	commands := []string{
+		installCommand(ctx, host, env, installArgs(install, redactOptions(install.Options))),
+	}
+	if install.Role == remote.NodeRoleServer {
+		commands = append(commands, copyKubeconfigCommand, readKubeconfigCommand)
+	}
+
+	e.Record(ctx, host, Operation{
+		Description: fmt.Sprintf("install k3s %s as a %s", install.Version, install.Role),
+		Commands:    commands,
+		Env:         env,
+	})
+
+	// No kubeconfig is available since the cluster was not installed.
+	return nil, nil
+}
+
+func (e *DryRunExecutor) Uninstall(ctx context.Context, host *ssh_handler.SshConfig, role remote.NodeRole) error {
+	command, err := uninstallCommand(ctx, host, role)
+	if err != nil {
+		return err
+	}
+
+	e.Record(ctx, host, Operation{
+		Description: fmt.Sprintf("uninstall the k3s %s", role),
+		Commands:    []string{command},
+	})
+
+	return nil
+}
+
+func (e *DryRunExecutor) Status(ctx context.Context, host *ssh_handler.SshConfig) (*remote.NodeInfo, error) {
+	return e.executor.Status(ctx, host)
+}
+
+func (e *DryRunExecutor) ReadFile(ctx context.Context, host *ssh_handler.SshConfig, filePath string) ([]byte, error) {
+	return e.executor.ReadFile(ctx, host, filePath)
+}
+
+func (e *DryRunExecutor) WriteFile(ctx context.Context, host *ssh_handler.SshConfig, filePath string, content []byte, mode os.FileMode) error {
+	if content == nil {
+		e.Record(ctx, host, Operation{
+			Description: "remove " + filePath,
+			Commands:    []string{"rm -f " + remote.ShellQuote(filePath)},
+		})
+		return nil
+	}
+
+	// Files that only root can read hold secrets, e.g. the agent token or the private keys of the certificate
+	// authorities, their content is left out.
+	recordedContent := string(content)
+	if mode.Perm()&0044 == 0 {
+		recordedContent = redacted
+	}
+
+	e.Record(ctx, host, Operation{
+		Description: fmt.Sprintf("write %s with mode %04o (%d bytes)", filePath, mode.Perm(), len(content)),
+		Content:     recordedContent,
+	})
+	return nil
+}
+
+func (e *DryRunExecutor) UploadFile(ctx context.Context, host *ssh_handler.SshConfig, filePath string, content []byte, mode os.FileMode, owner string) error {
+	recordedContent := string(content)
+	if mode.Perm()&0044 == 0 {
+		recordedContent = redacted
+	}
+
+	e.Record(ctx, host, Operation{
+		Description: fmt.Sprintf("upload %s with mode %04o and owner %s (%d bytes)", filePath, mode.Perm(), owner, len(content)),
+		Content:     recordedContent,
+	})
+	return nil
+}
+
+func (e *DryRunExecutor) Query(ctx context.Context, host *ssh_handler.SshConfig, command string, env map[string]string) ([]byte, error) {
+	return e.executor.Query(ctx, host, command, env)
+}
+
+// Run records the command with the values of its environment variables redacted, they only hold secrets such as
+// the cluster token or the S3 credentials. No output is returned.
+func (e *DryRunExecutor) Run(ctx context.Context, host *ssh_handler.SshConfig, command string, env map[string]string) ([]byte, error) {
+	if len(env) > 0 {
+		redactedEnv := make(map[string]string, len(env))
+		for name := range env {
+			redactedEnv[name] = redacted
+		}
+		env = redactedEnv
+		command = renderEnv(env) + " " + command
+	}
+
+	e.Record(ctx, host, Operation{
+		Description: "run a command as root",
+		Commands:    []string{command},
+		Env:         env,
+	})
+	return nil, nil
+}
+
+// Record logs the operation as not performed on the host, and appends it to the transcript file.
+func (e *DryRunExecutor) Record(ctx context.Context, host *ssh_handler.SshConfig, operation Operation) {
+	address := hostAddress(host)
+
+	fields := map[string]interface{}{
+		"host": address,
+	}
+	if len(operation.Commands) > 0 {
+		fields["commands"] = operation.Commands
+	}
+	if len(operation.Env) > 0 {
+		fields["env"] = operation.Env
+	}
+	if operation.Content != "" {
+		fields["content"] = operation.Content
+	}
+	tflog.Info(ctx, "dry run, not performed: "+operation.Description, fields)
+
+	if e.transcript == "" {
+		return
+	}
+
+	if err := e.appendTranscript(address, operation); err != nil {
+		tflog.Warn(ctx, "failed to write the dry run transcript", map[string]interface{}{
+			"path":  e.transcript,
+			"error": err.Error(),
+		})
+	}
+}
+
+func (e *DryRunExecutor) appendTranscript(address string, operation Operation) error {
+	var entry strings.Builder
+	fmt.Fprintf(&entry, "# %s %s: %s\n", time.Now().UTC().Format(time.RFC3339), address, operation.Description)
+	for _, command := range operation.Commands {
+		fmt.Fprintf(&entry, "$ %s\n", command)
+	}
+	if operation.Content != "" {
+		entry.WriteString(operation.Content)
+		if !strings.HasSuffix(operation.Content, "\n") {
+			entry.WriteString("\n")
+		}
+	}
+	entry.WriteString("\n")
+
+	// Resources are applied concurrently, the entries are written whole.
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	file, err := os.OpenFile(e.transcript, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.WriteString(entry.String())
+	return err
+}
+
+func hostAddress(host *ssh_handler.SshConfig) string {
+	if host == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s@%s:%s", host.GetUser(), host.GetHost(), host.GetPort())
+}
+
+// renderEnv renders the environment variables as the assignments prefixing a command, sorted by name.
+func renderEnv(env map[string]string) string {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	assignments := make([]string, 0, len(names))
+	for _, name := range names {
+		assignments = append(assignments, fmt.Sprintf("%s=%q", name, env[name]))
+	}
+
+	return strings.Join(assignments, " ")
+}
+
+// redactOptions replaces the values of the K3S flags holding secrets, given either as "--flag value" or "--flag=value".
+func redactOptions(options []string) []string {
+	redactedOptions := make([]string, 0, len(options))
+	for _, option := range options {
+		flag, separator, _, hasValue := splitOption(option)
+		if hasValue && isSensitiveOption(flag) {
+			option = flag + separator + redacted
+		}
+		redactedOptions = append(redactedOptions, option)
+	}
+
+	return redactedOptions
+}
+
+// sensitiveOptionValues returns the values of the K3S flags holding secrets.
+func sensitiveOptionValues(options []string) []string {
+	var values []string
+	for _, option := range options {
+		flag, _, value, hasValue := splitOption(option)
+		if hasValue && isSensitiveOption(flag) {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+// splitOption splits an option given either as "--flag value" or "--flag=value".
+func splitOption(option string) (string, string, string, bool) {
+	if flag, value, hasValue := strings.Cut(option, " "); hasValue {
+		return flag, " ", value, true
+	}
+
+	flag, value, hasValue := strings.Cut(option, "=")
+	return flag, "=", value, hasValue
+}
+
+func isSensitiveOption(flag string) bool {
+	name := strings.ToLower(strings.TrimLeft(flag, "-"))
+	for _, sensitive := range sensitiveOptionNames {
+		if strings.Contains(name, sensitive) {
+			return true
+		}
+	}
+	return false
+}
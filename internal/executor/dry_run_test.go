@@ -0,0 +1,80 @@
+package executor
+
+import (
+	"context"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/remote"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/sshtest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDryRunExecutor(t *testing.T) {
+	ctx := context.Background()
+	server := sshtest.NewServer(t)
+	server.Respond(`^if \[ -f /etc/systemd/system/k3s.service \]`, "none\n")
+
+	transcript := filepath.Join(t.TempDir(), "transcript.log")
+	dryRun := NewDryRunExecutor(NewYoshiK3SExecutor(), transcript)
+
+	kubeconfig, err := dryRun.Install(ctx, testHost(server), Install{
+		Role:    remote.NodeRoleServer,
+		Version: "v1.30.2+k3s2",
+		Token:   "cluster-secret",
+		Address: "10.0.0.1",
+		Options: []string{"--disable traefik", "--etcd-s3-secret-key s3-credential", "--agent-token=agent-secret"},
+	})
+	if err != nil || kubeconfig != nil {
+		t.Fatalf("expected no kubeconfig nor error, got: %q, %v", kubeconfig, err)
+	}
+	if err := dryRun.WriteFile(ctx, testHost(server), remote.AgentTokenConfigPath, []byte("agent-token: agent-secret\n"), 0600); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := dryRun.WriteFile(ctx, testHost(server), "/var/lib/rancher/k3s/server/tls/server-ca.crt", []byte("certificate\n"), 0644); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := dryRun.WriteFile(ctx, testHost(server), remote.EtcdSnapshotsConfigPath, nil, 0); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := dryRun.UploadFile(ctx, testHost(server), "/etc/rancher/k3s/registries.yaml", []byte("mirrors: {}\n"), 0644, "root:root"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := dryRun.Run(ctx, testHost(server), "k3s etcd-snapshot save --etcd-s3", map[string]string{"AWS_SECRET_ACCESS_KEY": "s3-credential"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := dryRun.Uninstall(ctx, testHost(server), remote.NodeRoleAgent); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	info, err := dryRun.Status(ctx, testHost(server))
+	if err != nil || info.Role != remote.NodeRoleNone {
+		t.Fatalf("expected the status to be read from the host, got: %v, %v", info, err)
+	}
+	if commands := server.Commands(); len(commands) != 1 {
+		t.Errorf("expected only the status to be read from the host, got: %v", commands)
+	}
+
+	content, err := os.ReadFile(transcript)
+	if err != nil {
+		t.Fatalf("failed to read the transcript: %s", err)
+	}
+	for _, expected := range []string{
+		`$ curl -sfL https://get.k3s.io | INSTALL_K3S_VERSION="v1.30.2+k3s2" K3S_KUBECONFIG_MODE="644" K3S_TOKEN="<redacted>" sh -s - --tls-san 10.0.0.1 server --disable traefik --etcd-s3-secret-key <redacted> --agent-token=<redacted>`,
+		"write " + remote.AgentTokenConfigPath + " with mode 0600 (26 bytes)\n<redacted>\n",
+		"server-ca.crt with mode 0644 (12 bytes)\ncertificate\n",
+		"$ rm -f '" + remote.EtcdSnapshotsConfigPath + "'",
+		"upload /etc/rancher/k3s/registries.yaml with mode 0644 and owner root:root (12 bytes)\nmirrors: {}\n",
+		`$ AWS_SECRET_ACCESS_KEY="<redacted>" k3s etcd-snapshot save --etcd-s3`,
+		"$ sudo k3s-agent-uninstall.sh",
+	} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("expected the transcript to contain %q, got:\n%s", expected, content)
+		}
+	}
+	for _, secret := range []string{"cluster-secret", "s3-credential", "agent-secret"} {
+		if strings.Contains(string(content), secret) {
+			t.Errorf("expected %q to be redacted from the transcript, got:\n%s", secret, content)
+		}
+	}
+}
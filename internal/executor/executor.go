@@ -0,0 +1,89 @@
+// Package executor defines the operations the node resources perform on their hosts, allowing the resources to
+// be tested with fakes and the operations to be carried out differently than by running them over SSH.
+package executor
+
+import (
+	"context"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/remote"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"os"
+)
+
+// Executor performs the K3S operations of the node resources on a host.
+type Executor interface {
+	// Install runs the K3S install script, installing K3S or reconfiguring an existing installation. It returns
+	// the kubeconfig of the cluster when installing a server, and nil when installing an agent.
+	Install(ctx context.Context, host *ssh_handler.SshConfig, install Install) ([]byte, error)
+	// Uninstall runs the uninstall script of the role, removing K3S and its data from the host.
+	Uninstall(ctx context.Context, host *ssh_handler.SshConfig, role remote.NodeRole) error
+	// Status inspects the K3S installation of the host.
+	Status(ctx context.Context, host *ssh_handler.SshConfig) (*remote.NodeInfo, error)
+	// ReadFile returns the content of a file readable by root.
+	ReadFile(ctx context.Context, host *ssh_handler.SshConfig, filePath string) ([]byte, error)
+	// WriteFile creates or replaces a file owned by root, a nil content removes the file instead.
+	WriteFile(ctx context.Context, host *ssh_handler.SshConfig, filePath string, content []byte, mode os.FileMode) error
+	// UploadFile creates or replaces a file given to the owner, e.g. root:root, through SFTP so that its size is not
+	// limited.
+	UploadFile(ctx context.Context, host *ssh_handler.SshConfig, filePath string, content []byte, mode os.FileMode, owner string) error
+	// Query runs a command inspecting the host as root, with environment variables holding secrets, and returns its
+	// standard output.
+	Query(ctx context.Context, host *ssh_handler.SshConfig, command string, env map[string]string) ([]byte, error)
+	// Run runs a command changing the host as root, with environment variables holding secrets, and returns its
+	// standard output.
+	Run(ctx context.Context, host *ssh_handler.SshConfig, command string, env map[string]string) ([]byte, error)
+}
+
+// Install describes the K3S installation of a node.
+type Install struct {
+	// Role is either remote.NodeRoleServer or remote.NodeRoleAgent.
+	Role remote.NodeRole
+
+	Version string
+	Token   string
+	// Address is the address of the cluster, added to the certificate of servers and joined by agents.
+	Address string
+	// Options are the flags passed to the K3S service.
+	Options []string
+}
+
+// Files returns the files of the host, written through the executor.
+func Files(ctx context.Context, executor Executor, host *ssh_handler.SshConfig) remote.FileWriter {
+	return &hostFiles{
+		ctx:      ctx,
+		executor: executor,
+		host:     host,
+	}
+}
+
+type hostFiles struct {
+	ctx      context.Context
+	executor Executor
+	host     *ssh_handler.SshConfig
+}
+
+func (f *hostFiles) WriteFile(filePath string, content []byte, mode os.FileMode) error {
+	return f.executor.WriteFile(f.ctx, f.host, filePath, content, mode)
+}
+
+// Commands returns the commands of the host, run through the executor.
+func Commands(ctx context.Context, executor Executor, host *ssh_handler.SshConfig) remote.CommandRunner {
+	return &hostCommands{
+		ctx:      ctx,
+		executor: executor,
+		host:     host,
+	}
+}
+
+type hostCommands struct {
+	ctx      context.Context
+	executor Executor
+	host     *ssh_handler.SshConfig
+}
+
+func (c *hostCommands) Query(command string, env map[string]string) ([]byte, error) {
+	return c.executor.Query(c.ctx, c.host, command, env)
+}
+
+func (c *hostCommands) Run(command string, env map[string]string) ([]byte, error) {
+	return c.executor.Run(c.ctx, c.host, command, env)
+}
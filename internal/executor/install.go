@@ -0,0 +1,101 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/remote"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"strings"
+)
+
+// installScriptUrl is the K3S install script, as run by yoshi-k3s.
+const installScriptUrl = "https://get.k3s.io"
+
+// copyKubeconfigCommand copies the kubeconfig of a server to the home of the SSH user, who may not be allowed to
+// read /etc/rancher/k3s.
+const copyKubeconfigCommand = "mkdir -p $HOME/.kube; cp /etc/rancher/k3s/k3s.yaml $HOME/.kube/config; chmod g+r $HOME/.kube/config;"
+
+const readKubeconfigCommand = "cat $HOME/.kube/config"
+
+// installEnv returns the environment the install script runs with, the token is given separately so that a dry run
+// can redact it.
+func installEnv(install Install, token string) (map[string]string, error) {
+	// Without them, the install script would not join the configured cluster.
+	if install.Token == "" {
+		return nil, fmt.Errorf("cannot install k3s without a cluster token")
+	}
+	if install.Address == "" {
+		return nil, fmt.Errorf("cannot install k3s without a cluster address")
+	}
+
+	env := map[string]string{
+		"K3S_TOKEN": token,
+	}
+	if install.Version != "" {
+		env["INSTALL_K3S_VERSION"] = install.Version
+	}
+
+	switch install.Role {
+	case remote.NodeRoleServer:
+		env["K3S_KUBECONFIG_MODE"] = "644"
+	case remote.NodeRoleAgent:
+		env["K3S_URL"] = fmt.Sprintf("https://%s:6443", install.Address)
+	default:
+		return nil, fmt.Errorf("cannot install k3s with the role %q", install.Role)
+	}
+
+	return env, nil
+}
+
+// installArgs returns the arguments of the install script, the options are given separately so that a dry run can
+// redact them.
+func installArgs(install Install, options []string) []string {
+	if install.Role == remote.NodeRoleServer {
+		return append([]string{"--tls-san " + install.Address, "server"}, options...)
+	}
+
+	return append([]string{"agent"}, options...)
+}
+
+// scriptsUseSudo tells whether the K3S scripts can be run as the SSH user the way yoshi-k3s runs them, leaving them
+// to call sudo, whose prompt reads the SSH password. The other become settings, e.g. doas or a sudo password of its
+// own, run the scripts prefixed with the become command instead.
+func scriptsUseSudo(become remote.Become, host *ssh_handler.SshConfig) bool {
+	return become.Method == remote.BecomeSudo &&
+		(become.User == "" || become.User == "root") &&
+		(become.Password == "" || become.Password == host.GetPassword())
+}
+
+// installCommand renders the command downloading and running the install script as root. With the sudo settings of
+// yoshi-k3s, the script runs as the SSH user, as yoshi-k3s runs it, using sudo itself. Otherwise, the pipeline runs in
+// a shell started with the become settings, since sudo reads its password from the standard input of the command.
+func installCommand(ctx context.Context, host *ssh_handler.SshConfig, env map[string]string, args []string) string {
+	script := fmt.Sprintf("curl -sfL %s | %s sh -s - %s", installScriptUrl, renderEnv(env), strings.Join(args, " "))
+
+	become := remote.BecomeFromContext(ctx, host)
+	if scriptsUseSudo(become, host) {
+		return script
+	}
+
+	return become.Command(host, "sh -c "+remote.ShellQuote(script))
+}
+
+// uninstallCommand returns the command running the uninstall script of the role as root.
+func uninstallCommand(ctx context.Context, host *ssh_handler.SshConfig, role remote.NodeRole) (string, error) {
+	var script string
+	switch role {
+	case remote.NodeRoleServer:
+		script = "k3s-uninstall.sh"
+	case remote.NodeRoleAgent:
+		script = "k3s-agent-uninstall.sh"
+	default:
+		return "", fmt.Errorf("cannot uninstall k3s with the role %q", role)
+	}
+
+	become := remote.BecomeFromContext(ctx, host)
+	if scriptsUseSudo(become, host) {
+		return "sudo " + script, nil
+	}
+
+	return become.Command(host, script), nil
+}
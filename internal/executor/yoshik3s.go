@@ -0,0 +1,118 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/remote"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/kubeconfig"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"os"
+)
+
+var _ Executor = &YoshiK3SExecutor{}
+
+// YoshiK3SExecutor is the default executor, it runs the K3S scripts the way yoshi-k3s does and every other operation
+// over SSH, logging the commands and capturing their output for the errors.
+type YoshiK3SExecutor struct{}
+
+func NewYoshiK3SExecutor() *YoshiK3SExecutor {
+	return &YoshiK3SExecutor{}
+}
+
+func (e *YoshiK3SExecutor) Install(ctx context.Context, host *ssh_handler.SshConfig, install Install) ([]byte, error) {
+	env, err := installEnv(install, install.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	// A failing privilege escalation is reported before the install script starts changing the host.
+	if err := remote.CheckBecome(ctx, host); err != nil {
+		return nil, err
+	}
+
+	ctx = remote.WithSecrets(ctx, append([]string{install.Token}, sensitiveOptionValues(install.Options)...)...)
+	command := installCommand(ctx, host, env, installArgs(install, install.Options))
+
+	if _, err := runScript(ctx, host, command); err != nil {
+		return nil, fmt.Errorf("failed to install k3s: %w", err)
+	}
+
+	if install.Role != remote.NodeRoleServer {
+		return nil, nil
+	}
+
+	// The kubeconfig is copied to the home of the SSH user, not of the become user.
+	if _, err := remote.Run(ctx, host, copyKubeconfigCommand); err != nil {
+		return nil, fmt.Errorf("failed to copy the kubeconfig: %w", err)
+	}
+	content, err := remote.Run(remote.WithSensitiveOutput(ctx), host, readKubeconfigCommand)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the kubeconfig: %w", err)
+	}
+
+	updated, err := kubeconfig.UpdateServerAddress(&content, install.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	return *updated, nil
+}
+
+func (e *YoshiK3SExecutor) Uninstall(ctx context.Context, host *ssh_handler.SshConfig, role remote.NodeRole) error {
+	command, err := uninstallCommand(ctx, host, role)
+	if err != nil {
+		return err
+	}
+
+	if _, err := runScript(ctx, host, command); err != nil {
+		return fmt.Errorf("failed to uninstall k3s: %w", err)
+	}
+
+	return nil
+}
+
+func (e *YoshiK3SExecutor) Status(ctx context.Context, host *ssh_handler.SshConfig) (*remote.NodeInfo, error) {
+	return remote.DiscoverNode(ctx, host)
+}
+
+func (e *YoshiK3SExecutor) ReadFile(ctx context.Context, host *ssh_handler.SshConfig, filePath string) ([]byte, error) {
+	// The files may hold secrets, e.g. the private keys of a kubeconfig.
+	content, err := remote.RunAsRoot(remote.WithSensitiveOutput(ctx), host, "cat "+remote.ShellQuote(filePath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+
+	return content, nil
+}
+
+func (e *YoshiK3SExecutor) WriteFile(ctx context.Context, host *ssh_handler.SshConfig, filePath string, content []byte, mode os.FileMode) error {
+	if content == nil {
+		return remote.RemoveFileAsRoot(ctx, host, filePath)
+	}
+
+	return remote.WriteFileAsRoot(ctx, host, filePath, content, mode)
+}
+
+func (e *YoshiK3SExecutor) UploadFile(ctx context.Context, host *ssh_handler.SshConfig, filePath string, content []byte, mode os.FileMode, owner string) error {
+	return remote.UploadFileAsRoot(ctx, host, filePath, content, mode, owner)
+}
+
+func (e *YoshiK3SExecutor) Query(ctx context.Context, host *ssh_handler.SshConfig, command string, env map[string]string) ([]byte, error) {
+	return remote.RunAsRootWithEnv(ctx, host, env, command)
+}
+
+func (e *YoshiK3SExecutor) Run(ctx context.Context, host *ssh_handler.SshConfig, command string, env map[string]string) ([]byte, error) {
+	return remote.RunAsRootWithEnv(ctx, host, env, command)
+}
+
+// runScript runs a K3S script prefixed with the become settings in a pseudo terminal, as yoshi-k3s does, with the
+// sudo password on its input.
+func runScript(ctx context.Context, host *ssh_handler.SshConfig, command string) ([]byte, error) {
+	become := remote.BecomeFromContext(ctx, host)
+
+	return remote.Exec(remote.WithSecrets(ctx, become.Password), host, remote.Command{
+		Command:  command,
+		Stdin:    become.Stdin(host),
+		Terminal: true,
+	})
+}
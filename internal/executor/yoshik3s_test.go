@@ -0,0 +1,228 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/remote"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/sshtest"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const testKubeconfig = `apiVersion: v1
+clusters:
+- cluster:
+    server: https://127.0.0.1:6443
+  name: default
+kind: Config
+`
+
+func testHost(server *sshtest.Server) *ssh_handler.SshConfig {
+	return ssh_handler.NewSshConfig(server.Host(), server.Port(), server.User, server.Password, "", "")
+}
+
+func TestYoshiK3SExecutorInstall(t *testing.T) {
+	ctx := context.Background()
+
+	for _, test := range []struct {
+		role     remote.NodeRole
+		expected string
+	}{
+		{remote.NodeRoleServer, "--tls-san 10.0.0.1 server --disable traefik"},
+		{remote.NodeRoleAgent, `K3S_URL="https://10.0.0.1:6443"`},
+	} {
+		t.Run(string(test.role), func(t *testing.T) {
+			server := sshtest.NewServer(t)
+			server.Respond(`cat \$HOME/.kube/config$`, testKubeconfig)
+
+			kubeconfig, err := NewYoshiK3SExecutor().Install(ctx, testHost(server), Install{
+				Role:    test.role,
+				Version: "v1.30.2+k3s2",
+				Token:   "cluster-secret",
+				Address: "10.0.0.1",
+				Options: []string{"--disable traefik"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			installs := server.Received(`get\.k3s\.io`)
+			if len(installs) != 1 || !strings.Contains(installs[0].Command, test.expected) {
+				t.Fatalf("expected one install command containing %q, got: %v", test.expected, server.Commands())
+			}
+
+			if test.role == remote.NodeRoleServer && !strings.Contains(string(kubeconfig), "server: https://10.0.0.1:6443") {
+				t.Errorf("expected the kubeconfig to point to the cluster address, got:\n%s", kubeconfig)
+			}
+			if test.role == remote.NodeRoleAgent && kubeconfig != nil {
+				t.Errorf("expected no kubeconfig for an agent, got:\n%s", kubeconfig)
+			}
+		})
+	}
+}
+
+func TestYoshiK3SExecutorUninstall(t *testing.T) {
+	ctx := context.Background()
+
+	for role, script := range map[remote.NodeRole]string{
+		remote.NodeRoleServer: "k3s-uninstall.sh",
+		remote.NodeRoleAgent:  "k3s-agent-uninstall.sh",
+	} {
+		t.Run(string(role), func(t *testing.T) {
+			server := sshtest.NewServer(t)
+
+			if err := NewYoshiK3SExecutor().Uninstall(ctx, testHost(server), role); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			received := server.Received(`^sudo ` + script + `$`)
+			if len(received) != 1 {
+				t.Fatalf("expected %s to be run, got: %v", script, server.Commands())
+			}
+			if received[0].Stdin != server.Password+"\n" {
+				t.Errorf("expected the sudo password on the standard input, got: %q", received[0].Stdin)
+			}
+		})
+	}
+}
+
+func TestYoshiK3SExecutorInstallRequiresCluster(t *testing.T) {
+	ctx := context.Background()
+
+	for name, install := range map[string]Install{
+		"token":   {Role: remote.NodeRoleAgent, Address: "10.0.0.1"},
+		"address": {Role: remote.NodeRoleServer, Token: "cluster-secret"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			server := sshtest.NewServer(t)
+
+			_, err := NewYoshiK3SExecutor().Install(ctx, testHost(server), install)
+			if err == nil || !strings.Contains(err.Error(), "without a cluster "+name) {
+				t.Fatalf("expected the missing cluster %s to be reported, got: %v", name, err)
+			}
+			if commands := server.Commands(); len(commands) != 0 {
+				t.Errorf("expected nothing to be run on the host, got: %v", commands)
+			}
+		})
+	}
+}
+
+func TestYoshiK3SExecutorWriteFile(t *testing.T) {
+	ctx := context.Background()
+	server := sshtest.NewServer(t)
+	files := Files(ctx, NewYoshiK3SExecutor(), testHost(server))
+
+	if err := remote.WriteAgentTokenConfig(files, "agent-secret"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	uploads := server.Received(`^umask 077 && set -C && cat > '/tmp/\.yoshik3s-upload-[0-9a-f]+'$`)
+	if len(uploads) != 1 || !strings.Contains(uploads[0].Stdin, "agent-token: agent-secret\n") {
+		t.Errorf("expected the agent token configuration to be uploaded over the standard input, got: %v", server.Commands())
+	}
+	if received := server.Received(`install -m 600 .* && mv -f .*` + regexp.QuoteMeta(remote.AgentTokenConfigPath)); len(received) != 1 {
+		t.Errorf("expected the agent token configuration to be installed, got: %v", server.Commands())
+	}
+	for _, command := range server.Commands() {
+		if strings.Contains(command.Command, "agent-secret") {
+			t.Errorf("expected the agent token to be left out of the command line, got: %s", command.Command)
+		}
+	}
+
+	if err := remote.WriteAgentTokenConfig(files, ""); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if received := server.Received(`rm -f '` + regexp.QuoteMeta(remote.AgentTokenConfigPath) + `'$`); len(received) != 1 {
+		t.Errorf("expected the agent token configuration to be removed, got: %v", server.Commands())
+	}
+}
+
+func TestYoshiK3SExecutorReadFile(t *testing.T) {
+	ctx := context.Background()
+	server := sshtest.NewServer(t)
+	server.Respond(`cat '/etc/rancher/k3s/k3s\.yaml'$`, testKubeconfig)
+
+	content, err := NewYoshiK3SExecutor().ReadFile(ctx, testHost(server), "/etc/rancher/k3s/k3s.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(content) != testKubeconfig {
+		t.Errorf("expected the file content, got:\n%s", content)
+	}
+}
+
+func TestYoshiK3SExecutorInstallFailure(t *testing.T) {
+	for name, become := range map[string]remote.Become{
+		"default":       remote.DefaultBecome,
+		"sudo password": {Method: remote.BecomeSudo, Password: "sudo-secret"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			server := sshtest.NewServer(t)
+			server.Fail(`get\.k3s\.io`, "[INFO]  Using v1.30.2+k3s2 as release\n[ERROR] invalid token cluster-secret\n", 1)
+			host := testHost(server)
+
+			_, err := NewYoshiK3SExecutor().Install(remote.WithBecome(context.Background(), host, become), host, Install{
+				Role:    remote.NodeRoleAgent,
+				Version: "v1.30.2+k3s2",
+				Token:   "cluster-secret",
+				Address: "10.0.0.1",
+			})
+			if err == nil {
+				t.Fatal("expected the install to fail")
+			}
+
+			var commandError *remote.CommandError
+			if !errors.As(err, &commandError) || commandError.ExitCode != 1 {
+				t.Fatalf("expected a command error with the exit code 1, got: %s", err)
+			}
+			if !strings.Contains(err.Error(), "[ERROR] invalid token ***") {
+				t.Errorf("expected the error to end with the masked output of the install script, got: %s", err)
+			}
+			if strings.Contains(err.Error(), "cluster-secret") {
+				t.Errorf("expected the token to be masked, got: %s", err)
+			}
+		})
+	}
+}
+
+func TestYoshiK3SExecutorInstallWithBecome(t *testing.T) {
+	server := sshtest.NewServer(t)
+	host := testHost(server)
+	ctx := remote.WithBecome(context.Background(), host, remote.Become{Method: remote.BecomeDoas})
+
+	if _, err := NewYoshiK3SExecutor().Install(ctx, host, Install{
+		Role:    remote.NodeRoleAgent,
+		Version: "v1.30.2+k3s2",
+		Token:   "cluster-secret",
+		Address: "10.0.0.1",
+	}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	installs := server.Received(`get\.k3s\.io`)
+	if len(installs) != 1 || !strings.HasPrefix(installs[0].Command, "doas -n sh -c 'curl -sfL https://get.k3s.io | ") {
+		t.Fatalf("expected the install script to run with doas, got: %v", server.Commands())
+	}
+	if installs[0].Stdin != "" {
+		t.Errorf("expected no password on the standard input, got: %q", installs[0].Stdin)
+	}
+}
+
+func TestYoshiK3SExecutorInstallChecksBecome(t *testing.T) {
+	server := sshtest.NewServer(t)
+	server.Fail(`id -un$`, "sudo: a password is required\n", 1)
+
+	_, err := NewYoshiK3SExecutor().Install(context.Background(), testHost(server), Install{
+		Role:    remote.NodeRoleAgent,
+		Version: "v1.30.2+k3s2",
+		Token:   "cluster-secret",
+		Address: "10.0.0.1",
+	})
+	if err == nil || !strings.Contains(err.Error(), "check the become settings") {
+		t.Fatalf("expected the install to fail on the escalation check, got: %v", err)
+	}
+	if installs := server.Received(`get\.k3s\.io`); len(installs) != 0 {
+		t.Errorf("expected the install script not to run, got: %v", installs)
+	}
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// YoshiK3SBecomeModel describes how the commands needing privileges are run on a node.
+type YoshiK3SBecomeModel struct {
+	Method   types.String `tfsdk:"method"`
+	Password types.String `tfsdk:"password"`
+	User     types.String `tfsdk:"user"`
+}
+
+var becomeDescriptions = map[string]string{
+	"method": "How the commands needing privileges are run: sudo, reading the password from the standard input when " +
+		"one is known and never prompting otherwise, doas, which requires a nopass rule for the SSH user, or none " +
+		"when the SSH user is root. Defaults to sudo.",
+	"password": "The password of the sudo prompt. Defaults to the SSH password, which is reused for sudo when the SSH " +
+		"user logs in with a password.",
+	"user": "The user the commands needing privileges run as, only root is supported since K3S is installed and " +
+		"managed as root. Defaults to root.",
+}
+
+var YoshiK3SBecomeModelSchema = map[string]schema.Attribute{
+	"method": schema.StringAttribute{
+		Description:         becomeDescriptions["method"],
+		MarkdownDescription: becomeDescriptions["method"],
+		Optional:            true,
+	},
+	"password": schema.StringAttribute{
+		Description:         becomeDescriptions["password"],
+		MarkdownDescription: becomeDescriptions["password"],
+		Optional:            true,
+		Sensitive:           true,
+	},
+	"user": schema.StringAttribute{
+		Description:         becomeDescriptions["user"],
+		MarkdownDescription: becomeDescriptions["user"],
+		Optional:            true,
+	},
+}
+
+var YoshiK3SBecomeModelAttributeTypes = map[string]attr.Type{
+	"method":   types.StringType,
+	"password": types.StringType,
+	"user":     types.StringType,
+}
@@ -0,0 +1,111 @@
+package model
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// YoshiK3SCertificateAuthorityModel describes the custom certificate authorities of a cluster.
+type YoshiK3SCertificateAuthorityModel struct {
+	ServerCaCertificate        types.String `tfsdk:"server_ca_certificate"`
+	ServerCaKey                types.String `tfsdk:"server_ca_key"`
+	ClientCaCertificate        types.String `tfsdk:"client_ca_certificate"`
+	ClientCaKey                types.String `tfsdk:"client_ca_key"`
+	RequestHeaderCaCertificate types.String `tfsdk:"request_header_ca_certificate"`
+	RequestHeaderCaKey         types.String `tfsdk:"request_header_ca_key"`
+	EtcdServerCaCertificate    types.String `tfsdk:"etcd_server_ca_certificate"`
+	EtcdServerCaKey            types.String `tfsdk:"etcd_server_ca_key"`
+	EtcdPeerCaCertificate      types.String `tfsdk:"etcd_peer_ca_certificate"`
+	EtcdPeerCaKey              types.String `tfsdk:"etcd_peer_ca_key"`
+}
+
+var certificateAuthorityDescriptions = map[string]string{
+	"server_ca_certificate":         "The PEM encoded certificate of the CA signing the server certificates.",
+	"server_ca_key":                 "The PEM encoded private key of the server CA.",
+	"client_ca_certificate":         "The PEM encoded certificate of the CA signing the client certificates.",
+	"client_ca_key":                 "The PEM encoded private key of the client CA.",
+	"request_header_ca_certificate": "The PEM encoded certificate of the CA signing the request header certificates of the aggregation layer.",
+	"request_header_ca_key":         "The PEM encoded private key of the request header CA.",
+	"etcd_server_ca_certificate":    "The PEM encoded certificate of the CA signing the etcd server certificates. Generated by K3S when unset.",
+	"etcd_server_ca_key":            "The PEM encoded private key of the etcd server CA.",
+	"etcd_peer_ca_certificate":      "The PEM encoded certificate of the CA signing the etcd peer certificates. Generated by K3S when unset.",
+	"etcd_peer_ca_key":              "The PEM encoded private key of the etcd peer CA.",
+}
+
+// YoshiK3SCertificateAuthorityAttributeTypes describes the certificate_authority object.
+var YoshiK3SCertificateAuthorityAttributeTypes = map[string]attr.Type{
+	"server_ca_certificate":         types.StringType,
+	"server_ca_key":                 types.StringType,
+	"client_ca_certificate":         types.StringType,
+	"client_ca_key":                 types.StringType,
+	"request_header_ca_certificate": types.StringType,
+	"request_header_ca_key":         types.StringType,
+	"etcd_server_ca_certificate":    types.StringType,
+	"etcd_server_ca_key":            types.StringType,
+	"etcd_peer_ca_certificate":      types.StringType,
+	"etcd_peer_ca_key":              types.StringType,
+}
+
+var YoshiK3SCertificateAuthorityModelSchema = map[string]schema.Attribute{
+	"server_ca_certificate": schema.StringAttribute{
+		Description:         certificateAuthorityDescriptions["server_ca_certificate"],
+		MarkdownDescription: certificateAuthorityDescriptions["server_ca_certificate"],
+		Required:            true,
+		Sensitive:           true,
+	},
+	"server_ca_key": schema.StringAttribute{
+		Description:         certificateAuthorityDescriptions["server_ca_key"],
+		MarkdownDescription: certificateAuthorityDescriptions["server_ca_key"],
+		Required:            true,
+		Sensitive:           true,
+	},
+	"client_ca_certificate": schema.StringAttribute{
+		Description:         certificateAuthorityDescriptions["client_ca_certificate"],
+		MarkdownDescription: certificateAuthorityDescriptions["client_ca_certificate"],
+		Required:            true,
+		Sensitive:           true,
+	},
+	"client_ca_key": schema.StringAttribute{
+		Description:         certificateAuthorityDescriptions["client_ca_key"],
+		MarkdownDescription: certificateAuthorityDescriptions["client_ca_key"],
+		Required:            true,
+		Sensitive:           true,
+	},
+	"request_header_ca_certificate": schema.StringAttribute{
+		Description:         certificateAuthorityDescriptions["request_header_ca_certificate"],
+		MarkdownDescription: certificateAuthorityDescriptions["request_header_ca_certificate"],
+		Required:            true,
+		Sensitive:           true,
+	},
+	"request_header_ca_key": schema.StringAttribute{
+		Description:         certificateAuthorityDescriptions["request_header_ca_key"],
+		MarkdownDescription: certificateAuthorityDescriptions["request_header_ca_key"],
+		Required:            true,
+		Sensitive:           true,
+	},
+	"etcd_server_ca_certificate": schema.StringAttribute{
+		Description:         certificateAuthorityDescriptions["etcd_server_ca_certificate"],
+		MarkdownDescription: certificateAuthorityDescriptions["etcd_server_ca_certificate"],
+		Optional:            true,
+		Sensitive:           true,
+	},
+	"etcd_server_ca_key": schema.StringAttribute{
+		Description:         certificateAuthorityDescriptions["etcd_server_ca_key"],
+		MarkdownDescription: certificateAuthorityDescriptions["etcd_server_ca_key"],
+		Optional:            true,
+		Sensitive:           true,
+	},
+	"etcd_peer_ca_certificate": schema.StringAttribute{
+		Description:         certificateAuthorityDescriptions["etcd_peer_ca_certificate"],
+		MarkdownDescription: certificateAuthorityDescriptions["etcd_peer_ca_certificate"],
+		Optional:            true,
+		Sensitive:           true,
+	},
+	"etcd_peer_ca_key": schema.StringAttribute{
+		Description:         certificateAuthorityDescriptions["etcd_peer_ca_key"],
+		MarkdownDescription: certificateAuthorityDescriptions["etcd_peer_ca_key"],
+		Optional:            true,
+		Sensitive:           true,
+	},
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
@@ -13,18 +14,35 @@ type YoshiK3SClusterResourceModel struct {
 
 	ClusterName    types.String `tfsdk:"name"`
 	ClusterToken   types.String `tfsdk:"token"`
+	AgentToken     types.String `tfsdk:"agent_token"`
 	ClusterAddress types.String `tfsdk:"address"`
 	ClusterVersion types.String `tfsdk:"k3s_version"`
+
+	CertificateAuthority types.Object `tfsdk:"certificate_authority"`
 }
 
 var clusterResourceDescriptions = map[string]string{
-	"id":          "The ID of the K3S Cluster.",
-	"name":        "The name of the K3S Cluster.",
-	"token":       "The token of K3S to be used in the configuration of the K3S Cluster.",
+	"id":    "The ID of the K3S Cluster.",
+	"name":  "The name of the K3S Cluster.",
+	"token": "The token of K3S to be used in the configuration of the K3S Cluster.",
+	"agent_token": "The token used by the worker nodes to join the K3S Cluster. When set, the master nodes are configured with it " +
+		"as `--agent-token` and the worker nodes join with it instead of the token, which then only allows joining master nodes.",
 	"address":     "The server address of the K3S Cluster.",
 	"k3s_version": "The version of K3S to be used in the configuration of the K3S Cluster.",
+	"certificate_authority": "Custom certificate authorities of the K3S Cluster, uploaded to the first master node before K3S is installed " +
+		"so it does not generate its own. The first master node is the one not joining through the `--server` option.",
+}
+
+// workerNodeClusterDescriptions override the descriptions of the cluster attributes nested in the worker nodes.
+var workerNodeClusterDescriptions = map[string]string{
+	"token": "The token of K3S to be used in the configuration of the K3S Cluster. Either the token or the agent token must be set, " +
+		"the agent token being used when both are.",
 }
 
+// YoshiK3SClusterResourceModelSchemaVersion must be incremented, together with a new state upgrader,
+// whenever a change to the schema would not be compatible with the existing state.
+const YoshiK3SClusterResourceModelSchemaVersion int64 = 1
+
 var YoshiK3SClusterResourceModelSchema = map[string]schema.Attribute{
 	"id": schema.StringAttribute{
 		MarkdownDescription: clusterResourceDescriptions["id"],
@@ -45,6 +63,122 @@ var YoshiK3SClusterResourceModelSchema = map[string]schema.Attribute{
 		Required:            true,
 		Sensitive:           true,
 	},
+	"agent_token": schema.StringAttribute{
+		MarkdownDescription: clusterResourceDescriptions["agent_token"],
+		Description:         clusterResourceDescriptions["agent_token"],
+		Optional:            true,
+		Sensitive:           true,
+	},
+	"address": schema.StringAttribute{
+		MarkdownDescription: clusterResourceDescriptions["address"],
+		Description:         clusterResourceDescriptions["address"],
+		Required:            true,
+	},
+	"k3s_version": schema.StringAttribute{
+		MarkdownDescription: clusterResourceDescriptions["k3s_version"],
+		Description:         clusterResourceDescriptions["k3s_version"],
+		Optional:            true,
+	},
+	"certificate_authority": schema.SingleNestedAttribute{
+		MarkdownDescription: clusterResourceDescriptions["certificate_authority"],
+		Description:         clusterResourceDescriptions["certificate_authority"],
+		Optional:            true,
+		Sensitive:           true,
+		Attributes:          YoshiK3SCertificateAuthorityModelSchema,
+	},
+}
+
+// YoshiK3SNodeClusterAttributeTypes describes the cluster object nested in the master nodes.
+var YoshiK3SNodeClusterAttributeTypes = map[string]attr.Type{
+	"id":          types.StringType,
+	"name":        types.StringType,
+	"token":       types.StringType,
+	"agent_token": types.StringType,
+	"address":     types.StringType,
+	"k3s_version": types.StringType,
+	"certificate_authority": types.ObjectType{
+		AttrTypes: YoshiK3SCertificateAuthorityAttributeTypes,
+	},
+}
+
+// YoshiK3SWorkerNodeClusterAttributeTypes describes the cluster object nested in the worker nodes.
+var YoshiK3SWorkerNodeClusterAttributeTypes = map[string]attr.Type{
+	"id":          types.StringType,
+	"name":        types.StringType,
+	"token":       types.StringType,
+	"agent_token": types.StringType,
+	"address":     types.StringType,
+	"k3s_version": types.StringType,
+}
+
+// YoshiK3SNodeClusterSchema describes the cluster object nested in the master nodes.
+var YoshiK3SNodeClusterSchema = map[string]schema.Attribute{
+	"id": schema.StringAttribute{
+		MarkdownDescription: clusterResourceDescriptions["id"],
+		Description:         clusterResourceDescriptions["id"],
+		Optional:            true,
+	},
+	"name": schema.StringAttribute{
+		MarkdownDescription: clusterResourceDescriptions["name"],
+		Description:         clusterResourceDescriptions["name"],
+		Optional:            true,
+	},
+	"token": schema.StringAttribute{
+		MarkdownDescription: clusterResourceDescriptions["token"],
+		Description:         clusterResourceDescriptions["token"],
+		Required:            true,
+		Sensitive:           true,
+	},
+	"agent_token": schema.StringAttribute{
+		MarkdownDescription: clusterResourceDescriptions["agent_token"],
+		Description:         clusterResourceDescriptions["agent_token"],
+		Optional:            true,
+		Sensitive:           true,
+	},
+	"address": schema.StringAttribute{
+		MarkdownDescription: clusterResourceDescriptions["address"],
+		Description:         clusterResourceDescriptions["address"],
+		Required:            true,
+	},
+	"k3s_version": schema.StringAttribute{
+		MarkdownDescription: clusterResourceDescriptions["k3s_version"],
+		Description:         clusterResourceDescriptions["k3s_version"],
+		Optional:            true,
+	},
+	"certificate_authority": schema.SingleNestedAttribute{
+		MarkdownDescription: clusterResourceDescriptions["certificate_authority"],
+		Description:         clusterResourceDescriptions["certificate_authority"],
+		Optional:            true,
+		Sensitive:           true,
+		Attributes:          YoshiK3SCertificateAuthorityModelSchema,
+	},
+}
+
+// YoshiK3SWorkerNodeClusterSchema describes the cluster object nested in the worker nodes, which leaves out the
+// certificate authorities only used by the master nodes so that their private keys are not copied to the worker states.
+var YoshiK3SWorkerNodeClusterSchema = map[string]schema.Attribute{
+	"id": schema.StringAttribute{
+		MarkdownDescription: clusterResourceDescriptions["id"],
+		Description:         clusterResourceDescriptions["id"],
+		Optional:            true,
+	},
+	"name": schema.StringAttribute{
+		MarkdownDescription: clusterResourceDescriptions["name"],
+		Description:         clusterResourceDescriptions["name"],
+		Optional:            true,
+	},
+	"token": schema.StringAttribute{
+		MarkdownDescription: workerNodeClusterDescriptions["token"],
+		Description:         workerNodeClusterDescriptions["token"],
+		Optional:            true,
+		Sensitive:           true,
+	},
+	"agent_token": schema.StringAttribute{
+		MarkdownDescription: clusterResourceDescriptions["agent_token"],
+		Description:         clusterResourceDescriptions["agent_token"],
+		Optional:            true,
+		Sensitive:           true,
+	},
 	"address": schema.StringAttribute{
 		MarkdownDescription: clusterResourceDescriptions["address"],
 		Description:         clusterResourceDescriptions["address"],
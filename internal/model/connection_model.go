@@ -1,7 +1,10 @@
 package model
 
 import (
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
@@ -12,15 +15,22 @@ type YoshiK3SConnectionModel struct {
 	Password             types.String `tfsdk:"password"`
 	PrivateKey           types.String `tfsdk:"private_key"`
 	PrivateKeyPassphrase types.String `tfsdk:"private_key_passphrase"`
+	ConnectionRetry      types.Object `tfsdk:"connection_retry"`
+	Become               types.Object `tfsdk:"become"`
 }
 
 var connectResourceDescriptions = map[string]string{
-	"host":                   "The hostname or IP address of the master node.",
-	"port":                   "The SSH port of the master node.",
+	"host":                   "The hostname or IP address of the master node. Changing it replaces the resource.",
+	"port":                   "The SSH port of the master node. Changing it replaces the resource.",
 	"user":                   "The SSH user of the master node.",
 	"password":               "The SSH password of the master node.",
 	"private_key":            "The SSH private key of the master node.",
 	"private_key_passphrase": "The passphrase for the SSH private key of the master node.",
+	"connection_retry": "Retries the connections failing to dial the node or to complete the SSH handshake, e.g. while the " +
+		"node is booting, with an exponential backoff. Authentication failures are not retried. Overrides the " +
+		"connection_retry settings of the provider.",
+	"become": "How the commands needing privileges, such as the K3S install script, are run on the node. They run " +
+		"with sudo as root when unset, the sudo password being the SSH password. Escalation is checked before installing K3S.",
 }
 
 var YoshiK3SConnectionModelSchema = map[string]schema.Attribute{
@@ -28,11 +38,19 @@ var YoshiK3SConnectionModelSchema = map[string]schema.Attribute{
 		Description:         connectResourceDescriptions["host"],
 		MarkdownDescription: connectResourceDescriptions["host"],
 		Required:            true,
+		// The host and port identify the node, a different one is a different node.
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.RequiresReplace(),
+		},
 	},
 	"port": schema.StringAttribute{
 		Description:         connectResourceDescriptions["port"],
 		MarkdownDescription: connectResourceDescriptions["port"],
 		Required:            true,
+		// The host and port identify the node, a different one is a different node.
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.RequiresReplace(),
+		},
 	},
 	"user": schema.StringAttribute{
 		Description:         connectResourceDescriptions["user"],
@@ -57,4 +75,31 @@ var YoshiK3SConnectionModelSchema = map[string]schema.Attribute{
 		Optional:            true,
 		Sensitive:           true,
 	},
+	"connection_retry": schema.SingleNestedAttribute{
+		Description:         connectResourceDescriptions["connection_retry"],
+		MarkdownDescription: connectResourceDescriptions["connection_retry"],
+		Optional:            true,
+		Attributes:          YoshiK3SConnectionRetryModelSchema,
+	},
+	"become": schema.SingleNestedAttribute{
+		Description:         connectResourceDescriptions["become"],
+		MarkdownDescription: connectResourceDescriptions["become"],
+		Optional:            true,
+		Attributes:          YoshiK3SBecomeModelSchema,
+	},
+}
+
+var YoshiK3SConnectionModelAttributeTypes = map[string]attr.Type{
+	"host":                   types.StringType,
+	"port":                   types.StringType,
+	"user":                   types.StringType,
+	"password":               types.StringType,
+	"private_key":            types.StringType,
+	"private_key_passphrase": types.StringType,
+	"connection_retry": types.ObjectType{
+		AttrTypes: YoshiK3SConnectionRetryModelAttributeTypes,
+	},
+	"become": types.ObjectType{
+		AttrTypes: YoshiK3SBecomeModelAttributeTypes,
+	},
 }
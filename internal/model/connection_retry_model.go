@@ -0,0 +1,64 @@
+package model
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	providerschema "github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// YoshiK3SConnectionRetryModel describes how the SSH connections failing to dial or to complete the handshake are
+// retried, both in the provider configuration and in the node_connection attributes.
+type YoshiK3SConnectionRetryModel struct {
+	MaxAttempts  types.Int64  `tfsdk:"max_attempts"`
+	InitialDelay types.String `tfsdk:"initial_delay"`
+	MaxDelay     types.String `tfsdk:"max_delay"`
+}
+
+var connectionRetryDescriptions = map[string]string{
+	"max_attempts":  "The number of connection attempts, including the first one. Defaults to 5.",
+	"initial_delay": "The delay before the second attempt, e.g. 1s or 500ms. It doubles after every attempt. Defaults to 1s.",
+	"max_delay":     "The longest delay between two attempts, e.g. 30s or 1m. Defaults to 30s.",
+}
+
+var YoshiK3SConnectionRetryModelSchema = map[string]schema.Attribute{
+	"max_attempts": schema.Int64Attribute{
+		Description:         connectionRetryDescriptions["max_attempts"],
+		MarkdownDescription: connectionRetryDescriptions["max_attempts"],
+		Optional:            true,
+	},
+	"initial_delay": schema.StringAttribute{
+		Description:         connectionRetryDescriptions["initial_delay"],
+		MarkdownDescription: connectionRetryDescriptions["initial_delay"],
+		Optional:            true,
+	},
+	"max_delay": schema.StringAttribute{
+		Description:         connectionRetryDescriptions["max_delay"],
+		MarkdownDescription: connectionRetryDescriptions["max_delay"],
+		Optional:            true,
+	},
+}
+
+var YoshiK3SConnectionRetryProviderSchema = map[string]providerschema.Attribute{
+	"max_attempts": providerschema.Int64Attribute{
+		Description:         connectionRetryDescriptions["max_attempts"],
+		MarkdownDescription: connectionRetryDescriptions["max_attempts"],
+		Optional:            true,
+	},
+	"initial_delay": providerschema.StringAttribute{
+		Description:         connectionRetryDescriptions["initial_delay"],
+		MarkdownDescription: connectionRetryDescriptions["initial_delay"],
+		Optional:            true,
+	},
+	"max_delay": providerschema.StringAttribute{
+		Description:         connectionRetryDescriptions["max_delay"],
+		MarkdownDescription: connectionRetryDescriptions["max_delay"],
+		Optional:            true,
+	},
+}
+
+var YoshiK3SConnectionRetryModelAttributeTypes = map[string]attr.Type{
+	"max_attempts":  types.Int64Type,
+	"initial_delay": types.StringType,
+	"max_delay":     types.StringType,
+}
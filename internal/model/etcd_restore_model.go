@@ -0,0 +1,31 @@
+package model
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// YoshiK3SEtcdRestoreModel describes the etcd snapshot from which a master node is restored.
+type YoshiK3SEtcdRestoreModel struct {
+	Path types.String `tfsdk:"path"`
+	S3   types.Object `tfsdk:"s3"`
+}
+
+var etcdRestoreDescriptions = map[string]string{
+	"path": "The snapshot to restore, either the absolute path of a snapshot on the node or, when s3 is set, the name of a snapshot stored in S3.",
+	"s3":   "The S3 compatible storage from which the snapshot is downloaded.",
+}
+
+var YoshiK3SEtcdRestoreModelSchema = map[string]schema.Attribute{
+	"path": schema.StringAttribute{
+		Description:         etcdRestoreDescriptions["path"],
+		MarkdownDescription: etcdRestoreDescriptions["path"],
+		Required:            true,
+	},
+	"s3": schema.SingleNestedAttribute{
+		Description:         etcdRestoreDescriptions["s3"],
+		MarkdownDescription: etcdRestoreDescriptions["s3"],
+		Optional:            true,
+		Attributes:          YoshiK3SEtcdS3ModelSchema,
+	},
+}
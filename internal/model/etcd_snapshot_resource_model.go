@@ -0,0 +1,195 @@
+package model
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/objectplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// YoshiK3SEtcdSnapshotResourceModel describes the resource data model.
+type YoshiK3SEtcdSnapshotResourceModel struct {
+	Id types.String `tfsdk:"id"`
+
+	Connection types.Object `tfsdk:"node_connection"`
+
+	Name     types.String `tfsdk:"name"`
+	Compress types.Bool   `tfsdk:"compress"`
+	S3       types.Object `tfsdk:"s3"`
+
+	SnapshotName types.String `tfsdk:"snapshot_name"`
+	Path         types.String `tfsdk:"path"`
+	Size         types.Int64  `tfsdk:"size"`
+	CreatedAt    types.String `tfsdk:"created_at"`
+}
+
+// YoshiK3SEtcdS3Model describes an S3 compatible storage used for etcd snapshots.
+type YoshiK3SEtcdS3Model struct {
+	Endpoint      types.String `tfsdk:"endpoint"`
+	Bucket        types.String `tfsdk:"bucket"`
+	Folder        types.String `tfsdk:"folder"`
+	Region        types.String `tfsdk:"region"`
+	AccessKey     types.String `tfsdk:"access_key"`
+	SecretKey     types.String `tfsdk:"secret_key"`
+	Insecure      types.Bool   `tfsdk:"insecure"`
+	SkipSslVerify types.Bool   `tfsdk:"skip_ssl_verify"`
+}
+
+var etcdSnapshotResourceDescriptions = map[string]string{
+	"id":              "The ID of the etcd snapshot.",
+	"node_connection": "The connection details of the master node on which the snapshot is taken.",
+	"name":            "The name of the snapshot, K3S appends the node name and a timestamp to it.",
+	"compress":        "Whether the snapshot is compressed.",
+	"s3":              "The S3 compatible storage to which the snapshot is uploaded.",
+	"snapshot_name":   "The full name of the snapshot, as listed by `k3s etcd-snapshot ls`.",
+	"path":            "The location of the snapshot, either a file:// or a s3:// URI.",
+	"size":            "The size of the snapshot in bytes.",
+	"created_at":      "The creation timestamp of the snapshot.",
+}
+
+var etcdS3Descriptions = map[string]string{
+	"endpoint":        "The S3 endpoint, e.g. s3.amazonaws.com or minio:9000.",
+	"bucket":          "The S3 bucket name.",
+	"folder":          "The S3 folder.",
+	"region":          "The S3 region.",
+	"access_key":      "The S3 access key.",
+	"secret_key":      "The S3 secret key.",
+	"insecure":        "Disables the use of HTTPS, e.g. for local S3 compatible storages.",
+	"skip_ssl_verify": "Disables the verification of the S3 SSL certificate.",
+}
+
+var YoshiK3SEtcdS3AttributeTypes = map[string]attr.Type{
+	"endpoint":        types.StringType,
+	"bucket":          types.StringType,
+	"folder":          types.StringType,
+	"region":          types.StringType,
+	"access_key":      types.StringType,
+	"secret_key":      types.StringType,
+	"insecure":        types.BoolType,
+	"skip_ssl_verify": types.BoolType,
+}
+
+var YoshiK3SEtcdS3ModelSchema = map[string]schema.Attribute{
+	"endpoint": schema.StringAttribute{
+		Description:         etcdS3Descriptions["endpoint"],
+		MarkdownDescription: etcdS3Descriptions["endpoint"],
+		Required:            true,
+	},
+	"bucket": schema.StringAttribute{
+		Description:         etcdS3Descriptions["bucket"],
+		MarkdownDescription: etcdS3Descriptions["bucket"],
+		Required:            true,
+	},
+	"folder": schema.StringAttribute{
+		Description:         etcdS3Descriptions["folder"],
+		MarkdownDescription: etcdS3Descriptions["folder"],
+		Optional:            true,
+	},
+	"region": schema.StringAttribute{
+		Description:         etcdS3Descriptions["region"],
+		MarkdownDescription: etcdS3Descriptions["region"],
+		Optional:            true,
+	},
+	"access_key": schema.StringAttribute{
+		Description:         etcdS3Descriptions["access_key"],
+		MarkdownDescription: etcdS3Descriptions["access_key"],
+		Optional:            true,
+		Sensitive:           true,
+	},
+	"secret_key": schema.StringAttribute{
+		Description:         etcdS3Descriptions["secret_key"],
+		MarkdownDescription: etcdS3Descriptions["secret_key"],
+		Optional:            true,
+		Sensitive:           true,
+	},
+	"insecure": schema.BoolAttribute{
+		Description:         etcdS3Descriptions["insecure"],
+		MarkdownDescription: etcdS3Descriptions["insecure"],
+		Optional:            true,
+	},
+	"skip_ssl_verify": schema.BoolAttribute{
+		Description:         etcdS3Descriptions["skip_ssl_verify"],
+		MarkdownDescription: etcdS3Descriptions["skip_ssl_verify"],
+		Optional:            true,
+	},
+}
+
+const YoshiK3SEtcdSnapshotResourceModelSchemaVersion int64 = 0
+
+var YoshiK3SEtcdSnapshotResourceModelSchema = map[string]schema.Attribute{
+	"id": schema.StringAttribute{
+		Description:         etcdSnapshotResourceDescriptions["id"],
+		MarkdownDescription: etcdSnapshotResourceDescriptions["id"],
+		Computed:            true,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.UseStateForUnknown(),
+		},
+	},
+	"node_connection": schema.SingleNestedAttribute{
+		Description:         etcdSnapshotResourceDescriptions["node_connection"],
+		MarkdownDescription: etcdSnapshotResourceDescriptions["node_connection"],
+		Required:            true,
+		Attributes:          YoshiK3SConnectionModelSchema,
+	},
+	"name": schema.StringAttribute{
+		Description:         etcdSnapshotResourceDescriptions["name"],
+		MarkdownDescription: etcdSnapshotResourceDescriptions["name"],
+		Required:            true,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.RequiresReplace(),
+		},
+	},
+	"compress": schema.BoolAttribute{
+		Description:         etcdSnapshotResourceDescriptions["compress"],
+		MarkdownDescription: etcdSnapshotResourceDescriptions["compress"],
+		Optional:            true,
+		PlanModifiers: []planmodifier.Bool{
+			boolplanmodifier.RequiresReplace(),
+		},
+	},
+	"s3": schema.SingleNestedAttribute{
+		Description:         etcdSnapshotResourceDescriptions["s3"],
+		MarkdownDescription: etcdSnapshotResourceDescriptions["s3"],
+		Optional:            true,
+		Attributes:          YoshiK3SEtcdS3ModelSchema,
+		PlanModifiers: []planmodifier.Object{
+			objectplanmodifier.RequiresReplace(),
+		},
+	},
+	"snapshot_name": schema.StringAttribute{
+		Description:         etcdSnapshotResourceDescriptions["snapshot_name"],
+		MarkdownDescription: etcdSnapshotResourceDescriptions["snapshot_name"],
+		Computed:            true,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.UseStateForUnknown(),
+		},
+	},
+	"path": schema.StringAttribute{
+		Description:         etcdSnapshotResourceDescriptions["path"],
+		MarkdownDescription: etcdSnapshotResourceDescriptions["path"],
+		Computed:            true,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.UseStateForUnknown(),
+		},
+	},
+	"size": schema.Int64Attribute{
+		Description:         etcdSnapshotResourceDescriptions["size"],
+		MarkdownDescription: etcdSnapshotResourceDescriptions["size"],
+		Computed:            true,
+		PlanModifiers: []planmodifier.Int64{
+			int64planmodifier.UseStateForUnknown(),
+		},
+	},
+	"created_at": schema.StringAttribute{
+		Description:         etcdSnapshotResourceDescriptions["created_at"],
+		MarkdownDescription: etcdSnapshotResourceDescriptions["created_at"],
+		Computed:            true,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.UseStateForUnknown(),
+		},
+	},
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// YoshiK3SEtcdSnapshotsModel describes the scheduled etcd snapshots of a master node.
+type YoshiK3SEtcdSnapshotsModel struct {
+	ScheduleCron types.String `tfsdk:"schedule_cron"`
+	Retention    types.Int64  `tfsdk:"retention"`
+	Directory    types.String `tfsdk:"directory"`
+	Compress     types.Bool   `tfsdk:"compress"`
+	S3           types.Object `tfsdk:"s3"`
+}
+
+var etcdSnapshotsDescriptions = map[string]string{
+	"schedule_cron": "The cron expression scheduling the snapshots, e.g. `0 */12 * * *`.",
+	"retention":     "The number of scheduled snapshots to retain.",
+	"directory":     "The directory in which the snapshots are saved on the node.",
+	"compress":      "Whether the snapshots are compressed.",
+	"s3":            "The S3 compatible storage to which the snapshots are uploaded.",
+}
+
+var YoshiK3SEtcdSnapshotsAttributeTypes = map[string]attr.Type{
+	"schedule_cron": types.StringType,
+	"retention":     types.Int64Type,
+	"directory":     types.StringType,
+	"compress":      types.BoolType,
+	"s3":            types.ObjectType{AttrTypes: YoshiK3SEtcdS3AttributeTypes},
+}
+
+var YoshiK3SEtcdSnapshotsModelSchema = map[string]schema.Attribute{
+	"schedule_cron": schema.StringAttribute{
+		Description:         etcdSnapshotsDescriptions["schedule_cron"],
+		MarkdownDescription: etcdSnapshotsDescriptions["schedule_cron"],
+		Optional:            true,
+	},
+	"retention": schema.Int64Attribute{
+		Description:         etcdSnapshotsDescriptions["retention"],
+		MarkdownDescription: etcdSnapshotsDescriptions["retention"],
+		Optional:            true,
+	},
+	"directory": schema.StringAttribute{
+		Description:         etcdSnapshotsDescriptions["directory"],
+		MarkdownDescription: etcdSnapshotsDescriptions["directory"],
+		Optional:            true,
+	},
+	"compress": schema.BoolAttribute{
+		Description:         etcdSnapshotsDescriptions["compress"],
+		MarkdownDescription: etcdSnapshotsDescriptions["compress"],
+		Optional:            true,
+	},
+	"s3": schema.SingleNestedAttribute{
+		Description:         etcdSnapshotsDescriptions["s3"],
+		MarkdownDescription: etcdSnapshotsDescriptions["s3"],
+		Optional:            true,
+		Attributes:          YoshiK3SEtcdS3ModelSchema,
+	},
+}
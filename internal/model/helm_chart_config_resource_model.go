@@ -0,0 +1,95 @@
+package model
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// YoshiK3SHelmChartConfigResourceModel describes the resource data model.
+type YoshiK3SHelmChartConfigResourceModel struct {
+	Id types.String `tfsdk:"id"`
+
+	Connection types.Object `tfsdk:"node_connection"`
+
+	ChartName  types.String  `tfsdk:"chart_name"`
+	Namespace  types.String  `tfsdk:"namespace"`
+	Values     types.Dynamic `tfsdk:"values"`
+	ValuesYaml types.String  `tfsdk:"values_yaml"`
+
+	Path     types.String `tfsdk:"path"`
+	Checksum types.String `tfsdk:"checksum"`
+}
+
+var helmChartConfigResourceDescriptions = map[string]string{
+	"id":              "The ID of the HelmChartConfig.",
+	"node_connection": "The connection details of the master node on which the HelmChartConfig is deployed.",
+	"chart_name":      "The name of the HelmChart to configure, e.g. traefik.",
+	"namespace":       "The namespace of the HelmChart to configure.",
+	"values":          "The values overriding the chart defaults, rendered as YAML into the HelmChartConfig.",
+	"values_yaml":     "The values overriding the chart defaults, as a YAML document.",
+	"path":            "The path of the HelmChartConfig manifest on the master node.",
+	"checksum":        "The SHA-256 checksum of the HelmChartConfig manifest on the master node, a difference with the rendered manifest is planned as an update.",
+}
+
+const YoshiK3SHelmChartConfigResourceModelSchemaVersion int64 = 0
+
+var YoshiK3SHelmChartConfigResourceModelSchema = map[string]schema.Attribute{
+	"id": schema.StringAttribute{
+		Description:         helmChartConfigResourceDescriptions["id"],
+		MarkdownDescription: helmChartConfigResourceDescriptions["id"],
+		Computed:            true,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.UseStateForUnknown(),
+		},
+	},
+	"node_connection": schema.SingleNestedAttribute{
+		Description:         helmChartConfigResourceDescriptions["node_connection"],
+		MarkdownDescription: helmChartConfigResourceDescriptions["node_connection"],
+		Required:            true,
+		Attributes:          YoshiK3SConnectionModelSchema,
+	},
+	"chart_name": schema.StringAttribute{
+		Description:         helmChartConfigResourceDescriptions["chart_name"],
+		MarkdownDescription: helmChartConfigResourceDescriptions["chart_name"],
+		Required:            true,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.RequiresReplace(),
+		},
+	},
+	"namespace": schema.StringAttribute{
+		Description:         helmChartConfigResourceDescriptions["namespace"],
+		MarkdownDescription: helmChartConfigResourceDescriptions["namespace"],
+		Optional:            true,
+		Computed:            true,
+		Default:             stringdefault.StaticString("kube-system"),
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.RequiresReplace(),
+		},
+	},
+	"values": schema.DynamicAttribute{
+		Description:         helmChartConfigResourceDescriptions["values"],
+		MarkdownDescription: helmChartConfigResourceDescriptions["values"],
+		Optional:            true,
+	},
+	"values_yaml": schema.StringAttribute{
+		Description:         helmChartConfigResourceDescriptions["values_yaml"],
+		MarkdownDescription: helmChartConfigResourceDescriptions["values_yaml"],
+		Optional:            true,
+	},
+	"path": schema.StringAttribute{
+		Description:         helmChartConfigResourceDescriptions["path"],
+		MarkdownDescription: helmChartConfigResourceDescriptions["path"],
+		Computed:            true,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.UseStateForUnknown(),
+		},
+	},
+	"checksum": schema.StringAttribute{
+		Description:         helmChartConfigResourceDescriptions["checksum"],
+		MarkdownDescription: helmChartConfigResourceDescriptions["checksum"],
+		Computed:            true,
+	},
+}
@@ -0,0 +1,105 @@
+package model
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// YoshiK3SJoinTokenResourceModel describes the resource data model.
+type YoshiK3SJoinTokenResourceModel struct {
+	Id types.String `tfsdk:"id"`
+
+	Connection types.Object `tfsdk:"node_connection"`
+
+	Ttl         types.String `tfsdk:"ttl"`
+	Description types.String `tfsdk:"description"`
+	Usages      types.List   `tfsdk:"usages"`
+	Renew       types.String `tfsdk:"renew"`
+
+	Token   types.String `tfsdk:"token"`
+	Expired types.Bool   `tfsdk:"expired"`
+}
+
+var joinTokenResourceDescriptions = map[string]string{
+	"id":              "The public ID of the join token.",
+	"node_connection": "The connection details of the master node on which the token is created.",
+	"ttl":             "The duration after which the token expires, e.g. 1h or 24h. 0 creates a token that never expires. Defaults to the K3S default, 24h.",
+	"description":     "A human friendly description of the token.",
+	"usages":          "The ways in which the token can be used, either signing or authentication. Defaults to both.",
+	"renew":           "An arbitrary value, e.g. a date, whose changes replace the token with a new one, to renew an expired token.",
+	"token":           "The join token, usable as the join_token of the worker nodes.",
+	"expired":         "Whether the token expired or was deleted from the master node, it is kept in the state until renewed.",
+}
+
+const YoshiK3SJoinTokenResourceModelSchemaVersion int64 = 0
+
+var YoshiK3SJoinTokenResourceModelSchema = map[string]schema.Attribute{
+	"id": schema.StringAttribute{
+		Description:         joinTokenResourceDescriptions["id"],
+		MarkdownDescription: joinTokenResourceDescriptions["id"],
+		Computed:            true,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.UseStateForUnknown(),
+		},
+	},
+	"node_connection": schema.SingleNestedAttribute{
+		Description:         joinTokenResourceDescriptions["node_connection"],
+		MarkdownDescription: joinTokenResourceDescriptions["node_connection"],
+		Required:            true,
+		Attributes:          YoshiK3SConnectionModelSchema,
+	},
+	"ttl": schema.StringAttribute{
+		Description:         joinTokenResourceDescriptions["ttl"],
+		MarkdownDescription: joinTokenResourceDescriptions["ttl"],
+		Optional:            true,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.RequiresReplace(),
+		},
+	},
+	"description": schema.StringAttribute{
+		Description:         joinTokenResourceDescriptions["description"],
+		MarkdownDescription: joinTokenResourceDescriptions["description"],
+		Optional:            true,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.RequiresReplace(),
+		},
+	},
+	"usages": schema.ListAttribute{
+		Description:         joinTokenResourceDescriptions["usages"],
+		MarkdownDescription: joinTokenResourceDescriptions["usages"],
+		ElementType:         types.StringType,
+		Optional:            true,
+		PlanModifiers: []planmodifier.List{
+			listplanmodifier.RequiresReplace(),
+		},
+	},
+	"renew": schema.StringAttribute{
+		Description:         joinTokenResourceDescriptions["renew"],
+		MarkdownDescription: joinTokenResourceDescriptions["renew"],
+		Optional:            true,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.RequiresReplace(),
+		},
+	},
+	"token": schema.StringAttribute{
+		Description:         joinTokenResourceDescriptions["token"],
+		MarkdownDescription: joinTokenResourceDescriptions["token"],
+		Computed:            true,
+		Sensitive:           true,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.UseStateForUnknown(),
+		},
+	},
+	"expired": schema.BoolAttribute{
+		Description:         joinTokenResourceDescriptions["expired"],
+		MarkdownDescription: joinTokenResourceDescriptions["expired"],
+		Computed:            true,
+		PlanModifiers: []planmodifier.Bool{
+			boolplanmodifier.UseStateForUnknown(),
+		},
+	},
+}
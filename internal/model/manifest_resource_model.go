@@ -0,0 +1,75 @@
+package model
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// YoshiK3SManifestResourceModel describes the resource data model.
+type YoshiK3SManifestResourceModel struct {
+	Id types.String `tfsdk:"id"`
+
+	Connection types.Object `tfsdk:"node_connection"`
+
+	Name    types.String `tfsdk:"name"`
+	Content types.String `tfsdk:"content"`
+
+	Path     types.String `tfsdk:"path"`
+	Checksum types.String `tfsdk:"checksum"`
+}
+
+var manifestResourceDescriptions = map[string]string{
+	"id":              "The ID of the manifest.",
+	"node_connection": "The connection details of the master node on which the manifest is deployed.",
+	"name":            "The name of the manifest, it is stored as `<name>.yaml` in the K3S manifests directory.",
+	"content":         "The YAML content of the manifest, e.g. Kubernetes objects or HelmChart and HelmChartConfig resources.",
+	"path":            "The path of the manifest on the master node.",
+	"checksum":        "The SHA-256 checksum of the manifest on the master node, a difference with the content is planned as an update.",
+}
+
+const YoshiK3SManifestResourceModelSchemaVersion int64 = 0
+
+var YoshiK3SManifestResourceModelSchema = map[string]schema.Attribute{
+	"id": schema.StringAttribute{
+		Description:         manifestResourceDescriptions["id"],
+		MarkdownDescription: manifestResourceDescriptions["id"],
+		Computed:            true,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.UseStateForUnknown(),
+		},
+	},
+	"node_connection": schema.SingleNestedAttribute{
+		Description:         manifestResourceDescriptions["node_connection"],
+		MarkdownDescription: manifestResourceDescriptions["node_connection"],
+		Required:            true,
+		Attributes:          YoshiK3SConnectionModelSchema,
+	},
+	"name": schema.StringAttribute{
+		Description:         manifestResourceDescriptions["name"],
+		MarkdownDescription: manifestResourceDescriptions["name"],
+		Required:            true,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.RequiresReplace(),
+		},
+	},
+	"content": schema.StringAttribute{
+		Description:         manifestResourceDescriptions["content"],
+		MarkdownDescription: manifestResourceDescriptions["content"],
+		Required:            true,
+	},
+	"path": schema.StringAttribute{
+		Description:         manifestResourceDescriptions["path"],
+		MarkdownDescription: manifestResourceDescriptions["path"],
+		Computed:            true,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.UseStateForUnknown(),
+		},
+	},
+	"checksum": schema.StringAttribute{
+		Description:         manifestResourceDescriptions["checksum"],
+		MarkdownDescription: manifestResourceDescriptions["checksum"],
+		Computed:            true,
+	},
+}
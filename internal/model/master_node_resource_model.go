@@ -2,6 +2,7 @@ package model
 
 import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -13,20 +14,43 @@ type YoshiK3SMasterNodeResourceModel struct {
 
 	Kubeconfig types.String `tfsdk:"kubeconfig"`
 
+	ClusterId  types.String `tfsdk:"cluster_id"`
 	Cluster    types.Object `tfsdk:"cluster"`
 	Connection types.Object `tfsdk:"node_connection"`
 
 	Options types.List `tfsdk:"node_options"`
+
+	EtcdSnapshots       types.Object `tfsdk:"etcd_snapshots"`
+	RestoreFromSnapshot types.Object `tfsdk:"restore_from_snapshot"`
+
+	SecretsEncryption       types.Object `tfsdk:"secrets_encryption"`
+	SecretsEncryptionStatus types.String `tfsdk:"secrets_encryption_status"`
+
+	CertificateExpiry            types.Map    `tfsdk:"certificate_expiry"`
+	CertificateExpiryWarningDays types.Int64  `tfsdk:"certificate_expiry_warning_days"`
+	RotateCertificates           types.String `tfsdk:"rotate_certificates"`
 }
 
 var nodeResourceDescriptions = map[string]string{
-	"id":              "The ID of the node.",
-	"kubeconfig":      "The kubeconfig of the node.",
-	"cluster":         "The cluster to which the node belongs.",
-	"node_connection": "The connection details of the node.",
-	"node_options":    "The options of the node.",
+	"id":                              "The ID of the node, the host and SSH port of its connection, e.g. 10.0.0.10:22.",
+	"kubeconfig":                      "The kubeconfig of the node.",
+	"cluster_id":                      "The ID of the yoshik3s_cluster resource to which the node belongs.",
+	"cluster":                         "The cluster to which the node belongs. When cluster_id is set, it is resolved from the referenced yoshik3s_cluster resource.",
+	"node_connection":                 "The connection details of the node.",
+	"node_options":                    "The options of the node.",
+	"etcd_snapshots":                  "The scheduled etcd snapshots of the master node, rendered into the K3S configuration.",
+	"restore_from_snapshot":           "The etcd snapshot from which the cluster datastore is restored when the master node is created, it is ignored afterwards.",
+	"secrets_encryption":              "Enables the encryption at rest of the secrets stored by the master node. It must be set on every master node of the cluster and cannot be removed once enabled.",
+	"secrets_encryption_status":       "The secrets encryption status reported by `k3s secrets-encrypt status`, e.g. Enabled.",
+	"certificate_expiry":              "The expiry of the K3S server certificates in RFC 3339 format, keyed by the certificate name, e.g. client-admin.",
+	"certificate_expiry_warning_days": "The number of days before a certificate expires from which the plan warns about it. Defaults to 30.",
+	"rotate_certificates":             "An arbitrary value whose change rotates the K3S server certificates with `k3s certificate rotate`, restarting the node.",
 }
 
+// YoshiK3SMasterNodeResourceModelSchemaVersion must be incremented, together with a new state upgrader,
+// whenever a change to the schema, or to the nested connection schema, would not be compatible with the existing state.
+const YoshiK3SMasterNodeResourceModelSchemaVersion int64 = 2
+
 var YoshiK3SMasterNodeResourceModelSchema = map[string]schema.Attribute{
 	"id": schema.StringAttribute{
 		Description:         nodeResourceDescriptions["id"],
@@ -42,37 +66,18 @@ var YoshiK3SMasterNodeResourceModelSchema = map[string]schema.Attribute{
 		Computed:            true,
 		Sensitive:           true,
 	},
+	"cluster_id": schema.StringAttribute{
+		Description:         nodeResourceDescriptions["cluster_id"],
+		MarkdownDescription: nodeResourceDescriptions["cluster_id"],
+		Optional:            true,
+	},
 	"cluster": schema.SingleNestedAttribute{
 		Description:         nodeResourceDescriptions["cluster"],
 		MarkdownDescription: nodeResourceDescriptions["cluster"],
-		Required:            true,
-		Attributes: map[string]schema.Attribute{
-			"id": schema.StringAttribute{
-				MarkdownDescription: clusterResourceDescriptions["id"],
-				Description:         clusterResourceDescriptions["id"],
-				Optional:            true,
-			},
-			"name": schema.StringAttribute{
-				MarkdownDescription: clusterResourceDescriptions["name"],
-				Description:         clusterResourceDescriptions["name"],
-				Optional:            true,
-			},
-			"token": schema.StringAttribute{
-				MarkdownDescription: clusterResourceDescriptions["token"],
-				Description:         clusterResourceDescriptions["token"],
-				Required:            true,
-			},
-			"address": schema.StringAttribute{
-				MarkdownDescription: clusterResourceDescriptions["address"],
-				Description:         clusterResourceDescriptions["address"],
-				Required:            true,
-			},
-			"k3s_version": schema.StringAttribute{
-				MarkdownDescription: clusterResourceDescriptions["k3s_version"],
-				Description:         clusterResourceDescriptions["k3s_version"],
-				Optional:            true,
-			},
-		},
+		DeprecationMessage:  "Use cluster_id to reference a yoshik3s_cluster resource instead of copying its attributes.",
+		Optional:            true,
+		Computed:            true,
+		Attributes:          YoshiK3SNodeClusterSchema,
 	},
 	"node_connection": schema.SingleNestedAttribute{
 		Description:         nodeResourceDescriptions["node_connection"],
@@ -86,4 +91,45 @@ var YoshiK3SMasterNodeResourceModelSchema = map[string]schema.Attribute{
 		ElementType:         types.StringType,
 		Optional:            true,
 	},
+	"etcd_snapshots": schema.SingleNestedAttribute{
+		Description:         nodeResourceDescriptions["etcd_snapshots"],
+		MarkdownDescription: nodeResourceDescriptions["etcd_snapshots"],
+		Optional:            true,
+		Attributes:          YoshiK3SEtcdSnapshotsModelSchema,
+	},
+	"restore_from_snapshot": schema.SingleNestedAttribute{
+		Description:         nodeResourceDescriptions["restore_from_snapshot"],
+		MarkdownDescription: nodeResourceDescriptions["restore_from_snapshot"],
+		Optional:            true,
+		Attributes:          YoshiK3SEtcdRestoreModelSchema,
+	},
+	"secrets_encryption": schema.SingleNestedAttribute{
+		Description:         nodeResourceDescriptions["secrets_encryption"],
+		MarkdownDescription: nodeResourceDescriptions["secrets_encryption"],
+		Optional:            true,
+		Attributes:          YoshiK3SSecretsEncryptionModelSchema,
+	},
+	"secrets_encryption_status": schema.StringAttribute{
+		Description:         nodeResourceDescriptions["secrets_encryption_status"],
+		MarkdownDescription: nodeResourceDescriptions["secrets_encryption_status"],
+		Computed:            true,
+	},
+	"certificate_expiry": schema.MapAttribute{
+		Description:         nodeResourceDescriptions["certificate_expiry"],
+		MarkdownDescription: nodeResourceDescriptions["certificate_expiry"],
+		ElementType:         types.StringType,
+		Computed:            true,
+	},
+	"certificate_expiry_warning_days": schema.Int64Attribute{
+		Description:         nodeResourceDescriptions["certificate_expiry_warning_days"],
+		MarkdownDescription: nodeResourceDescriptions["certificate_expiry_warning_days"],
+		Optional:            true,
+		Computed:            true,
+		Default:             int64default.StaticInt64(30),
+	},
+	"rotate_certificates": schema.StringAttribute{
+		Description:         nodeResourceDescriptions["rotate_certificates"],
+		MarkdownDescription: nodeResourceDescriptions["rotate_certificates"],
+		Optional:            true,
+	},
 }
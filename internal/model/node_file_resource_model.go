@@ -0,0 +1,99 @@
+package model
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// YoshiK3SNodeFileResourceModel describes the resource data model.
+type YoshiK3SNodeFileResourceModel struct {
+	Id types.String `tfsdk:"id"`
+
+	Connection types.Object `tfsdk:"node_connection"`
+
+	Path             types.String `tfsdk:"path"`
+	Content          types.String `tfsdk:"content"`
+	SensitiveContent types.String `tfsdk:"sensitive_content"`
+	Mode             types.String `tfsdk:"mode"`
+	Owner            types.String `tfsdk:"owner"`
+	RestartService   types.String `tfsdk:"restart_service"`
+
+	Checksum types.String `tfsdk:"checksum"`
+}
+
+var nodeFileResourceDescriptions = map[string]string{
+	"id":                "The ID of the file.",
+	"node_connection":   "The connection details of the node on which the file is uploaded.",
+	"path":              "The absolute path of the file on the node, missing directories are created.",
+	"content":           "The content of the file.",
+	"sensitive_content": "The content of the file, hidden from the plan output.",
+	"mode":              "The permissions of the file in octal notation.",
+	"owner":             "The owner of the file, as `user` or `user:group`.",
+	"restart_service":   "The K3S service restarted, when running, after the file is uploaded. Either k3s or k3s-agent.",
+	"checksum":          "The SHA-256 checksum of the file on the node, a difference with the content is planned as an update.",
+}
+
+const YoshiK3SNodeFileResourceModelSchemaVersion int64 = 0
+
+var YoshiK3SNodeFileResourceModelSchema = map[string]schema.Attribute{
+	"id": schema.StringAttribute{
+		Description:         nodeFileResourceDescriptions["id"],
+		MarkdownDescription: nodeFileResourceDescriptions["id"],
+		Computed:            true,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.UseStateForUnknown(),
+		},
+	},
+	"node_connection": schema.SingleNestedAttribute{
+		Description:         nodeFileResourceDescriptions["node_connection"],
+		MarkdownDescription: nodeFileResourceDescriptions["node_connection"],
+		Required:            true,
+		Attributes:          YoshiK3SConnectionModelSchema,
+	},
+	"path": schema.StringAttribute{
+		Description:         nodeFileResourceDescriptions["path"],
+		MarkdownDescription: nodeFileResourceDescriptions["path"],
+		Required:            true,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.RequiresReplace(),
+		},
+	},
+	"content": schema.StringAttribute{
+		Description:         nodeFileResourceDescriptions["content"],
+		MarkdownDescription: nodeFileResourceDescriptions["content"],
+		Optional:            true,
+	},
+	"sensitive_content": schema.StringAttribute{
+		Description:         nodeFileResourceDescriptions["sensitive_content"],
+		MarkdownDescription: nodeFileResourceDescriptions["sensitive_content"],
+		Optional:            true,
+		Sensitive:           true,
+	},
+	"mode": schema.StringAttribute{
+		Description:         nodeFileResourceDescriptions["mode"],
+		MarkdownDescription: nodeFileResourceDescriptions["mode"],
+		Optional:            true,
+		Computed:            true,
+		Default:             stringdefault.StaticString("0644"),
+	},
+	"owner": schema.StringAttribute{
+		Description:         nodeFileResourceDescriptions["owner"],
+		MarkdownDescription: nodeFileResourceDescriptions["owner"],
+		Optional:            true,
+		Computed:            true,
+		Default:             stringdefault.StaticString("root:root"),
+	},
+	"restart_service": schema.StringAttribute{
+		Description:         nodeFileResourceDescriptions["restart_service"],
+		MarkdownDescription: nodeFileResourceDescriptions["restart_service"],
+		Optional:            true,
+	},
+	"checksum": schema.StringAttribute{
+		Description:         nodeFileResourceDescriptions["checksum"],
+		MarkdownDescription: nodeFileResourceDescriptions["checksum"],
+		Computed:            true,
+	},
+}
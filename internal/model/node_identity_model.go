@@ -0,0 +1,34 @@
+package model
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/resource/identityschema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// YoshiK3SNodeIdentityModel describes the identity data model shared by the node resources.
+type YoshiK3SNodeIdentityModel struct {
+	Host        types.String `tfsdk:"host"`
+	Port        types.String `tfsdk:"port"`
+	ClusterName types.String `tfsdk:"cluster_name"`
+}
+
+var nodeIdentityDescriptions = map[string]string{
+	"host":         "The hostname or IP address of the node.",
+	"port":         "The SSH port of the node, defaults to 22 when importing.",
+	"cluster_name": "The name of the cluster to which the node belongs, it changes along with the name of the cluster.",
+}
+
+var YoshiK3SNodeIdentitySchema = map[string]identityschema.Attribute{
+	"host": identityschema.StringAttribute{
+		Description:       nodeIdentityDescriptions["host"],
+		RequiredForImport: true,
+	},
+	"port": identityschema.StringAttribute{
+		Description:       nodeIdentityDescriptions["port"],
+		OptionalForImport: true,
+	},
+	"cluster_name": identityschema.StringAttribute{
+		Description:       nodeIdentityDescriptions["cluster_name"],
+		OptionalForImport: true,
+	},
+}
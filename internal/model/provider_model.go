@@ -0,0 +1,62 @@
+package model
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// YoshiK3SProviderModel describes the provider data model.
+type YoshiK3SProviderModel struct {
+	DryRun           types.Bool   `tfsdk:"dry_run"`
+	DryRunTranscript types.String `tfsdk:"dry_run_transcript"`
+	ConnectionRetry  types.Object `tfsdk:"connection_retry"`
+	RemoteLock       types.Object `tfsdk:"remote_lock"`
+	ReadTimeout      types.String `tfsdk:"read_timeout"`
+}
+
+var providerDescriptions = map[string]string{
+	"dry_run": "When enabled, the node, node file, etcd snapshot and secrets encryption rotation resources record the commands they would run, the files they would upload and the " +
+		"environment variables they would set in the provider logs instead of changing the hosts. Sensitive values are redacted. " +
+		"Can also be set with the YOSHIK3S_DRY_RUN environment variable.",
+	"dry_run_transcript": "The path of a file to which the operations recorded by a dry run are appended, in addition to the " +
+		"provider logs. Can also be set with the YOSHIK3S_DRY_RUN_TRANSCRIPT environment variable.",
+	"connection_retry": "Retries the connections failing to dial a node or to complete the SSH handshake, e.g. while the " +
+		"node is booting, with an exponential backoff. Authentication failures are not retried. Applies to the " +
+		"node_connection attributes without connection_retry settings, connections are attempted once when unset.",
+	"remote_lock": "Takes an exclusive lock file on the hosts with flock while the provider changes them, so that two " +
+		"Terraform runs, e.g. from different workspaces sharing nodes, never change a host at the same time. The lock is " +
+		"held by the SSH session and released if the run is interrupted. Operations on the same host are always " +
+		"serialized within a run, whether or not remote_lock is set.",
+	"read_timeout": "How long the resources may take to inspect their nodes over SSH while refreshing, e.g. 2m or 30s. " +
+		"A node not answering in time fails the refresh of its resources instead of hanging the run. Defaults to 2m.",
+}
+
+var YoshiK3SProviderModelSchema = map[string]schema.Attribute{
+	"dry_run": schema.BoolAttribute{
+		Description:         providerDescriptions["dry_run"],
+		MarkdownDescription: providerDescriptions["dry_run"],
+		Optional:            true,
+	},
+	"dry_run_transcript": schema.StringAttribute{
+		Description:         providerDescriptions["dry_run_transcript"],
+		MarkdownDescription: providerDescriptions["dry_run_transcript"],
+		Optional:            true,
+	},
+	"connection_retry": schema.SingleNestedAttribute{
+		Description:         providerDescriptions["connection_retry"],
+		MarkdownDescription: providerDescriptions["connection_retry"],
+		Optional:            true,
+		Attributes:          YoshiK3SConnectionRetryProviderSchema,
+	},
+	"remote_lock": schema.SingleNestedAttribute{
+		Description:         providerDescriptions["remote_lock"],
+		MarkdownDescription: providerDescriptions["remote_lock"],
+		Optional:            true,
+		Attributes:          YoshiK3SRemoteLockModelSchema,
+	},
+	"read_timeout": schema.StringAttribute{
+		Description:         providerDescriptions["read_timeout"],
+		MarkdownDescription: providerDescriptions["read_timeout"],
+		Optional:            true,
+	},
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// YoshiK3SRemoteLockModel describes the lock file taken on the hosts while the provider changes them.
+type YoshiK3SRemoteLockModel struct {
+	Path    types.String `tfsdk:"path"`
+	Timeout types.String `tfsdk:"timeout"`
+}
+
+var remoteLockDescriptions = map[string]string{
+	"path": "The absolute path of the lock file on the hosts. Defaults to /var/lock/yoshik3s.lock.",
+	"timeout": "How long to wait for another Terraform run to release the lock of a host before failing, e.g. 10m or " +
+		"30s. Defaults to 10m.",
+}
+
+var YoshiK3SRemoteLockModelSchema = map[string]schema.Attribute{
+	"path": schema.StringAttribute{
+		Description:         remoteLockDescriptions["path"],
+		MarkdownDescription: remoteLockDescriptions["path"],
+		Optional:            true,
+	},
+	"timeout": schema.StringAttribute{
+		Description:         remoteLockDescriptions["timeout"],
+		MarkdownDescription: remoteLockDescriptions["timeout"],
+		Optional:            true,
+	},
+}
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// YoshiK3SSecretsEncryptionModel describes the encryption at rest of the secrets stored by a master node.
+type YoshiK3SSecretsEncryptionModel struct {
+	Provider types.String `tfsdk:"provider"`
+}
+
+var secretsEncryptionDescriptions = map[string]string{
+	"provider": "The encryption provider, either aescbc or secretbox. Defaults to the K3S default, aescbc.",
+}
+
+var YoshiK3SSecretsEncryptionModelSchema = map[string]schema.Attribute{
+	"provider": schema.StringAttribute{
+		Description:         secretsEncryptionDescriptions["provider"],
+		MarkdownDescription: secretsEncryptionDescriptions["provider"],
+		Optional:            true,
+	},
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// YoshiK3SSecretsEncryptionRotationResourceModel describes the resource data model.
+type YoshiK3SSecretsEncryptionRotationResourceModel struct {
+	Id types.String `tfsdk:"id"`
+
+	Connections types.List `tfsdk:"node_connections"`
+	Triggers    types.Map  `tfsdk:"triggers"`
+
+	Stage types.String `tfsdk:"stage"`
+}
+
+var secretsEncryptionRotationResourceDescriptions = map[string]string{
+	"id":               "The ID of the rotation, the time at which it was run.",
+	"node_connections": "The connection details of every master node of the cluster, the rotation is driven from the first one.",
+	"triggers":         "Arbitrary values whose change rotates the secrets encryption keys again.",
+	"stage":            "The secrets encryption rotation stage reported by the first master node after the rotation.",
+}
+
+const YoshiK3SSecretsEncryptionRotationResourceModelSchemaVersion int64 = 0
+
+var YoshiK3SSecretsEncryptionRotationResourceModelSchema = map[string]schema.Attribute{
+	"id": schema.StringAttribute{
+		Description:         secretsEncryptionRotationResourceDescriptions["id"],
+		MarkdownDescription: secretsEncryptionRotationResourceDescriptions["id"],
+		Computed:            true,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.UseStateForUnknown(),
+		},
+	},
+	"node_connections": schema.ListNestedAttribute{
+		Description:         secretsEncryptionRotationResourceDescriptions["node_connections"],
+		MarkdownDescription: secretsEncryptionRotationResourceDescriptions["node_connections"],
+		Required:            true,
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: YoshiK3SConnectionModelSchema,
+		},
+	},
+	"triggers": schema.MapAttribute{
+		Description:         secretsEncryptionRotationResourceDescriptions["triggers"],
+		MarkdownDescription: secretsEncryptionRotationResourceDescriptions["triggers"],
+		ElementType:         types.StringType,
+		Optional:            true,
+		PlanModifiers: []planmodifier.Map{
+			mapplanmodifier.RequiresReplace(),
+		},
+	},
+	"stage": schema.StringAttribute{
+		Description:         secretsEncryptionRotationResourceDescriptions["stage"],
+		MarkdownDescription: secretsEncryptionRotationResourceDescriptions["stage"],
+		Computed:            true,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.UseStateForUnknown(),
+		},
+	},
+}
@@ -11,12 +11,24 @@ import (
 type YoshiK3SWorkerNodeResourceModel struct {
 	Id types.String `tfsdk:"id"`
 
+	ClusterId  types.String `tfsdk:"cluster_id"`
 	Cluster    types.Object `tfsdk:"cluster"`
 	Connection types.Object `tfsdk:"node_connection"`
 
 	Options types.List `tfsdk:"node_options"`
+
+	JoinToken types.String `tfsdk:"join_token"`
+}
+
+var workerNodeResourceDescriptions = map[string]string{
+	"join_token": "A token created by a yoshik3s_join_token resource with which the worker node joins the cluster, " +
+		"instead of the agent token or the token of the cluster. It is only used to join, changing it does not reinstall the node.",
 }
 
+// YoshiK3SWorkerNodeResourceModelSchemaVersion must be incremented, together with a new state upgrader,
+// whenever a change to the schema, or to the nested connection schema, would not be compatible with the existing state.
+const YoshiK3SWorkerNodeResourceModelSchemaVersion int64 = 3
+
 var YoshiK3SWorkerNodeResourceModelSchema = map[string]schema.Attribute{
 	"id": schema.StringAttribute{
 		Description:         nodeResourceDescriptions["id"],
@@ -26,37 +38,18 @@ var YoshiK3SWorkerNodeResourceModelSchema = map[string]schema.Attribute{
 			stringplanmodifier.UseStateForUnknown(),
 		},
 	},
+	"cluster_id": schema.StringAttribute{
+		Description:         nodeResourceDescriptions["cluster_id"],
+		MarkdownDescription: nodeResourceDescriptions["cluster_id"],
+		Optional:            true,
+	},
 	"cluster": schema.SingleNestedAttribute{
 		Description:         nodeResourceDescriptions["cluster"],
 		MarkdownDescription: nodeResourceDescriptions["cluster"],
-		Required:            true,
-		Attributes: map[string]schema.Attribute{
-			"id": schema.StringAttribute{
-				MarkdownDescription: clusterResourceDescriptions["id"],
-				Description:         clusterResourceDescriptions["id"],
-				Optional:            true,
-			},
-			"name": schema.StringAttribute{
-				MarkdownDescription: clusterResourceDescriptions["name"],
-				Description:         clusterResourceDescriptions["name"],
-				Optional:            true,
-			},
-			"token": schema.StringAttribute{
-				MarkdownDescription: clusterResourceDescriptions["token"],
-				Description:         clusterResourceDescriptions["token"],
-				Required:            true,
-			},
-			"address": schema.StringAttribute{
-				MarkdownDescription: clusterResourceDescriptions["address"],
-				Description:         clusterResourceDescriptions["address"],
-				Required:            true,
-			},
-			"k3s_version": schema.StringAttribute{
-				MarkdownDescription: clusterResourceDescriptions["k3s_version"],
-				Description:         clusterResourceDescriptions["k3s_version"],
-				Optional:            true,
-			},
-		},
+		DeprecationMessage:  "Use cluster_id to reference a yoshik3s_cluster resource instead of copying its attributes.",
+		Optional:            true,
+		Computed:            true,
+		Attributes:          YoshiK3SWorkerNodeClusterSchema,
 	},
 	"node_connection": schema.SingleNestedAttribute{
 		Description:         nodeResourceDescriptions["node_connection"],
@@ -70,4 +63,10 @@ var YoshiK3SWorkerNodeResourceModelSchema = map[string]schema.Attribute{
 		ElementType:         types.StringType,
 		Optional:            true,
 	},
+	"join_token": schema.StringAttribute{
+		Description:         workerNodeResourceDescriptions["join_token"],
+		MarkdownDescription: workerNodeResourceDescriptions["join_token"],
+		Optional:            true,
+		Sensitive:           true,
+	},
 }
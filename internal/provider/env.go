@@ -0,0 +1,39 @@
+package provider
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"os"
+	"strconv"
+)
+
+// Environment variables used when the matching provider attributes are not set.
+const (
+	dryRunEnvVar           = "YOSHIK3S_DRY_RUN"
+	dryRunTranscriptEnvVar = "YOSHIK3S_DRY_RUN_TRANSCRIPT"
+)
+
+func boolFromConfigOrEnv(value types.Bool, envVar string) (bool, error) {
+	if !value.IsNull() && !value.IsUnknown() {
+		return value.ValueBool(), nil
+	}
+
+	env := os.Getenv(envVar)
+	if env == "" {
+		return false, nil
+	}
+
+	parsed, err := strconv.ParseBool(env)
+	if err != nil {
+		return false, fmt.Errorf("expected %s to be true or false, got: %q", envVar, env)
+	}
+	return parsed, nil
+}
+
+func stringFromConfigOrEnv(value types.String, envVar string) string {
+	if !value.IsNull() && !value.IsUnknown() {
+		return value.ValueString()
+	}
+
+	return os.Getenv(envVar)
+}
@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"regexp"
+	"testing"
+)
+
+func TestAccMasterNodeResource(t *testing.T) {
+	// The import reads the SSH password from the environment, as the import ID cannot carry it.
+	t.Setenv("YOSHIK3S_SSH_PASSWORD", testAccMasterNode.Password)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t, testAccMasterNode) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckUninstalled(testAccMasterNode),
+		Steps: []resource.TestStep{
+			// Create
+			{
+				Config: testAccMasterNodeConfig(testAccK3sVersion, "--disable traefik"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("yoshik3s_master_node.test", "id", testAccMasterNode.id()),
+					resource.TestCheckResourceAttr("yoshik3s_master_node.test", "cluster.k3s_version", testAccK3sVersion),
+					resource.TestCheckResourceAttr("yoshik3s_master_node.test", "node_options.#", "1"),
+					resource.TestMatchResourceAttr(
+						"yoshik3s_master_node.test", "kubeconfig",
+						regexp.MustCompile(regexp.QuoteMeta("server: https://"+testAccClusterAddress+":6443")),
+					),
+					resource.TestCheckResourceAttrSet("yoshik3s_master_node.test", "certificate_expiry.%"),
+				),
+			},
+			// Update the node options
+			{
+				Config: testAccMasterNodeConfig(testAccK3sVersion, "--disable traefik", "--disable servicelb"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("yoshik3s_master_node.test", "node_options.#", "2"),
+					resource.TestCheckResourceAttr("yoshik3s_master_node.test", "node_options.1", "--disable servicelb"),
+				),
+			},
+			// Upgrade K3S
+			{
+				Config: testAccMasterNodeConfig(testAccK3sUpgradeVersion, "--disable traefik", "--disable servicelb"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("yoshik3s_cluster.test", "k3s_version", testAccK3sUpgradeVersion),
+					resource.TestCheckResourceAttr("yoshik3s_master_node.test", "cluster.k3s_version", testAccK3sUpgradeVersion),
+				),
+			},
+			// Import
+			{
+				ResourceName:      "yoshik3s_master_node.test",
+				ImportState:       true,
+				ImportStateId:     testAccMasterNode.importId(),
+				ImportStateVerify: true,
+				// Defaults are only applied by a plan, which an import does not run.
+				ImportStateVerifyIgnore: []string{"certificate_expiry_warning_days"},
+			},
+			// Destroy runs after the last step and is verified by CheckDestroy.
+		},
+	})
+}
+
+func testAccMasterNodeConfig(k3sVersion string, options ...string) string {
+	return testAccClusterConfig(k3sVersion) + fmt.Sprintf(`
+resource "yoshik3s_master_node" "test" {
+  cluster_id = yoshik3s_cluster.test.id
+
+  node_connection = %s
+
+  node_options = %s
+}
+`, testAccMasterNode.connection(), testAccList(options...))
+}
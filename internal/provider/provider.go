@@ -5,23 +5,55 @@ package provider
 
 import (
 	"context"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/executor"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/model"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/providerdata"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/remote"
 	internalresource "github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/resource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 type YoshiK3SProvider struct {
 	version string
+
+	// executor performs the operations of the node resources, it is shared with them through Configure.
+	executor executor.Executor
+	// connections caches the SSH connections of the resources, it is shared with them through Configure.
+	connections *remote.ConnectionPool
 }
 
 var _ provider.Provider = &YoshiK3SProvider{}
 
 func New(version string) func() provider.Provider {
+	return NewWithConnectionPool(version, remote.NewConnectionPool())
+}
+
+// NewWithConnectionPool returns a provider caching its SSH connections in the given pool, which the caller closes
+// once the provider server stops.
+func NewWithConnectionPool(version string, connections *remote.ConnectionPool) func() provider.Provider {
+	return func() provider.Provider {
+		return &YoshiK3SProvider{
+			version:     version,
+			executor:    executor.NewYoshiK3SExecutor(),
+			connections: connections,
+		}
+	}
+}
+
+// NewWithExecutor returns a provider performing the operations of the node resources with the given executor
+// instead of the default one.
+func NewWithExecutor(version string, executor executor.Executor) func() provider.Provider {
 	return func() provider.Provider {
 		return &YoshiK3SProvider{
-			version: version,
+			version:     version,
+			executor:    executor,
+			connections: remote.NewConnectionPool(),
 		}
 	}
 }
@@ -34,10 +66,84 @@ func (p *YoshiK3SProvider) Metadata(_ context.Context, _ provider.MetadataReques
 
 // Schema defines the provider-level schema for configuration data.
 func (p *YoshiK3SProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp *provider.SchemaResponse) {
-	resp.Schema = schema.Schema{}
+	resp.Schema = schema.Schema{
+		Attributes: model.YoshiK3SProviderModelSchema,
+	}
 }
 
 func (p *YoshiK3SProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	var config model.YoshiK3SProviderModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	dryRun, err := boolFromConfigOrEnv(config.DryRun, dryRunEnvVar)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("dry_run"), "Invalid dry run setting", err.Error())
+		return
+	}
+
+	nodeExecutor := p.executor
+	if dryRun {
+		transcript := stringFromConfigOrEnv(config.DryRunTranscript, dryRunTranscriptEnvVar)
+		nodeExecutor = executor.NewDryRunExecutor(nodeExecutor, transcript)
+
+		tflog.Info(ctx, "dry run enabled, the node resources will not change the hosts", map[string]interface{}{
+			"transcript": transcript,
+		})
+	}
+
+	data := providerdata.New(nodeExecutor)
+	data.Connections = p.connections
+
+	if !config.ConnectionRetry.IsNull() && !config.ConnectionRetry.IsUnknown() {
+		var retry model.YoshiK3SConnectionRetryModel
+		resp.Diagnostics.Append(config.ConnectionRetry.As(ctx, &retry, basetypes.ObjectAsOptions{})...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		data.ConnectionRetry, err = remote.NewRetryPolicy(
+			retry.MaxAttempts.ValueInt64Pointer(),
+			retry.InitialDelay.ValueString(),
+			retry.MaxDelay.ValueString(),
+		)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("connection_retry"), "Invalid connection retry settings", err.Error())
+			return
+		}
+	}
+
+	if !config.RemoteLock.IsNull() && !config.RemoteLock.IsUnknown() {
+		var lock model.YoshiK3SRemoteLockModel
+		resp.Diagnostics.Append(config.RemoteLock.As(ctx, &lock, basetypes.ObjectAsOptions{})...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		options, err := remote.NewLockOptions(lock.Path.ValueString(), lock.Timeout.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("remote_lock"), "Invalid remote lock settings", err.Error())
+			return
+		}
+		data.RemoteLock = &options
+	}
+
+	if !config.ReadTimeout.IsNull() && !config.ReadTimeout.IsUnknown() {
+		data.ReadTimeout, err = remote.NewReadTimeout(config.ReadTimeout.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("read_timeout"), "Invalid read timeout", err.Error())
+			return
+		}
+	}
+
+	resp.DataSourceData = data
+	resp.ResourceData = data
 }
 
 func (p *YoshiK3SProvider) Resources(ctx context.Context) []func() resource.Resource {
@@ -45,6 +151,12 @@ func (p *YoshiK3SProvider) Resources(ctx context.Context) []func() resource.Reso
 		internalresource.NewYoshiK3SClusterResource,
 		internalresource.NewYoshiK3SMasterNodeResource,
 		internalresource.NewYoshiK3SWorkerNodeResource,
+		internalresource.NewYoshiK3SEtcdSnapshotResource,
+		internalresource.NewYoshiK3SManifestResource,
+		internalresource.NewYoshiK3SHelmChartConfigResource,
+		internalresource.NewYoshiK3SNodeFileResource,
+		internalresource.NewYoshiK3SSecretsEncryptionRotationResource,
+		internalresource.NewYoshiK3SJoinTokenResource,
 	}
 }
 
@@ -0,0 +1,146 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/remote"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"net"
+	"os"
+	"strings"
+	"testing"
+)
+
+// testAccProtoV6ProviderFactories instantiates the provider for every Terraform command run by the acceptance tests.
+var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
+	"yoshik3s": providerserver.NewProtocol6WithError(New("test")()),
+}
+
+// The acceptance tests run against the containers of docker/docker-compose.yml, installing K3S from the airgap
+// artifacts downloaded by docker/airgap/fetch.sh. Every default can be overridden through the environment.
+var (
+	testAccClusterName    = "acc-cluster"
+	testAccClusterToken   = "acc-cluster-token"
+	testAccClusterAddress = testAccEnv("YOSHIK3S_ACC_CLUSTER_ADDRESS", "master_node")
+
+	testAccK3sVersion        = testAccEnv("YOSHIK3S_ACC_K3S_VERSION", "v1.30.6+k3s1")
+	testAccK3sUpgradeVersion = testAccEnv("YOSHIK3S_ACC_K3S_UPGRADE_VERSION", "v1.31.2+k3s1")
+
+	testAccMasterNode = testAccNode{
+		Host:     testAccEnv("YOSHIK3S_ACC_MASTER_HOST", "localhost"),
+		Port:     testAccEnv("YOSHIK3S_ACC_MASTER_PORT", "2222"),
+		User:     testAccEnv("YOSHIK3S_ACC_SSH_USER", "sshuser"),
+		Password: testAccEnv("YOSHIK3S_ACC_SSH_PASSWORD", "password"),
+	}
+	testAccWorkerNode = testAccNode{
+		Host:     testAccEnv("YOSHIK3S_ACC_WORKER_HOST", "localhost"),
+		Port:     testAccEnv("YOSHIK3S_ACC_WORKER_PORT", "3333"),
+		User:     testAccEnv("YOSHIK3S_ACC_SSH_USER", "sshuser"),
+		Password: testAccEnv("YOSHIK3S_ACC_SSH_PASSWORD", "password"),
+	}
+)
+
+// testAccNode is a host the acceptance tests install K3S on.
+type testAccNode struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
+}
+
+// connection renders the node_connection attribute of the node.
+func (n testAccNode) connection() string {
+	return fmt.Sprintf(`{
+    host     = %q
+    port     = %q
+    user     = %q
+    password = %q
+  }`, n.Host, n.Port, n.User, n.Password)
+}
+
+// id is the id of the node resources, the host and port of the node.
+func (n testAccNode) id() string {
+	return net.JoinHostPort(n.Host, n.Port)
+}
+
+func (n testAccNode) importId() string {
+	return fmt.Sprintf("%s@%s:%s/%s", n.User, n.Host, n.Port, testAccClusterName)
+}
+
+func (n testAccNode) sshConfig() *ssh_handler.SshConfig {
+	return ssh_handler.NewSshConfig(n.Host, n.Port, n.User, n.Password, "", "")
+}
+
+func testAccEnv(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// testAccPreCheck fails the test unless every node is reachable and has the airgap artifacts of both K3S versions
+// mounted, so that no test ends up downloading K3S.
+func testAccPreCheck(t *testing.T, nodes ...testAccNode) {
+	t.Helper()
+
+	for _, node := range nodes {
+		_, err := remote.Run(context.Background(), node.sshConfig(), fmt.Sprintf(
+			"test -f /opt/k3s-airgap/install.sh && test -f /opt/k3s-airgap/%s/k3s && test -f /opt/k3s-airgap/%s/k3s",
+			testAccK3sVersion, testAccK3sUpgradeVersion,
+		))
+		if err != nil {
+			t.Fatalf(
+				"the node %s:%s is not ready for the acceptance tests, start docker/docker-compose.yml after running "+
+					"docker/airgap/fetch.sh %s %s: %s",
+				node.Host, node.Port, testAccK3sVersion, testAccK3sUpgradeVersion, err,
+			)
+		}
+	}
+}
+
+// testAccCheckUninstalled connects to every node and checks that the uninstall script removed K3S.
+func testAccCheckUninstalled(nodes ...testAccNode) func(*terraform.State) error {
+	return func(_ *terraform.State) error {
+		for _, node := range nodes {
+			info, err := remote.DiscoverNode(context.Background(), node.sshConfig())
+			if err != nil {
+				return fmt.Errorf("failed to inspect the node %s:%s: %w", node.Host, node.Port, err)
+			}
+			if info.Role != remote.NodeRoleNone {
+				return fmt.Errorf("expected k3s to be uninstalled from the node %s:%s, found a %s", node.Host, node.Port, info.Role)
+			}
+
+			output, err := remote.Run(context.Background(), node.sshConfig(), "if [ -e /usr/local/bin/k3s ]; then echo present; fi")
+			if err != nil {
+				return fmt.Errorf("failed to inspect the node %s:%s: %w", node.Host, node.Port, err)
+			}
+			if strings.TrimSpace(string(output)) != "" {
+				return fmt.Errorf("expected the k3s binary to be removed from the node %s:%s", node.Host, node.Port)
+			}
+		}
+		return nil
+	}
+}
+
+func testAccClusterConfig(k3sVersion string) string {
+	return fmt.Sprintf(`
+resource "yoshik3s_cluster" "test" {
+  name        = %q
+  token       = %q
+  address     = %q
+  k3s_version = %q
+}
+`, testAccClusterName, testAccClusterToken, testAccClusterAddress, k3sVersion)
+}
+
+// testAccList renders the values as a list of strings.
+func testAccList(values ...string) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, fmt.Sprintf("%q", value))
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"testing"
+)
+
+func TestAccWorkerNodeResource(t *testing.T) {
+	// The import reads the SSH password from the environment, as the import ID cannot carry it.
+	t.Setenv("YOSHIK3S_SSH_PASSWORD", testAccWorkerNode.Password)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t, testAccMasterNode, testAccWorkerNode) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckUninstalled(testAccWorkerNode, testAccMasterNode),
+		Steps: []resource.TestStep{
+			// Create
+			{
+				Config: testAccWorkerNodeConfig(testAccK3sVersion, "--node-label role=worker"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("yoshik3s_worker_node.test", "id", testAccWorkerNode.id()),
+					resource.TestCheckResourceAttr("yoshik3s_worker_node.test", "cluster.address", testAccClusterAddress),
+					resource.TestCheckResourceAttr("yoshik3s_worker_node.test", "cluster.k3s_version", testAccK3sVersion),
+					resource.TestCheckResourceAttr("yoshik3s_worker_node.test", "node_options.0", "--node-label role=worker"),
+				),
+			},
+			// Update the node options
+			{
+				Config: testAccWorkerNodeConfig(testAccK3sVersion, "--node-label role=worker", "--node-label tier=acc"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("yoshik3s_worker_node.test", "node_options.#", "2"),
+					resource.TestCheckResourceAttr("yoshik3s_worker_node.test", "node_options.1", "--node-label tier=acc"),
+				),
+			},
+			// Upgrade K3S on both nodes
+			{
+				Config: testAccWorkerNodeConfig(testAccK3sUpgradeVersion, "--node-label role=worker", "--node-label tier=acc"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("yoshik3s_master_node.test", "cluster.k3s_version", testAccK3sUpgradeVersion),
+					resource.TestCheckResourceAttr("yoshik3s_worker_node.test", "cluster.k3s_version", testAccK3sUpgradeVersion),
+				),
+			},
+			// Import
+			{
+				ResourceName:      "yoshik3s_worker_node.test",
+				ImportState:       true,
+				ImportStateId:     testAccWorkerNode.importId(),
+				ImportStateVerify: true,
+			},
+			// Destroy runs after the last step and is verified by CheckDestroy.
+		},
+	})
+}
+
+func testAccWorkerNodeConfig(k3sVersion string, options ...string) string {
+	return testAccMasterNodeConfig(k3sVersion, "--disable traefik") + fmt.Sprintf(`
+resource "yoshik3s_worker_node" "test" {
+  cluster_id = yoshik3s_cluster.test.id
+
+  node_connection = %s
+
+  node_options = %s
+
+  depends_on = [yoshik3s_master_node.test]
+}
+`, testAccWorkerNode.connection(), testAccList(options...))
+}
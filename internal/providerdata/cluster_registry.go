@@ -0,0 +1,49 @@
+package providerdata
+
+import (
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/model"
+	"sync"
+)
+
+// ClusterRegistry holds the yoshik3s_cluster resources known to the provider during a Terraform run,
+// allowing node resources to reference a cluster by its id instead of copying its attributes.
+//
+// Clusters are registered when they are read, planned, created or updated, which Terraform always does
+// before handling the nodes referencing them.
+type ClusterRegistry struct {
+	mutex    sync.RWMutex
+	clusters map[string]model.YoshiK3SClusterResourceModel
+}
+
+func NewClusterRegistry() *ClusterRegistry {
+	return &ClusterRegistry{
+		clusters: make(map[string]model.YoshiK3SClusterResourceModel),
+	}
+}
+
+// Register stores the cluster, ignoring clusters whose id is not known yet.
+func (r *ClusterRegistry) Register(cluster model.YoshiK3SClusterResourceModel) {
+	if cluster.Id.IsNull() || cluster.Id.IsUnknown() {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.clusters[cluster.Id.ValueString()] = cluster
+}
+
+func (r *ClusterRegistry) Get(id string) (model.YoshiK3SClusterResourceModel, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	cluster, found := r.clusters[id]
+	return cluster, found
+}
+
+func (r *ClusterRegistry) Remove(id string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.clusters, id)
+}
@@ -0,0 +1,44 @@
+package providerdata
+
+import (
+	"context"
+	"sync"
+)
+
+// HostLocks serializes the operations changing the same host during a Terraform run, since Terraform applies
+// resources concurrently and two installer runs on one node corrupt it. Hosts are identified by their host:port.
+type HostLocks struct {
+	mutex sync.Mutex
+	locks map[string]chan struct{}
+}
+
+func NewHostLocks() *HostLocks {
+	return &HostLocks{
+		locks: make(map[string]chan struct{}),
+	}
+}
+
+// Lock waits until the host is free or the context is done, and returns the function releasing the host.
+func (l *HostLocks) Lock(ctx context.Context, address string) (func(), error) {
+	lock := l.get(address)
+
+	select {
+	case lock <- struct{}{}:
+		return func() { <-lock }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (l *HostLocks) get(address string) chan struct{} {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	lock, found := l.locks[address]
+	if !found {
+		lock = make(chan struct{}, 1)
+		l.locks[address] = lock
+	}
+
+	return lock
+}
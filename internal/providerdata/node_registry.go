@@ -0,0 +1,50 @@
+package providerdata
+
+import (
+	"sync"
+)
+
+// NodeRegistry holds the node options of the yoshik3s_master_node resources known to the provider during a
+// Terraform run, keyed by the host and port of their connection, allowing resources deployed on a master node to
+// check their configuration against the components enabled on it.
+//
+// Like clusters, nodes are registered when they are read, planned, created or updated. A node left out of the run,
+// e.g. with -target, is not registered, so the checks relying on the registry are advisory.
+type NodeRegistry struct {
+	mutex sync.RWMutex
+	nodes map[string][]string
+}
+
+func NewNodeRegistry() *NodeRegistry {
+	return &NodeRegistry{
+		nodes: make(map[string][]string),
+	}
+}
+
+// Register stores the node options of the address, the host and port of the node, ignoring addresses that are not
+// known yet.
+func (r *NodeRegistry) Register(address string, options []string) {
+	if address == "" {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.nodes[address] = options
+}
+
+func (r *NodeRegistry) Get(address string) ([]string, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	options, found := r.nodes[address]
+	return options, found
+}
+
+func (r *NodeRegistry) Remove(address string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.nodes, address)
+}
@@ -0,0 +1,37 @@
+package providerdata
+
+import (
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/executor"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/remote"
+	"time"
+)
+
+// Data is shared by the provider with every resource through their Configure method.
+type Data struct {
+	Clusters *ClusterRegistry
+	Masters  *NodeRegistry
+	Hosts    *HostLocks
+
+	// Executor performs the operations of the node resources on their hosts.
+	Executor executor.Executor
+	// ConnectionRetry applies to the node connections without connection_retry settings.
+	ConnectionRetry remote.RetryPolicy
+	// Connections caches the SSH connections to the hosts, they are dialed for every operation when it is nil.
+	Connections *remote.ConnectionPool
+	// RemoteLock is the lock file taken on the hosts while changing them, nil when remote_lock is not set.
+	RemoteLock *remote.LockOptions
+	// ReadTimeout bounds the inspection of the hosts while refreshing the resources.
+	ReadTimeout time.Duration
+}
+
+func New(executor executor.Executor) *Data {
+	return &Data{
+		Clusters: NewClusterRegistry(),
+		Masters:  NewNodeRegistry(),
+		Hosts:    NewHostLocks(),
+		Executor: executor,
+
+		ConnectionRetry: remote.NoRetry,
+		ReadTimeout:     remote.DefaultReadTimeout,
+	}
+}
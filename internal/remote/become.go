@@ -0,0 +1,179 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"net"
+	"strings"
+)
+
+// BecomeMethod is the way commands are run with the privileges of another user, root by default.
+type BecomeMethod string
+
+const (
+	// BecomeSudo runs the commands with sudo, reading the password from the standard input when one is known.
+	BecomeSudo BecomeMethod = "sudo"
+	// BecomeDoas runs the commands with doas, which cannot read a password and requires a nopass rule.
+	BecomeDoas BecomeMethod = "doas"
+	// BecomeNone runs the commands as the SSH user, which must be root.
+	BecomeNone BecomeMethod = "none"
+)
+
+// BecomeMethods are the supported values of the method of the become settings.
+var BecomeMethods = []string{string(BecomeSudo), string(BecomeDoas), string(BecomeNone)}
+
+// sudoPrefix reads the password from stdin without printing a prompt, so the
+// command output is not polluted when the user requires a sudo password.
+const sudoPrefix = "sudo -S -p ''"
+
+// sudoNonInteractivePrefix is used when no password is known, sudo failing right away instead of waiting for one
+// when the SSH user is not allowed to run it without a password.
+const sudoNonInteractivePrefix = "sudo -n"
+
+// Become describes how the commands needing privileges are run on a host.
+type Become struct {
+	Method BecomeMethod
+	// User is the user the commands run as, it can only be root since K3S is installed and managed as root.
+	User string
+	// Password is the password of the sudo prompt, the SSH password is reused when empty.
+	Password string
+}
+
+// DefaultBecome is used when no become settings are given, running the commands with sudo as root.
+var DefaultBecome = Become{Method: BecomeSudo}
+
+// NewBecome parses the become settings, an empty method selects sudo.
+func NewBecome(method string, user string, password string) (Become, error) {
+	become := Become{
+		Method:   BecomeMethod(method),
+		User:     user,
+		Password: password,
+	}
+	if become.Method == "" {
+		become.Method = BecomeSudo
+	}
+
+	// The K3S scripts, files and services are only managed as root, another user would fail halfway through an
+	// installation.
+	if user != "" && user != "root" {
+		return become, fmt.Errorf("the commands must run as root, got the user %q", user)
+	}
+
+	switch become.Method {
+	case BecomeSudo:
+	case BecomeDoas:
+		if password != "" {
+			return become, fmt.Errorf("doas cannot read a password from the standard input, configure a nopass rule for the SSH user instead")
+		}
+	case BecomeNone:
+		if password != "" {
+			return become, fmt.Errorf("the method none does not use a password")
+		}
+	default:
+		return become, fmt.Errorf("method must be one of %s, got: %q", strings.Join(BecomeMethods, ", "), method)
+	}
+
+	return become, nil
+}
+
+// Command prefixes the command run on the host so that it runs with the privileges of the become user. sudo reads
+// the password from the standard input when one is known, and never prompts otherwise.
+func (b Become) Command(config *ssh_handler.SshConfig, command string) string {
+	switch b.Method {
+	case BecomeDoas:
+		// doas never prompts with -n, failing right away when the SSH user has no nopass rule.
+		if b.User != "" {
+			return "doas -n -u " + ShellQuote(b.User) + " " + command
+		}
+		return "doas -n " + command
+	case BecomeNone:
+		return command
+	default:
+		prefix := sudoNonInteractivePrefix
+		if b.password(config) != "" {
+			prefix = sudoPrefix
+		}
+		if b.User != "" {
+			return prefix + " -u " + ShellQuote(b.User) + " " + command
+		}
+		return prefix + " " + command
+	}
+}
+
+// Stdin returns the standard input of the commands prefixed by Command, which carries the sudo password when one is
+// known.
+func (b Become) Stdin(config *ssh_handler.SshConfig) []byte {
+	if b.Method != BecomeSudo && b.Method != "" {
+		return nil
+	}
+	if password := b.password(config); password != "" {
+		return []byte(password + "\n")
+	}
+
+	return nil
+}
+
+// password returns the sudo password, the SSH password of the host when none is set. It is empty when the SSH user
+// logs in with a private key and no sudo password is set.
+func (b Become) password(config *ssh_handler.SshConfig) string {
+	if b.Password != "" {
+		return b.Password
+	}
+
+	return config.GetPassword()
+}
+
+// String describes the settings in the errors, e.g. "sudo as root".
+func (b Become) String() string {
+	switch b.Method {
+	case BecomeNone:
+		return "the SSH user"
+	case BecomeDoas:
+		return "doas as root"
+	default:
+		return "sudo as root"
+	}
+}
+
+type becomeKey struct{}
+
+// WithBecome returns a context in which the commands needing privileges on the host run with the become settings.
+// The settings are kept per host, since a resource may connect to several nodes with different settings.
+func WithBecome(ctx context.Context, config *ssh_handler.SshConfig, become Become) context.Context {
+	current, _ := ctx.Value(becomeKey{}).(map[string]Become)
+
+	settings := make(map[string]Become, len(current)+1)
+	for address, hostBecome := range current {
+		settings[address] = hostBecome
+	}
+	settings[net.JoinHostPort(config.GetHost(), config.GetPort())] = become
+
+	return context.WithValue(ctx, becomeKey{}, settings)
+}
+
+// BecomeFromContext returns the become settings of the host, DefaultBecome when the context has none.
+func BecomeFromContext(ctx context.Context, config *ssh_handler.SshConfig) Become {
+	settings, _ := ctx.Value(becomeKey{}).(map[string]Become)
+	if become, ok := settings[net.JoinHostPort(config.GetHost(), config.GetPort())]; ok {
+		return become
+	}
+
+	return DefaultBecome
+}
+
+// CheckBecome verifies that the commands needing privileges can run on the host, so that a misconfigured escalation
+// fails before anything is changed rather than halfway through an installation.
+func CheckBecome(ctx context.Context, config *ssh_handler.SshConfig) error {
+	become := BecomeFromContext(ctx, config)
+
+	output, err := RunAsRoot(ctx, config, "id -un")
+	if err != nil {
+		return fmt.Errorf("failed to run commands with %s, check the become settings of the connection: %w", become, err)
+	}
+	if user := strings.TrimSpace(string(output)); user != "root" {
+		return fmt.Errorf("commands run with %s run as %q instead of \"root\", check the become settings of the connection", become, user)
+	}
+
+	return nil
+}
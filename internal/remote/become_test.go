@@ -0,0 +1,116 @@
+package remote
+
+import (
+	"context"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/sshtest"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"strings"
+	"testing"
+)
+
+func TestNewBecome(t *testing.T) {
+	for name, test := range map[string]struct {
+		method   string
+		user     string
+		password string
+		expected Become
+		err      string
+	}{
+		"defaults":           {expected: Become{Method: BecomeSudo}},
+		"sudo as root":       {method: "sudo", user: "root", password: "secret", expected: Become{Method: BecomeSudo, User: "root", Password: "secret"}},
+		"doas":               {method: "doas", expected: Become{Method: BecomeDoas}},
+		"none":               {method: "none", user: "root", expected: Become{Method: BecomeNone, User: "root"}},
+		"unknown method":     {method: "su", err: "method must be one of"},
+		"doas with password": {method: "doas", password: "secret", err: "nopass"},
+		"sudo with user":     {method: "sudo", user: "k3s", err: "must run as root"},
+		"doas with user":     {method: "doas", user: "k3s", err: "must run as root"},
+		"none with user":     {method: "none", user: "k3s", err: "must run as root"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			become, err := NewBecome(test.method, test.user, test.password)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected an error about %s, got: %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if become != test.expected {
+				t.Errorf("expected %+v, got %+v", test.expected, become)
+			}
+		})
+	}
+}
+
+func TestRunAsRootWithBecome(t *testing.T) {
+	server := sshtest.NewServer(t)
+	host := ssh_handler.NewSshConfig(server.Host(), server.Port(), server.User, server.Password, "", "")
+
+	for _, test := range []struct {
+		become  Become
+		command string
+		stdin   string
+	}{
+		{DefaultBecome, "sudo -S -p '' systemctl restart k3s", server.Password + "\n"},
+		{Become{Method: BecomeSudo, User: "root", Password: "sudo-secret"}, "sudo -S -p '' -u 'root' systemctl restart k3s", "sudo-secret\n"},
+		{Become{Method: BecomeDoas}, "doas -n systemctl restart k3s", ""},
+		{Become{Method: BecomeNone}, "systemctl restart k3s", ""},
+	} {
+		t.Run(string(test.become.Method)+test.become.User, func(t *testing.T) {
+			server.Reset()
+
+			ctx := WithBecome(context.Background(), host, test.become)
+			if _, err := RunAsRoot(ctx, host, "systemctl restart k3s"); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			commands := server.Commands()
+			if len(commands) != 1 || commands[0].Command != test.command || commands[0].Stdin != test.stdin {
+				t.Errorf("expected %q with %q on the standard input, got: %v", test.command, test.stdin, commands)
+			}
+		})
+	}
+}
+
+func TestBecomeWithoutPassword(t *testing.T) {
+	// The SSH user logs in with a private key, so no password is known for the sudo prompt.
+	host := ssh_handler.NewSshConfig("10.0.0.1", "22", "k3s", "", "private-key", "")
+
+	if command := DefaultBecome.Command(host, "k3s --version"); command != "sudo -n k3s --version" {
+		t.Errorf("expected sudo not to prompt for a password, got: %s", command)
+	}
+	if stdin := DefaultBecome.Stdin(host); stdin != nil {
+		t.Errorf("expected no standard input, got: %q", stdin)
+	}
+
+	become := Become{Method: BecomeSudo, Password: "sudo-secret"}
+	if command := become.Command(host, "k3s --version"); command != "sudo -S -p '' k3s --version" {
+		t.Errorf("expected sudo to read the become password, got: %s", command)
+	}
+	if stdin := string(become.Stdin(host)); stdin != "sudo-secret\n" {
+		t.Errorf("expected the become password on the standard input, got: %q", stdin)
+	}
+}
+
+func TestCheckBecome(t *testing.T) {
+	server := sshtest.NewServer(t)
+	host := ssh_handler.NewSshConfig(server.Host(), server.Port(), server.User, server.Password, "", "")
+	ctx := context.Background()
+
+	if err := CheckBecome(ctx, host); err != nil {
+		t.Fatalf("expected the escalation to work, got: %s", err)
+	}
+
+	server.Respond(`id -un$`, server.User+"\n")
+	if err := CheckBecome(WithBecome(ctx, host, Become{Method: BecomeNone}), host); err == nil || !strings.Contains(err.Error(), `run as "`+server.User+`" instead of "root"`) {
+		t.Errorf("expected the escalation to be reported as not running as root, got: %v", err)
+	}
+
+	server.Fail(`id -un$`, "sudo: a password is required\n", 1)
+	err := CheckBecome(ctx, host)
+	if err == nil || !strings.Contains(err.Error(), "with sudo as root") || !strings.Contains(err.Error(), "a password is required") {
+		t.Errorf("expected the escalation failure to name the method and the sudo error, got: %v", err)
+	}
+}
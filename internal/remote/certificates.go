@@ -0,0 +1,101 @@
+package remote
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// K3sServerTlsPath is the directory holding the certificates of a server node.
+const K3sServerTlsPath = "/var/lib/rancher/k3s/server/tls"
+
+// certificateFileMarker separates the certificate files in the output of ReadCertificateExpiry.
+const certificateFileMarker = "==> "
+
+// ReadCertificateExpiry returns the expiry of every certificate of a server node, keyed by the certificate file name
+// without its extension, e.g. client-admin.
+func ReadCertificateExpiry(commands CommandRunner) (map[string]time.Time, error) {
+	script := fmt.Sprintf(
+		`for f in %s/*.crt; do [ -f "$f" ] || continue; echo "%s$f"; cat "$f"; done`,
+		K3sServerTlsPath, certificateFileMarker,
+	)
+
+	output, err := commands.Query("sh -c "+ShellQuote(script), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the certificates in %s: %w", K3sServerTlsPath, err)
+	}
+
+	return ParseCertificateExpiry(string(output))
+}
+
+// ParseCertificateExpiry parses the certificate files printed by ReadCertificateExpiry, each one preceded by
+// a line holding the marker and its path. Only the first certificate of a bundle is considered, the leaf.
+func ParseCertificateExpiry(output string) (map[string]time.Time, error) {
+	expiry := map[string]time.Time{}
+
+	for _, section := range strings.Split(output, certificateFileMarker) {
+		filePath, content, found := strings.Cut(section, "\n")
+		if !found || strings.TrimSpace(filePath) == "" {
+			continue
+		}
+
+		block, _ := pem.Decode([]byte(content))
+		if block == nil || block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the certificate %s: %w", filePath, err)
+		}
+
+		name := strings.TrimSuffix(path.Base(strings.TrimSpace(filePath)), ".crt")
+		expiry[name] = certificate.NotAfter.UTC()
+	}
+
+	return expiry, nil
+}
+
+// RotateCertificates renews the certificates of a server node, k3s must be stopped while they are rotated.
+func RotateCertificates(commands CommandRunner) error {
+	if err := StopK3s(commands); err != nil {
+		return err
+	}
+
+	if _, err := commands.Run("k3s certificate rotate", nil); err != nil {
+		// Bring the node back with its previous certificates.
+		_ = StartK3s(commands)
+		return fmt.Errorf("failed to rotate the certificates: %w", err)
+	}
+
+	return StartK3s(commands)
+}
+
+// WriteCertificateAuthorityFiles writes custom certificate authority files, keyed by their path relative to the
+// server tls directory, e.g. etcd/server-ca.crt. K3S uses them instead of generating its own when they are
+// present before its first start. Private keys are only readable by root.
+func WriteCertificateAuthorityFiles(files FileWriter, authority map[string][]byte) error {
+	names := make([]string, 0, len(authority))
+	for name := range authority {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		mode := os.FileMode(0644)
+		if strings.HasSuffix(name, ".key") {
+			mode = 0600
+		}
+
+		if err := files.WriteFile(path.Join(K3sServerTlsPath, name), authority[name], mode); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,217 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/crypto/ssh"
+	"net"
+	"strings"
+	"time"
+)
+
+// dialTimeout bounds the TCP connection and the SSH handshake of every attempt, so that a host still booting does
+// not hang an attempt until the operating system gives up.
+const dialTimeout = 30 * time.Second
+
+// Defaults of the connection_retry settings.
+const (
+	DefaultRetryMaxAttempts  = 5
+	DefaultRetryInitialDelay = time.Second
+	DefaultRetryMaxDelay     = 30 * time.Second
+)
+
+// RetryPolicy tells how many times, and how long apart, the connections failing to dial or to complete the SSH
+// handshake are attempted. The delay doubles after every attempt, up to MaxDelay.
+type RetryPolicy struct {
+	MaxAttempts  int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+}
+
+// NoRetry attempts the connections once, it is used when no connection_retry settings are given.
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// NewRetryPolicy parses the connection_retry settings, a nil or empty setting selects its default.
+func NewRetryPolicy(maxAttempts *int64, initialDelay string, maxDelay string) (RetryPolicy, error) {
+	policy := RetryPolicy{
+		MaxAttempts:  DefaultRetryMaxAttempts,
+		InitialDelay: DefaultRetryInitialDelay,
+		MaxDelay:     DefaultRetryMaxDelay,
+	}
+
+	if maxAttempts != nil {
+		if *maxAttempts < 1 {
+			return policy, fmt.Errorf("max_attempts must be at least 1, got: %d", *maxAttempts)
+		}
+		policy.MaxAttempts = int(*maxAttempts)
+	}
+
+	var err error
+	if initialDelay != "" {
+		if policy.InitialDelay, err = time.ParseDuration(initialDelay); err != nil || policy.InitialDelay < 0 {
+			return policy, fmt.Errorf("initial_delay must be a non-negative duration, e.g. 1s or 500ms, got: %q", initialDelay)
+		}
+	}
+	if maxDelay != "" {
+		if policy.MaxDelay, err = time.ParseDuration(maxDelay); err != nil || policy.MaxDelay < 0 {
+			return policy, fmt.Errorf("max_delay must be a non-negative duration, e.g. 30s or 1m, got: %q", maxDelay)
+		}
+	}
+	if policy.MaxDelay < policy.InitialDelay {
+		return policy, fmt.Errorf("max_delay (%s) must not be shorter than initial_delay (%s)", policy.MaxDelay, policy.InitialDelay)
+	}
+
+	return policy, nil
+}
+
+type retryPoliciesKey struct{}
+
+// WithRetryPolicy returns a context in which the connections to the host are retried according to the policy. The
+// policies are kept per host, since a resource may connect to several nodes with different settings.
+func WithRetryPolicy(ctx context.Context, config *ssh_handler.SshConfig, policy RetryPolicy) context.Context {
+	current, _ := ctx.Value(retryPoliciesKey{}).(map[string]RetryPolicy)
+
+	policies := make(map[string]RetryPolicy, len(current)+1)
+	for address, hostPolicy := range current {
+		policies[address] = hostPolicy
+	}
+	policies[net.JoinHostPort(config.GetHost(), config.GetPort())] = policy
+
+	return context.WithValue(ctx, retryPoliciesKey{}, policies)
+}
+
+func retryPolicyFromContext(ctx context.Context, address string) RetryPolicy {
+	policies, _ := ctx.Value(retryPoliciesKey{}).(map[string]RetryPolicy)
+	if policy, ok := policies[address]; ok && policy.MaxAttempts > 0 {
+		return policy
+	}
+
+	return NoRetry
+}
+
+// AuthenticationError is returned when the host refuses the credentials of the connection, which is never retried.
+type AuthenticationError struct {
+	User    string
+	Address string
+
+	err error
+}
+
+func (e *AuthenticationError) Error() string {
+	return fmt.Sprintf(
+		"the host %s refused the credentials of the user %s, check the password or private key of the connection: %s",
+		e.Address, e.User, e.err,
+	)
+}
+
+func (e *AuthenticationError) Unwrap() error {
+	return e.err
+}
+
+// Dial opens an SSH connection authenticated like the yoshi-k3s handler, for protocols it does not expose such as SFTP.
+// Failures to dial the host or to complete the handshake are retried according to the retry policy of the context.
+func Dial(ctx context.Context, config *ssh_handler.SshConfig) (*ssh.Client, error) {
+	if config.GetHost() == "" || config.GetPort() == "" {
+		return nil, fmt.Errorf("host and port must be set")
+	}
+
+	auth, err := authMethod(config)
+	if err != nil {
+		return nil, err
+	}
+
+	address := net.JoinHostPort(config.GetHost(), config.GetPort())
+	clientConfig := &ssh.ClientConfig{
+		User:            config.GetUser(),
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Auth:            []ssh.AuthMethod{auth},
+		Timeout:         dialTimeout,
+	}
+
+	policy := retryPolicyFromContext(ctx, address)
+	delay := policy.InitialDelay
+	for attempt := 1; ; attempt++ {
+		client, err := dialOnce(ctx, address, clientConfig)
+		if err == nil {
+			return client, nil
+		}
+
+		if isAuthenticationFailure(err) {
+			return nil, &AuthenticationError{User: config.GetUser(), Address: address, err: err}
+		}
+		if attempt >= policy.MaxAttempts {
+			if attempt > 1 {
+				return nil, fmt.Errorf("failed to connect to %s after %d attempts: %w", address, attempt, err)
+			}
+			return nil, err
+		}
+
+		logRemote(ctx, tflog.SubsystemWarn, tflog.Warn, "failed to connect, retrying", map[string]interface{}{
+			"host":    address,
+			"attempt": attempt,
+			"delay":   delay.String(),
+			"error":   err.Error(),
+		})
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("failed to connect to %s: %w", address, errors.Join(err, ctx.Err()))
+		case <-time.After(delay):
+		}
+
+		delay = min(delay*2, policy.MaxDelay)
+	}
+}
+
+func dialOnce(ctx context.Context, address string, clientConfig *ssh.ClientConfig) (*ssh.Client, error) {
+	dialer := net.Dialer{Timeout: clientConfig.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	// The handshake is bounded as well, hosts still booting may accept connections before sshd answers them.
+	if err := conn.SetDeadline(time.Now().Add(clientConfig.Timeout)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	sshConn, channels, requests, err := ssh.NewClientConn(conn, address, clientConfig)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		sshConn.Close()
+		return nil, err
+	}
+
+	return ssh.NewClient(sshConn, channels, requests), nil
+}
+
+func authMethod(config *ssh_handler.SshConfig) (ssh.AuthMethod, error) {
+	switch {
+	case config.GetPassword() != "":
+		return ssh.Password(config.GetPassword()), nil
+	case config.GetPrivateKeyPassphrase() != "":
+		signer, err := ssh.ParsePrivateKeyWithPassphrase([]byte(config.GetPrivateKey()), []byte(config.GetPrivateKeyPassphrase()))
+		if err != nil {
+			return nil, err
+		}
+		return ssh.PublicKeys(signer), nil
+	default:
+		signer, err := ssh.ParsePrivateKey([]byte(config.GetPrivateKey()))
+		if err != nil {
+			return nil, err
+		}
+		return ssh.PublicKeys(signer), nil
+	}
+}
+
+// isAuthenticationFailure tells whether the handshake failed because the host refused every authentication method,
+// golang.org/x/crypto/ssh does not return a typed error for it.
+func isAuthenticationFailure(err error) bool {
+	return strings.Contains(err.Error(), "unable to authenticate")
+}
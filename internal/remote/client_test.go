@@ -0,0 +1,170 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/sshtest"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyProxy forwards the connections to the server, except the first ones which are closed before the handshake,
+// like a host whose sshd is still starting.
+func flakyProxy(t *testing.T, server *sshtest.Server, failures int) *ssh_handler.SshConfig {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %s", err)
+	}
+
+	var waitGroup sync.WaitGroup
+	t.Cleanup(func() {
+		_ = listener.Close()
+		waitGroup.Wait()
+	})
+
+	waitGroup.Add(1)
+	go func() {
+		defer waitGroup.Done()
+
+		for accepted := 0; ; accepted++ {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			if accepted < failures {
+				_ = conn.Close()
+				continue
+			}
+
+			upstream, err := net.Dial("tcp", net.JoinHostPort(server.Host(), server.Port()))
+			if err != nil {
+				_ = conn.Close()
+				continue
+			}
+
+			waitGroup.Add(2)
+			go func() {
+				defer waitGroup.Done()
+				_, _ = io.Copy(upstream, conn)
+				_ = upstream.Close()
+			}()
+			go func() {
+				defer waitGroup.Done()
+				_, _ = io.Copy(conn, upstream)
+				_ = conn.Close()
+			}()
+		}
+	}()
+
+	port := strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+	return ssh_handler.NewSshConfig("127.0.0.1", port, server.User, server.Password, "", "")
+}
+
+func TestDialRetriesHandshakeFailures(t *testing.T) {
+	server := sshtest.NewServer(t)
+	host := flakyProxy(t, server, 2)
+
+	ctx := WithRetryPolicy(context.Background(), host, RetryPolicy{
+		MaxAttempts:  3,
+		InitialDelay: 10 * time.Millisecond,
+		MaxDelay:     20 * time.Millisecond,
+	})
+
+	client, err := Dial(ctx, host)
+	if err != nil {
+		t.Fatalf("expected the third attempt to succeed, got: %s", err)
+	}
+	_ = client.Close()
+}
+
+func TestDialGivesUpAfterMaxAttempts(t *testing.T) {
+	server := sshtest.NewServer(t)
+	host := flakyProxy(t, server, 3)
+
+	ctx := WithRetryPolicy(context.Background(), host, RetryPolicy{
+		MaxAttempts:  2,
+		InitialDelay: 10 * time.Millisecond,
+		MaxDelay:     10 * time.Millisecond,
+	})
+
+	if _, err := Dial(ctx, host); err == nil || !strings.Contains(err.Error(), "after 2 attempts") {
+		t.Fatalf("expected the connection to fail after 2 attempts, got: %v", err)
+	}
+
+	// Without a retry policy, the connection is attempted once.
+	if _, err := Dial(context.Background(), host); err == nil || strings.Contains(err.Error(), "attempts") {
+		t.Fatalf("expected the connection to fail after a single attempt, got: %v", err)
+	}
+}
+
+func TestDialFailsFastOnAuthenticationFailure(t *testing.T) {
+	server := sshtest.NewServer(t)
+	host := ssh_handler.NewSshConfig(server.Host(), server.Port(), server.User, "wrong-password", "", "")
+
+	ctx := WithRetryPolicy(context.Background(), host, RetryPolicy{
+		MaxAttempts:  5,
+		InitialDelay: time.Minute,
+		MaxDelay:     time.Minute,
+	})
+
+	_, err := Dial(ctx, host)
+
+	var authenticationError *AuthenticationError
+	if !errors.As(err, &authenticationError) {
+		t.Fatalf("expected an authentication error, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "refused the credentials of the user "+server.User) {
+		t.Errorf("expected the error to name the user, got: %s", err)
+	}
+}
+
+func TestNewRetryPolicy(t *testing.T) {
+	three := int64(3)
+	zero := int64(0)
+
+	for name, test := range map[string]struct {
+		maxAttempts  *int64
+		initialDelay string
+		maxDelay     string
+		expected     RetryPolicy
+		err          string
+	}{
+		"defaults": {
+			expected: RetryPolicy{MaxAttempts: DefaultRetryMaxAttempts, InitialDelay: DefaultRetryInitialDelay, MaxDelay: DefaultRetryMaxDelay},
+		},
+		"custom": {
+			maxAttempts:  &three,
+			initialDelay: "500ms",
+			maxDelay:     "2s",
+			expected:     RetryPolicy{MaxAttempts: 3, InitialDelay: 500 * time.Millisecond, MaxDelay: 2 * time.Second},
+		},
+		"no attempts":         {maxAttempts: &zero, err: "max_attempts"},
+		"invalid delay":       {initialDelay: "soon", err: "initial_delay"},
+		"max delay too short": {initialDelay: "10s", maxDelay: "1s", err: "must not be shorter"},
+		"negative max delay":  {maxDelay: "-1s", err: "max_delay"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			policy, err := NewRetryPolicy(test.maxAttempts, test.initialDelay, test.maxDelay)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected an error about %s, got: %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if policy != test.expected {
+				t.Errorf("expected %+v, got %+v", test.expected, policy)
+			}
+		})
+	}
+}
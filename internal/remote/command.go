@@ -0,0 +1,289 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/crypto/ssh"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LogSubsystem is the tflog subsystem of the remote commands, its level is set by TF_LOG_PROVIDER_YOSHIK3S_SSH.
+const LogSubsystem = "ssh"
+
+const logLevelEnvVar = "TF_LOG_PROVIDER_YOSHIK3S_SSH"
+
+const (
+	// logOutputSize is the number of trailing bytes of the command outputs added to the logs.
+	logOutputSize = 4096
+	// errorOutputLines is the number of trailing lines of the command outputs added to the errors.
+	errorOutputLines = 20
+)
+
+const masked = "***"
+
+// sensitiveLogFields are the keys of the log fields whose values are masked.
+var sensitiveLogFields = []string{"token", "password", "private_key", "private_key_passphrase"}
+
+// terminalModes are the modes of the pseudo terminal, the input is not echoed so the sudo password is not
+// printed back.
+var terminalModes = ssh.TerminalModes{
+	ssh.ECHO:          0,
+	ssh.TTY_OP_ISPEED: 14400,
+	ssh.TTY_OP_OSPEED: 14400,
+}
+
+type commandLoggingKey struct{}
+
+// commandLogging holds the values masked from the logs and the errors of the commands run with a context.
+type commandLogging struct {
+	secrets         []string
+	sensitiveOutput bool
+	// subsystem is set once the ssh subsystem is set up.
+	subsystem bool
+}
+
+// WithCommandLogging returns a context logging the commands run with it in the ssh subsystem, with the resource
+// and phase fields, e.g. "yoshik3s_master_node" and "create". The secrets, as well as the password and private key
+// passphrase of the hosts, are masked from the logs and from the output added to the errors.
+func WithCommandLogging(ctx context.Context, resource string, phase string, secrets ...string) context.Context {
+	ctx = tflog.MaskFieldValuesWithFieldKeys(ctx, sensitiveLogFields...)
+
+	ctx = tflog.NewSubsystem(ctx, LogSubsystem, tflog.WithLevelFromEnv(logLevelEnvVar))
+	ctx = tflog.SubsystemSetField(ctx, LogSubsystem, "resource", resource)
+	ctx = tflog.SubsystemSetField(ctx, LogSubsystem, "phase", phase)
+	ctx = tflog.SubsystemMaskFieldValuesWithFieldKeys(ctx, LogSubsystem, sensitiveLogFields...)
+
+	logging := loggingFromContext(ctx)
+	logging.subsystem = true
+	ctx = context.WithValue(ctx, commandLoggingKey{}, &logging)
+
+	return WithSecrets(ctx, secrets...)
+}
+
+// WithSecrets returns a context masking the secrets from the logs and the errors of the commands run with it.
+func WithSecrets(ctx context.Context, secrets ...string) context.Context {
+	logging := loggingFromContext(ctx)
+	for _, secret := range secrets {
+		if secret != "" {
+			logging.secrets = append(logging.secrets, secret)
+		}
+	}
+
+	return context.WithValue(ctx, commandLoggingKey{}, &logging)
+}
+
+// WithSensitiveOutput returns a context leaving the standard output of the commands run with it out of the logs
+// and the errors, for the commands printing secrets such as a kubeconfig or a join token.
+func WithSensitiveOutput(ctx context.Context) context.Context {
+	logging := loggingFromContext(ctx)
+	logging.sensitiveOutput = true
+
+	return context.WithValue(ctx, commandLoggingKey{}, &logging)
+}
+
+func loggingFromContext(ctx context.Context) commandLogging {
+	logging, ok := ctx.Value(commandLoggingKey{}).(*commandLogging)
+	if !ok {
+		return commandLogging{}
+	}
+
+	return commandLogging{
+		secrets:         append([]string(nil), logging.secrets...),
+		sensitiveOutput: logging.sensitiveOutput,
+		subsystem:       logging.subsystem,
+	}
+}
+
+// Command is a command run on a host.
+type Command struct {
+	Command string
+	// Stdin is sent as the standard input of the command.
+	Stdin []byte
+	// Terminal runs the command in a pseudo terminal, which the K3S scripts need to prompt for the sudo password.
+	Terminal bool
+}
+
+// CommandError is returned when a command does not exit successfully, it carries the end of the command output.
+type CommandError struct {
+	ExitCode int
+	Duration time.Duration
+	// Output holds the last lines of the standard output and error, with the secrets masked.
+	Output string
+
+	err error
+}
+
+func (e *CommandError) Error() string {
+	message := fmt.Sprintf("%s after %s", e.err, e.Duration.Round(time.Millisecond))
+	if e.Output != "" {
+		message += ", output:\n" + e.Output
+	}
+
+	return message
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.err
+}
+
+// Exec runs the command on the host described by the connection config and returns its standard output. Its exit
+// code, duration and output are logged, and the last lines of its output are added to the error when it fails.
+func Exec(ctx context.Context, config *ssh_handler.SshConfig, command Command) ([]byte, error) {
+	logging := loggingFromContext(ctx)
+	logging.secrets = append(logging.secrets, config.GetPassword(), config.GetPrivateKeyPassphrase())
+	mask := newMasker(logging.secrets)
+
+	fields := map[string]interface{}{
+		"host":    net.JoinHostPort(config.GetHost(), config.GetPort()),
+		"command": mask(command.Command),
+	}
+
+	stdout, stderr, duration, err := execSession(ctx, config, command)
+
+	fields["duration"] = duration.String()
+	fields["stderr"] = mask(tail(stderr, logOutputSize))
+	if logging.sensitiveOutput {
+		fields["stdout"] = fmt.Sprintf("(%d bytes, not logged)", len(stdout))
+	} else {
+		fields["stdout"] = mask(tail(stdout, logOutputSize))
+	}
+
+	exitCode := 0
+	var exitError *ssh.ExitError
+	switch {
+	case errors.As(err, &exitError):
+		exitCode = exitError.ExitStatus()
+	case err != nil:
+		exitCode = -1
+		fields["error"] = mask(err.Error())
+	}
+	fields["exit_code"] = exitCode
+
+	if err == nil {
+		logRemote(ctx, tflog.SubsystemDebug, tflog.Debug, "remote command succeeded", fields)
+		return stdout, nil
+	}
+	logRemote(ctx, tflog.SubsystemWarn, tflog.Warn, "remote command failed", fields)
+
+	output := stderr
+	if !logging.sensitiveOutput {
+		output = append(append([]byte(nil), stdout...), stderr...)
+	}
+
+	return stdout, &CommandError{
+		ExitCode: exitCode,
+		Duration: duration,
+		Output:   mask(tailLines(output, errorOutputLines)),
+		err:      err,
+	}
+}
+
+func execSession(ctx context.Context, config *ssh_handler.SshConfig, command Command) ([]byte, []byte, time.Duration, error) {
+	start := time.Now()
+
+	session, closeSession, err := newSession(ctx, config)
+	if err != nil {
+		return nil, nil, time.Since(start), err
+	}
+	defer closeSession()
+
+	if command.Terminal {
+		if err := session.RequestPty("xterm", 80, 40, terminalModes); err != nil {
+			return nil, nil, time.Since(start), err
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+	if len(command.Stdin) > 0 {
+		session.Stdin = bytes.NewReader(command.Stdin)
+	}
+
+	// Closing the session interrupts the command once the context is done, e.g. when a refresh times out.
+	finished := make(chan struct{})
+	defer close(finished)
+	go func() {
+		select {
+		case <-ctx.Done():
+			session.Close()
+		case <-finished:
+		}
+	}()
+
+	err = session.Run(command.Command)
+	if err != nil && ctx.Err() != nil {
+		err = fmt.Errorf("the command was interrupted: %w", ctx.Err())
+	}
+
+	return stdout.Bytes(), stderr.Bytes(), time.Since(start), err
+}
+
+// logRemote logs in the ssh subsystem when the context was set up by WithCommandLogging, and with the provider
+// logger otherwise.
+func logRemote(
+	ctx context.Context,
+	subsystemLog func(context.Context, string, string, ...map[string]interface{}),
+	rootLog func(context.Context, string, ...map[string]interface{}),
+	message string,
+	fields map[string]interface{},
+) {
+	if loggingFromContext(ctx).subsystem {
+		subsystemLog(ctx, LogSubsystem, message, fields)
+		return
+	}
+
+	rootLog(ctx, message, fields)
+}
+
+// newMasker returns a function replacing the secrets in a string, the longest first so that a secret containing
+// another one is masked whole.
+func newMasker(secrets []string) func(string) string {
+	var values []string
+	for _, secret := range secrets {
+		if secret != "" {
+			values = append(values, secret)
+		}
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return len(values[i]) > len(values[j])
+	})
+
+	return func(value string) string {
+		for _, secret := range values {
+			value = strings.ReplaceAll(value, secret, masked)
+		}
+		return value
+	}
+}
+
+// tail returns the last size bytes of the output.
+func tail(output []byte, size int) string {
+	if len(output) <= size {
+		return string(output)
+	}
+
+	return "..." + string(output[len(output)-size:])
+}
+
+// tailLines returns the last lines of the output, the pseudo terminal line endings are normalized.
+func tailLines(output []byte, count int) string {
+	text := strings.TrimRight(strings.ReplaceAll(string(output), "\r\n", "\n"), "\n")
+	if text == "" {
+		return ""
+	}
+
+	lines := strings.Split(text, "\n")
+	if len(lines) > count {
+		lines = append([]string{"..."}, lines[len(lines)-count:]...)
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,102 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/sshtest"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testHost(server *sshtest.Server) *ssh_handler.SshConfig {
+	return ssh_handler.NewSshConfig(server.Host(), server.Port(), server.User, server.Password, "", "")
+}
+
+// testCommands runs the commands as root on the test server.
+type testCommands struct {
+	host *ssh_handler.SshConfig
+}
+
+func (c testCommands) Query(command string, env map[string]string) ([]byte, error) {
+	return RunAsRootWithEnv(context.Background(), c.host, env, command)
+}
+
+func (c testCommands) Run(command string, env map[string]string) ([]byte, error) {
+	return RunAsRootWithEnv(context.Background(), c.host, env, command)
+}
+
+func TestExecOutputTail(t *testing.T) {
+	var output strings.Builder
+	for line := 1; line <= 30; line++ {
+		fmt.Fprintf(&output, "line %d\n", line)
+	}
+
+	server := sshtest.NewServer(t)
+	server.Fail(`^failing$`, output.String(), 2)
+
+	_, err := Exec(context.Background(), testHost(server), Command{Command: "failing"})
+	if err == nil {
+		t.Fatal("expected the command to fail")
+	}
+
+	message := err.Error()
+	if !strings.Contains(message, "status 2") {
+		t.Errorf("expected the error to hold the exit status, got: %s", message)
+	}
+	if !strings.HasSuffix(message, "line 30") {
+		t.Errorf("expected the error to end with the output, got: %s", message)
+	}
+	if strings.Contains(message, "line 10\n") || !strings.Contains(message, "...\nline 11\n") {
+		t.Errorf("expected only the last %d lines of the output, got: %s", errorOutputLines, message)
+	}
+}
+
+func TestExecMasksSecrets(t *testing.T) {
+	server := sshtest.NewServer(t)
+	server.Fail(`^k3s`, "invalid token s3cr3t for "+server.Password+"\n", 1)
+
+	ctx := WithSecrets(context.Background(), "s3cr3t")
+	_, err := Exec(ctx, testHost(server), Command{Command: "k3s token"})
+	if err == nil {
+		t.Fatal("expected the command to fail")
+	}
+
+	if !strings.Contains(err.Error(), "invalid token *** for ***") {
+		t.Errorf("expected the secrets and the password to be masked, got: %s", err)
+	}
+}
+
+func TestExecSensitiveOutput(t *testing.T) {
+	server := sshtest.NewServer(t)
+	response := server.Fail(`^cat`, "failed to read the rest\n", 1)
+	response.Stdout = "client-key-data: secret\n"
+
+	_, err := Exec(WithSensitiveOutput(context.Background()), testHost(server), Command{Command: "cat kubeconfig"})
+	if err == nil {
+		t.Fatal("expected the command to fail")
+	}
+
+	if strings.Contains(err.Error(), "client-key-data") || !strings.Contains(err.Error(), "failed to read the rest") {
+		t.Errorf("expected only the standard error in the error, got: %s", err)
+	}
+}
+
+func TestExecInterruptedByContext(t *testing.T) {
+	server := sshtest.NewServer(t)
+	server.Respond(`^k3s --version$`, "k3s version v1.30.2+k3s2 (faa4574e)\n").Delay = 2 * time.Second
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := Exec(ctx, testHost(server), Command{Command: "k3s --version"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the command to be interrupted, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the command to be interrupted at the deadline, it took %s", elapsed)
+	}
+}
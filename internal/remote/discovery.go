@@ -0,0 +1,246 @@
+package remote
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/kubeconfig"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// NodeRole describes how K3S is installed on a host.
+type NodeRole string
+
+const (
+	NodeRoleNone   NodeRole = "none"
+	NodeRoleServer NodeRole = "server"
+	NodeRoleAgent  NodeRole = "agent"
+)
+
+const (
+	k3sServerServiceFile = "/etc/systemd/system/k3s.service"
+	k3sAgentServiceFile  = "/etc/systemd/system/k3s-agent.service"
+	K3sKubeconfigPath    = "/etc/rancher/k3s/k3s.yaml"
+)
+
+// DefaultReadTimeout bounds the inspection of a host while refreshing a resource, so that an unreachable node fails
+// the refresh instead of hanging it.
+const DefaultReadTimeout = 2 * time.Minute
+
+// NewReadTimeout parses the read_timeout setting, an empty setting selects its default.
+func NewReadTimeout(timeout string) (time.Duration, error) {
+	if timeout == "" {
+		return DefaultReadTimeout, nil
+	}
+
+	parsed, err := time.ParseDuration(timeout)
+	if err != nil || parsed < time.Second {
+		return DefaultReadTimeout, fmt.Errorf("read_timeout must be a duration of at least 1s, e.g. 2m, got: %q", timeout)
+	}
+
+	return parsed, nil
+}
+
+// NodeInfo holds the K3S installation details discovered on a host.
+type NodeInfo struct {
+	Role NodeRole
+
+	// Version is the installed K3S version, e.g. v1.30.2+k3s2.
+	Version string
+	// ServerAddress is the address of the cluster the node is registered in.
+	ServerAddress string
+	// Token is the K3S_TOKEN the node was installed with.
+	Token string
+	// Options are the flags passed to the K3S service, grouped with their values.
+	Options []string
+	// Kubeconfig is only set for server nodes.
+	Kubeconfig []byte
+}
+
+// DiscoverNode connects to the host and inspects the K3S installation created by the install script.
+func DiscoverNode(ctx context.Context, config *ssh_handler.SshConfig) (*NodeInfo, error) {
+	roleOutput, err := Run(ctx, config, fmt.Sprintf(
+		"if [ -f %s ]; then echo %s; elif [ -f %s ]; then echo %s; else echo %s; fi",
+		k3sServerServiceFile, NodeRoleServer,
+		k3sAgentServiceFile, NodeRoleAgent,
+		NodeRoleNone,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect k3s installation: %w", err)
+	}
+
+	info := &NodeInfo{
+		Role: NodeRole(strings.TrimSpace(string(roleOutput))),
+	}
+	if info.Role == NodeRoleNone {
+		return info, nil
+	}
+
+	serviceFile := k3sServerServiceFile
+	if info.Role == NodeRoleAgent {
+		serviceFile = k3sAgentServiceFile
+	}
+
+	versionOutput, err := Run(ctx, config, "k3s --version")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read k3s version: %w", err)
+	}
+	info.Version = ParseK3sVersion(string(versionOutput))
+
+	serviceOutput, err := Run(ctx, config, "cat "+serviceFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", serviceFile, err)
+	}
+	args := ParseExecStart(string(serviceOutput))
+
+	envOutput, err := RunAsRoot(ctx, config, "cat "+serviceFile+".env")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s.env: %w", serviceFile, err)
+	}
+	env := ParseEnvFile(string(envOutput))
+	info.Token = env["K3S_TOKEN"]
+
+	if info.Role == NodeRoleAgent {
+		info.Options = GroupOptions(args)
+		if serverUrl, err := url.Parse(env["K3S_URL"]); err == nil {
+			info.ServerAddress = serverUrl.Hostname()
+		}
+		return info, nil
+	}
+
+	info.ServerAddress, info.Options = extractTlsSan(GroupOptions(args))
+
+	info.Kubeconfig, err = ReadKubeconfig(ctx, config, info.ServerAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// ReadKubeconfig returns the kubeconfig of a server node, pointing to the server address when it is set.
+func ReadKubeconfig(ctx context.Context, config *ssh_handler.SshConfig, serverAddress string) ([]byte, error) {
+	kubeconfigOutput, err := Run(WithSensitiveOutput(ctx), config, "cat "+K3sKubeconfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", K3sKubeconfigPath, err)
+	}
+	if serverAddress == "" {
+		return kubeconfigOutput, nil
+	}
+
+	updatedKubeconfig, err := kubeconfig.UpdateServerAddress(&kubeconfigOutput, serverAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	return *updatedKubeconfig, nil
+}
+
+// ParseK3sVersion extracts the version from the output of `k3s --version`.
+func ParseK3sVersion(output string) string {
+	for _, field := range strings.Fields(output) {
+		if strings.HasPrefix(field, "v") && strings.Contains(field, "+k3s") {
+			return field
+		}
+	}
+	return ""
+}
+
+// ParseExecStart returns the arguments of the K3S service after the binary and the role,
+// unquoting the values written by the install script.
+func ParseExecStart(unit string) []string {
+	index := strings.Index(unit, "ExecStart=")
+	if index < 0 {
+		return nil
+	}
+
+	var command strings.Builder
+	scanner := bufio.NewScanner(strings.NewReader(unit[index+len("ExecStart="):]))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		continued := strings.HasSuffix(line, "\\")
+		command.WriteString(strings.TrimSuffix(line, "\\"))
+		command.WriteString(" ")
+		if !continued {
+			break
+		}
+	}
+
+	args := splitShellWords(command.String())
+	if len(args) < 2 {
+		return nil
+	}
+	return args[2:]
+}
+
+// ParseEnvFile parses the KEY='value' lines written by the install script.
+func ParseEnvFile(content string) map[string]string {
+	env := make(map[string]string)
+	for _, line := range strings.Split(content, "\n") {
+		key, value, found := strings.Cut(strings.TrimSpace(line), "=")
+		if !found || key == "" {
+			continue
+		}
+		env[key] = strings.Trim(value, `'"`)
+	}
+	return env
+}
+
+// GroupOptions joins each flag with the values that follow it, e.g.
+// ["--disable", "traefik"] becomes ["--disable traefik"].
+func GroupOptions(args []string) []string {
+	var options []string
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") || len(options) == 0 {
+			options = append(options, arg)
+			continue
+		}
+		options[len(options)-1] += " " + arg
+	}
+	return options
+}
+
+// extractTlsSan removes the first --tls-san option, which is added to every master node
+// with the cluster address, and returns its value separately.
+func extractTlsSan(options []string) (string, []string) {
+	for index, option := range options {
+		flag, value, _ := strings.Cut(option, " ")
+		if flag != "--tls-san" {
+			continue
+		}
+		remaining := append([]string{}, options[:index]...)
+		return value, append(remaining, options[index+1:]...)
+	}
+	return "", options
+}
+
+func splitShellWords(command string) []string {
+	var words []string
+	var current strings.Builder
+	inWord, inQuote := false, false
+
+	for _, char := range command {
+		switch {
+		case char == '\'':
+			inQuote = !inQuote
+			inWord = true
+		case !inQuote && (char == ' ' || char == '\t'):
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(char)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, current.String())
+	}
+
+	return words
+}
@@ -0,0 +1,210 @@
+package remote
+
+import (
+	"reflect"
+	"testing"
+)
+
+// serverUnit is the k3s.service unit written by the install script for
+// `sh -s - --tls-san 10.0.0.1 server --disable traefik --node-label 'role=control plane'`.
+const serverUnit = `[Unit]
+Description=Lightweight Kubernetes
+Documentation=https://k3s.io
+Wants=network-online.target
+After=network-online.target
+
+[Install]
+WantedBy=multi-user.target
+
+[Service]
+Type=notify
+EnvironmentFile=-/etc/default/%N
+EnvironmentFile=-/etc/sysconfig/%N
+EnvironmentFile=-/etc/systemd/system/k3s.service.env
+KillMode=process
+Delegate=yes
+# Having non-zero Limit*s causes performance problems due to accounting overhead
+# in the kernel. We recommend using cgroups to do container-local accounting.
+LimitNOFILE=1048576
+LimitNPROC=infinity
+LimitCORE=infinity
+TasksMax=infinity
+TimeoutStartSec=0
+Restart=always
+RestartSec=5s
+ExecStartPre=/bin/sh -xc '! /usr/bin/systemctl is-enabled --quiet nm-cloud-setup.service 2>/dev/null'
+ExecStartPre=-/sbin/modprobe br_netfilter
+ExecStartPre=-/sbin/modprobe overlay
+ExecStart=/usr/local/bin/k3s \
+    server \
+	'--tls-san' \
+	'10.0.0.1' \
+	'--disable' \
+	'traefik' \
+	'--node-label' \
+	'role=control plane' \
+
+`
+
+// agentUnit is the k3s-agent.service unit written by the install script for `sh -s - agent`.
+const agentUnit = `[Service]
+Type=notify
+EnvironmentFile=-/etc/systemd/system/k3s-agent.service.env
+ExecStartPre=-/sbin/modprobe br_netfilter
+ExecStart=/usr/local/bin/k3s \
+    agent \
+
+`
+
+func TestParseK3sVersion(t *testing.T) {
+	for name, test := range map[string]struct {
+		output   string
+		expected string
+	}{
+		"release": {
+			output:   "k3s version v1.30.2+k3s2 (faa4574e)\ngo version go1.22.4\n",
+			expected: "v1.30.2+k3s2",
+		},
+		"release candidate": {
+			output:   "k3s version v1.31.0-rc1+k3s1 (34be6d96)\ngo version go1.22.5\n",
+			expected: "v1.31.0-rc1+k3s1",
+		},
+		"not installed": {
+			output:   "sh: 1: k3s: not found\n",
+			expected: "",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if version := ParseK3sVersion(test.output); version != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, version)
+			}
+		})
+	}
+}
+
+func TestParseExecStart(t *testing.T) {
+	for name, test := range map[string]struct {
+		unit     string
+		expected []string
+	}{
+		"server": {
+			unit:     serverUnit,
+			expected: []string{"--tls-san", "10.0.0.1", "--disable", "traefik", "--node-label", "role=control plane"},
+		},
+		"agent without options": {
+			unit:     agentUnit,
+			expected: []string{},
+		},
+		"single line": {
+			unit:     "[Service]\nExecStart=/usr/local/bin/k3s server '--cluster-init'\n",
+			expected: []string{"--cluster-init"},
+		},
+		"no exec start": {
+			unit:     "[Unit]\nDescription=Lightweight Kubernetes\n",
+			expected: nil,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if args := ParseExecStart(test.unit); !reflect.DeepEqual(args, test.expected) {
+				t.Errorf("expected %q, got %q", test.expected, args)
+			}
+		})
+	}
+}
+
+func TestParseEnvFile(t *testing.T) {
+	for name, test := range map[string]struct {
+		content  string
+		expected map[string]string
+	}{
+		"server": {
+			content: "K3S_TOKEN='K10c0ffee::server:secret'\n",
+			expected: map[string]string{
+				"K3S_TOKEN": "K10c0ffee::server:secret",
+			},
+		},
+		"agent with proxy": {
+			content: "K3S_TOKEN='secret'\nK3S_URL='https://10.0.0.1:6443'\nHTTPS_PROXY=\"http://proxy:3128\"\n",
+			expected: map[string]string{
+				"K3S_TOKEN":   "secret",
+				"K3S_URL":     "https://10.0.0.1:6443",
+				"HTTPS_PROXY": "http://proxy:3128",
+			},
+		},
+		"blank lines": {
+			content:  "\n  \nNO_PROXY=\n",
+			expected: map[string]string{"NO_PROXY": ""},
+		},
+		"empty": {
+			content:  "",
+			expected: map[string]string{},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if env := ParseEnvFile(test.content); !reflect.DeepEqual(env, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, env)
+			}
+		})
+	}
+}
+
+func TestGroupOptions(t *testing.T) {
+	for name, test := range map[string]struct {
+		args     []string
+		expected []string
+	}{
+		"flags with values": {
+			args:     []string{"--disable", "traefik", "--node-label", "role=control plane", "--cluster-init"},
+			expected: []string{"--disable traefik", "--node-label role=control plane", "--cluster-init"},
+		},
+		"flags with equal signs": {
+			args:     []string{"--disable=traefik", "--write-kubeconfig-mode=644"},
+			expected: []string{"--disable=traefik", "--write-kubeconfig-mode=644"},
+		},
+		"leading value": {
+			args:     []string{"traefik", "--cluster-init"},
+			expected: []string{"traefik", "--cluster-init"},
+		},
+		"empty": {
+			args:     nil,
+			expected: nil,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if options := GroupOptions(test.args); !reflect.DeepEqual(options, test.expected) {
+				t.Errorf("expected %q, got %q", test.expected, options)
+			}
+		})
+	}
+}
+
+func TestExtractTlsSan(t *testing.T) {
+	for name, test := range map[string]struct {
+		options         []string
+		expectedAddress string
+		expectedOptions []string
+	}{
+		"first option": {
+			options:         GroupOptions(ParseExecStart(serverUnit)),
+			expectedAddress: "10.0.0.1",
+			expectedOptions: []string{"--disable traefik", "--node-label role=control plane"},
+		},
+		"only the first one": {
+			options:         []string{"--cluster-init", "--tls-san 10.0.0.1", "--tls-san k3s.example.com"},
+			expectedAddress: "10.0.0.1",
+			expectedOptions: []string{"--cluster-init", "--tls-san k3s.example.com"},
+		},
+		"missing": {
+			options:         []string{"--cluster-init"},
+			expectedAddress: "",
+			expectedOptions: []string{"--cluster-init"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			address, options := extractTlsSan(test.options)
+			if address != test.expectedAddress || !reflect.DeepEqual(options, test.expectedOptions) {
+				t.Errorf("expected %q and %q, got %q and %q", test.expectedAddress, test.expectedOptions, address, options)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package remote
+
+import (
+	"fmt"
+	"strings"
+)
+
+// K3sServerDatastorePath is the directory holding the embedded etcd datastore of a server node.
+const K3sServerDatastorePath = "/var/lib/rancher/k3s/server/db"
+
+// ResetClusterFromSnapshot resets the embedded etcd datastore of a stopped server node to a single member
+// restored from the snapshot. The token must be the one of the cluster from which the snapshot was taken.
+func ResetClusterFromSnapshot(commands CommandRunner, snapshotPath string, token string, s3 *EtcdS3Options) error {
+	args := []string{
+		"--cluster-reset",
+		"--cluster-reset-restore-path=" + ShellQuote(snapshotPath),
+	}
+	args = append(args, s3.Args()...)
+
+	env := s3.Env()
+	env["K3S_TOKEN"] = token
+
+	if _, err := commands.Run("k3s server "+strings.Join(args, " "), env); err != nil {
+		return fmt.Errorf("failed to restore etcd snapshot %s: %w", snapshotPath, err)
+	}
+
+	return nil
+}
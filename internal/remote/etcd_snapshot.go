@@ -0,0 +1,189 @@
+package remote
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EtcdS3Options describes an S3 compatible storage used for etcd snapshots.
+type EtcdS3Options struct {
+	Endpoint      string
+	Bucket        string
+	Folder        string
+	Region        string
+	AccessKey     string
+	SecretKey     string
+	Insecure      bool
+	SkipSslVerify bool
+}
+
+// Args returns the k3s etcd-snapshot flags selecting the S3 storage. The credentials are left out, they are
+// passed through the environment by Env.
+func (o *EtcdS3Options) Args() []string {
+	if o == nil {
+		return nil
+	}
+
+	args := []string{"--etcd-s3"}
+	for flag, value := range map[string]string{
+		"--etcd-s3-endpoint": o.Endpoint,
+		"--etcd-s3-bucket":   o.Bucket,
+		"--etcd-s3-folder":   o.Folder,
+		"--etcd-s3-region":   o.Region,
+	} {
+		if value != "" {
+			args = append(args, flag+"="+ShellQuote(value))
+		}
+	}
+	if o.Insecure {
+		args = append(args, "--etcd-s3-insecure")
+	}
+	if o.SkipSslVerify {
+		args = append(args, "--etcd-s3-skip-ssl-verify")
+	}
+
+	sort.Strings(args[1:])
+	return args
+}
+
+// Env returns the environment variables from which k3s reads the S3 credentials, which would be visible to every
+// user of the host on the command line.
+func (o *EtcdS3Options) Env() map[string]string {
+	env := make(map[string]string)
+	if o == nil {
+		return env
+	}
+
+	if o.AccessKey != "" {
+		env["AWS_ACCESS_KEY_ID"] = o.AccessKey
+	}
+	if o.SecretKey != "" {
+		env["AWS_SECRET_ACCESS_KEY"] = o.SecretKey
+	}
+
+	return env
+}
+
+// EtcdSnapshot is an entry of `k3s etcd-snapshot ls`.
+type EtcdSnapshot struct {
+	Name     string
+	Location string
+	Size     int64
+	Created  string
+}
+
+// SaveEtcdSnapshot takes an on-demand snapshot and returns it. K3S appends the node name and a
+// timestamp to the requested name, so the snapshot is identified by comparing the listings.
+func SaveEtcdSnapshot(commands CommandRunner, name string, compress bool, s3 *EtcdS3Options) (*EtcdSnapshot, error) {
+	before, err := ListEtcdSnapshots(commands, s3)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"--name=" + ShellQuote(name)}
+	if compress {
+		args = append(args, "--etcd-snapshot-compress")
+	}
+	args = append(args, s3.Args()...)
+
+	if _, err := commands.Run("k3s etcd-snapshot save "+strings.Join(args, " "), s3.Env()); err != nil {
+		return nil, fmt.Errorf("failed to save etcd snapshot: %w", err)
+	}
+
+	after, err := ListEtcdSnapshots(commands, s3)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]bool, len(before))
+	for _, snapshot := range before {
+		existing[snapshot.Name] = true
+	}
+
+	var created *EtcdSnapshot
+	for index, snapshot := range after {
+		if existing[snapshot.Name] || !strings.HasPrefix(snapshot.Name, name+"-") {
+			continue
+		}
+		// Prefer the S3 entry when the snapshot is also kept locally.
+		if created == nil || strings.HasPrefix(snapshot.Location, "s3://") {
+			created = &after[index]
+		}
+	}
+	if created == nil {
+		return nil, fmt.Errorf("etcd snapshot %q was not found after being saved", name)
+	}
+
+	return created, nil
+}
+
+// FindEtcdSnapshot returns the snapshot with the given name, or nil when it no longer exists.
+func FindEtcdSnapshot(commands CommandRunner, name string, s3 *EtcdS3Options) (*EtcdSnapshot, error) {
+	snapshots, err := ListEtcdSnapshots(commands, s3)
+	if err != nil {
+		return nil, err
+	}
+
+	var found *EtcdSnapshot
+	for index, snapshot := range snapshots {
+		if snapshot.Name != name {
+			continue
+		}
+		if found == nil || strings.HasPrefix(snapshot.Location, "s3://") {
+			found = &snapshots[index]
+		}
+	}
+
+	return found, nil
+}
+
+func ListEtcdSnapshots(commands CommandRunner, s3 *EtcdS3Options) ([]EtcdSnapshot, error) {
+	output, err := commands.Query(strings.TrimSpace("k3s etcd-snapshot ls "+strings.Join(s3.Args(), " ")), s3.Env())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list etcd snapshots: %w", err)
+	}
+
+	return ParseEtcdSnapshotList(string(output)), nil
+}
+
+// DeleteEtcdSnapshot removes the snapshot from the local snapshot directory and, when configured, from S3.
+func DeleteEtcdSnapshot(commands CommandRunner, name string, s3 *EtcdS3Options) error {
+	args := append(s3.Args(), ShellQuote(name))
+
+	if _, err := commands.Run("k3s etcd-snapshot delete "+strings.Join(args, " "), s3.Env()); err != nil {
+		return fmt.Errorf("failed to delete etcd snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// ParseEtcdSnapshotList parses the table printed by `k3s etcd-snapshot ls`:
+//
+//	Name                    Location                                                  Size    Created
+//	on-demand-node-1718000  file:///var/lib/rancher/k3s/server/db/snapshots/on-dem...  4390944 2024-06-10T07:00:00Z
+func ParseEtcdSnapshotList(output string) []EtcdSnapshot {
+	var snapshots []EtcdSnapshot
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 4 || fields[0] == "Name" {
+			continue
+		}
+
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		snapshots = append(snapshots, EtcdSnapshot{
+			Name:     fields[0],
+			Location: fields[1],
+			Size:     size,
+			Created:  fields[3],
+		})
+	}
+
+	return snapshots
+}
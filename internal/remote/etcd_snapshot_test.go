@@ -0,0 +1,110 @@
+package remote
+
+import (
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/sshtest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseEtcdSnapshotList(t *testing.T) {
+	for name, test := range map[string]struct {
+		output   string
+		expected []EtcdSnapshot
+	}{
+		"local and s3": {
+			output: "Name                               Location                                                                             Size    Created\n" +
+				"on-demand-master-1718006400        file:///var/lib/rancher/k3s/server/db/snapshots/on-demand-master-1718006400          4390944 2024-06-10T08:00:00Z\n" +
+				"on-demand-master-1718006400        s3://k3s-backups/cluster/on-demand-master-1718006400                                 4390944 2024-06-10T08:00:00Z\n",
+			expected: []EtcdSnapshot{
+				{
+					Name:     "on-demand-master-1718006400",
+					Location: "file:///var/lib/rancher/k3s/server/db/snapshots/on-demand-master-1718006400",
+					Size:     4390944,
+					Created:  "2024-06-10T08:00:00Z",
+				},
+				{
+					Name:     "on-demand-master-1718006400",
+					Location: "s3://k3s-backups/cluster/on-demand-master-1718006400",
+					Size:     4390944,
+					Created:  "2024-06-10T08:00:00Z",
+				},
+			},
+		},
+		"compressed with log lines": {
+			output: "time=\"2024-06-10T08:00:01Z\" level=info msg=\"Checking if S3 bucket k3s-backups exists\"\n" +
+				"Name                                     Location                                                                                  Size   Created\n" +
+				"etcd-snapshot-master-1718006400.zip      file:///var/lib/rancher/k3s/server/db/snapshots/etcd-snapshot-master-1718006400.zip       983040 2024-06-10T08:00:00Z\n",
+			expected: []EtcdSnapshot{
+				{
+					Name:     "etcd-snapshot-master-1718006400.zip",
+					Location: "file:///var/lib/rancher/k3s/server/db/snapshots/etcd-snapshot-master-1718006400.zip",
+					Size:     983040,
+					Created:  "2024-06-10T08:00:00Z",
+				},
+			},
+		},
+		"crlf line endings": {
+			output: "Name Location Size Created\r\n" +
+				"on-demand-master-1 file:///var/lib/rancher/k3s/server/db/snapshots/on-demand-master-1 42 2024-06-10T08:00:00Z\r\n",
+			expected: []EtcdSnapshot{
+				{
+					Name:     "on-demand-master-1",
+					Location: "file:///var/lib/rancher/k3s/server/db/snapshots/on-demand-master-1",
+					Size:     42,
+					Created:  "2024-06-10T08:00:00Z",
+				},
+			},
+		},
+		"invalid size": {
+			output:   "Name Location Size Created\non-demand-master-1 file:///snapshots/on-demand-master-1 unknown 2024-06-10T08:00:00Z\n",
+			expected: nil,
+		},
+		"no snapshots": {
+			output:   "Name Location Size Created\n",
+			expected: nil,
+		},
+		"empty": {
+			output:   "",
+			expected: nil,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if snapshots := ParseEtcdSnapshotList(test.output); !reflect.DeepEqual(snapshots, test.expected) {
+				t.Errorf("expected %+v, got %+v", test.expected, snapshots)
+			}
+		})
+	}
+}
+
+func TestEtcdSnapshotS3Credentials(t *testing.T) {
+	server := sshtest.NewServer(t)
+	host := testHost(server)
+	s3 := &EtcdS3Options{
+		Endpoint:  "s3.example.com",
+		Bucket:    "k3s-backups",
+		AccessKey: "access-key-id",
+		SecretKey: "secret-access-key",
+	}
+
+	if _, err := ListEtcdSnapshots(testCommands{host: host}, s3); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, command := range server.Commands() {
+		if strings.Contains(command.Command, s3.AccessKey) || strings.Contains(command.Command, s3.SecretKey) {
+			t.Errorf("expected the S3 credentials to be left out of the command line, got: %s", command.Command)
+		}
+	}
+
+	uploads := server.Received(`^umask 077 && set -C && cat > `)
+	if len(uploads) != 1 || uploads[0].Stdin != "AWS_ACCESS_KEY_ID='access-key-id'\nAWS_SECRET_ACCESS_KEY='secret-access-key'\n" {
+		t.Errorf("expected the S3 credentials to be uploaded as an environment file, got: %v", uploads)
+	}
+	if received := server.Received(`install -m 600 .*'/run/yoshik3s/env-[0-9a-f]+'`); len(received) != 1 {
+		t.Errorf("expected the environment file to be only readable by root, got: %v", server.Commands())
+	}
+	if received := server.Received(`rm -f .*/run/yoshik3s/env-[0-9a-f]+.*exec k3s etcd-snapshot ls --etcd-s3 `); len(received) != 1 {
+		t.Errorf("expected k3s to run with the environment file, removed beforehand, got: %v", server.Commands())
+	}
+}
@@ -0,0 +1,148 @@
+package remote
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// FileWriter creates, replaces or removes files owned by root on a host.
+type FileWriter interface {
+	// WriteFile creates or replaces the file, a nil content removes the file instead.
+	WriteFile(filePath string, content []byte, mode os.FileMode) error
+}
+
+// WriteFileAsRoot creates or replaces a file owned by root. The content is streamed over the standard input of the
+// SSH user into a temporary file only this user can read, since the command line is limited in size and visible to
+// every user of the host. Root then installs the file with its mode next to its destination and renames it into
+// place, so that it is never readable by other users nor seen half written.
+func WriteFileAsRoot(ctx context.Context, config *ssh_handler.SshConfig, filePath string, content []byte, mode os.FileMode) error {
+	tempPath, err := uploadTempPath()
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", filePath, err)
+	}
+
+	// noclobber fails rather than writing through a file created beforehand at the same path.
+	if _, err := Exec(ctx, config, Command{
+		Command: "umask 077 && set -C && cat > " + ShellQuote(tempPath),
+		Stdin:   content,
+	}); err != nil {
+		return fmt.Errorf("failed to upload %s: %w", filePath, err)
+	}
+
+	stagedPath := path.Join(path.Dir(filePath), "."+path.Base(filePath)+".yoshik3s")
+	script := fmt.Sprintf(
+		"mkdir -p %s && umask 077 && install -m %o %s %s && mv -f %s %s; status=$?; rm -f %s; exit $status",
+		ShellQuote(path.Dir(filePath)),
+		mode.Perm(),
+		ShellQuote(tempPath),
+		ShellQuote(stagedPath),
+		ShellQuote(stagedPath),
+		ShellQuote(filePath),
+		ShellQuote(tempPath),
+	)
+
+	if _, err := RunAsRoot(ctx, config, "sh -c "+ShellQuote(script)); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filePath, err)
+	}
+
+	return nil
+}
+
+// uploadTempPath returns a random path in /tmp, to which a content is uploaded by the SSH user before root installs it.
+func uploadTempPath() (string, error) {
+	return randomPath("/tmp/.yoshik3s-upload-")
+}
+
+// randomPath appends a random suffix to the prefix, so that concurrent operations never share a temporary file.
+func randomPath(prefix string) (string, error) {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+
+	return prefix + hex.EncodeToString(suffix), nil
+}
+
+// RemoveFileAsRoot removes a file owned by root, it does not fail when the file does not exist.
+func RemoveFileAsRoot(ctx context.Context, config *ssh_handler.SshConfig, filePath string) error {
+	if _, err := RunAsRoot(ctx, config, "rm -f "+ShellQuote(filePath)); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", filePath, err)
+	}
+
+	return nil
+}
+
+// FileChecksumAsRoot returns the SHA-256 checksum of a file owned by root, or an empty string when it does not exist.
+func FileChecksumAsRoot(commands CommandRunner, filePath string) (string, error) {
+	script := fmt.Sprintf("if [ -e %s ]; then sha256sum %s; fi", ShellQuote(filePath), ShellQuote(filePath))
+
+	output, err := commands.Query("sh -c "+ShellQuote(script), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute the checksum of %s: %w", filePath, err)
+	}
+
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return "", nil
+	}
+
+	return fields[0], nil
+}
+
+// FileAttributes are the permissions and the ownership of a file, the owner being given both by name and by id.
+type FileAttributes struct {
+	Mode    uint32
+	User    string
+	Group   string
+	UserId  string
+	GroupId string
+}
+
+// FileAttributesAsRoot returns the permissions and the ownership of a file owned by root, or nil when it does not exist.
+func FileAttributesAsRoot(commands CommandRunner, filePath string) (*FileAttributes, error) {
+	script := fmt.Sprintf("if [ -e %s ]; then stat -c '%%a %%U %%G %%u %%g' %s; fi", ShellQuote(filePath), ShellQuote(filePath))
+
+	output, err := commands.Query("sh -c "+ShellQuote(script), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the attributes of %s: %w", filePath, err)
+	}
+
+	return parseFileAttributes(string(output))
+}
+
+func parseFileAttributes(output string) (*FileAttributes, error) {
+	fields := strings.Fields(output)
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("unexpected stat output: %q", strings.TrimSpace(output))
+	}
+
+	mode, err := strconv.ParseUint(fields[0], 8, 32)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected file mode %q: %w", fields[0], err)
+	}
+
+	return &FileAttributes{
+		Mode:    uint32(mode),
+		User:    fields[1],
+		Group:   fields[2],
+		UserId:  fields[3],
+		GroupId: fields[4],
+	}, nil
+}
+
+// Checksum returns the SHA-256 checksum of the content, as printed by sha256sum.
+func Checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
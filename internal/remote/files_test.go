@@ -0,0 +1,56 @@
+package remote
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFileAttributes(t *testing.T) {
+	for name, test := range map[string]struct {
+		output   string
+		expected *FileAttributes
+		err      bool
+	}{
+		"root file": {
+			output:   "600 root root 0 0\n",
+			expected: &FileAttributes{Mode: 0600, User: "root", Group: "root", UserId: "0", GroupId: "0"},
+		},
+		"special bits": {
+			output:   "4755 k3s adm 998 4\n",
+			expected: &FileAttributes{Mode: 04755, User: "k3s", Group: "adm", UserId: "998", GroupId: "4"},
+		},
+		"unknown owner": {
+			output:   "644 UNKNOWN UNKNOWN 1234 1234\n",
+			expected: &FileAttributes{Mode: 0644, User: "UNKNOWN", Group: "UNKNOWN", UserId: "1234", GroupId: "1234"},
+		},
+		"missing file": {
+			output:   "",
+			expected: nil,
+		},
+		"unexpected output": {
+			output: "stat: cannot statx '/etc/rancher/k3s/config.yaml': Permission denied\n",
+			err:    true,
+		},
+		"invalid mode": {
+			output: "rw-r--r-- root root 0 0\n",
+			err:    true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			attributes, err := parseFileAttributes(test.output)
+			if test.err {
+				if err == nil {
+					t.Errorf("expected an error, got %+v", attributes)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(attributes, test.expected) {
+				t.Errorf("expected %+v, got %+v", test.expected, attributes)
+			}
+		})
+	}
+}
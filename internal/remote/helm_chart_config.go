@@ -0,0 +1,51 @@
+package remote
+
+import (
+	"fmt"
+)
+
+// HelmChartConfig is the k3s custom resource overriding the values of a HelmChart, including the bundled ones.
+type HelmChartConfig struct {
+	ApiVersion string                  `yaml:"apiVersion"`
+	Kind       string                  `yaml:"kind"`
+	Metadata   HelmChartConfigMetadata `yaml:"metadata"`
+	Spec       HelmChartConfigSpec     `yaml:"spec"`
+}
+
+type HelmChartConfigMetadata struct {
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace"`
+}
+
+type HelmChartConfigSpec struct {
+	ValuesContent string `yaml:"valuesContent,omitempty"`
+}
+
+func NewHelmChartConfig(chartName string, namespace string, valuesContent string) HelmChartConfig {
+	return HelmChartConfig{
+		ApiVersion: "helm.cattle.io/v1",
+		Kind:       "HelmChartConfig",
+		Metadata: HelmChartConfigMetadata{
+			Name:      chartName,
+			Namespace: namespace,
+		},
+		Spec: HelmChartConfigSpec{
+			ValuesContent: valuesContent,
+		},
+	}
+}
+
+// ManifestName returns the name of the manifest in which the HelmChartConfig is deployed.
+func (c HelmChartConfig) ManifestName() string {
+	return fmt.Sprintf("helm-chart-config-%s-%s", c.Metadata.Namespace, c.Metadata.Name)
+}
+
+// Render returns the HelmChartConfig manifest.
+func (c HelmChartConfig) Render() ([]byte, error) {
+	content, err := MarshalYaml(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the HelmChartConfig %s: %w", c.Metadata.Name, err)
+	}
+
+	return content, nil
+}
@@ -0,0 +1,116 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"strings"
+)
+
+// JoinTokenOptions are the arguments given to `k3s token create`.
+type JoinTokenOptions struct {
+	Ttl         string
+	Description string
+	Usages      []string
+}
+
+// Args returns the command line arguments matching the options.
+func (o JoinTokenOptions) Args() []string {
+	var args []string
+
+	if o.Ttl != "" {
+		args = append(args, "--ttl", ShellQuote(o.Ttl))
+	}
+	if o.Description != "" {
+		args = append(args, "--description", ShellQuote(o.Description))
+	}
+	if len(o.Usages) > 0 {
+		args = append(args, "--usages", ShellQuote(strings.Join(o.Usages, ",")))
+	}
+
+	return args
+}
+
+// CreateJoinToken creates a bootstrap token on a server node and returns it, e.g. K10<ca-hash>::<id>.<secret>.
+func CreateJoinToken(ctx context.Context, config *ssh_handler.SshConfig, options JoinTokenOptions) (string, error) {
+	output, err := RunAsRoot(WithSensitiveOutput(ctx), config, strings.Join(append([]string{"k3s token create"}, options.Args()...), " "))
+	if err != nil {
+		return "", fmt.Errorf("failed to create a join token: %w", err)
+	}
+
+	token := ""
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			token = line
+		}
+	}
+	if ParseJoinTokenId(token) == "" {
+		return "", fmt.Errorf("unexpected output of k3s token create: %q", strings.TrimSpace(string(output)))
+	}
+
+	return token, nil
+}
+
+// ParseJoinTokenId returns the public id of a bootstrap token, with or without the K10<ca-hash>:: prefix.
+func ParseJoinTokenId(token string) string {
+	if _, bootstrapToken, found := strings.Cut(token, "::"); found {
+		token = bootstrapToken
+	}
+
+	id, _, found := strings.Cut(token, ".")
+	if !found {
+		return ""
+	}
+
+	return id
+}
+
+// JoinTokenExists reports whether the bootstrap token with the given id is still listed, expired tokens are not.
+func JoinTokenExists(ctx context.Context, config *ssh_handler.SshConfig, id string) (bool, error) {
+	output, err := RunAsRoot(ctx, config, "k3s token list")
+	if err != nil {
+		return false, fmt.Errorf("failed to list join tokens: %w", err)
+	}
+
+	for _, listed := range ParseJoinTokenList(string(output)) {
+		if listed == id {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// ParseJoinTokenList returns the ids of the tokens in the table printed by `k3s token list`:
+//
+//	TOKEN    TTL  EXPIRES               USAGES                  DESCRIPTION  EXTRA GROUPS
+//	abcdef   23h  2024-06-11T07:00:00Z  authentication,signing  workers      system:bootstrappers:k3s:default-node-token
+func ParseJoinTokenList(output string) []string {
+	var ids []string
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] == "TOKEN" {
+			continue
+		}
+
+		id, _, _ := strings.Cut(fields[0], ".")
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
+// DeleteJoinToken revokes the bootstrap token with the given id, a token that already expired is ignored.
+func DeleteJoinToken(ctx context.Context, config *ssh_handler.SshConfig, id string) error {
+	_, err := RunAsRoot(ctx, config, "k3s token delete "+ShellQuote(id))
+	if err == nil {
+		return nil
+	}
+
+	if exists, listErr := JoinTokenExists(ctx, config, id); listErr == nil && !exists {
+		return nil
+	}
+
+	return fmt.Errorf("failed to delete the join token: %w", err)
+}
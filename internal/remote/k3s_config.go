@@ -0,0 +1,139 @@
+package remote
+
+import (
+	"fmt"
+)
+
+// SecretsEncryptionConfigPath is the k3s configuration drop-in enabling the secrets encryption.
+const SecretsEncryptionConfigPath = "/etc/rancher/k3s/config.yaml.d/50-yoshik3s-secrets-encryption.yaml"
+
+// AgentTokenConfigPath is the k3s configuration drop-in setting the token used by the agents to join the cluster.
+const AgentTokenConfigPath = "/etc/rancher/k3s/config.yaml.d/50-yoshik3s-agent-token.yaml"
+
+// EtcdSnapshotsConfigPath is the k3s configuration drop-in holding the scheduled etcd snapshot settings.
+const EtcdSnapshotsConfigPath = "/etc/rancher/k3s/config.yaml.d/50-yoshik3s-etcd-snapshots.yaml"
+
+// EtcdSnapshotsConfig describes the scheduled etcd snapshots of a server node.
+type EtcdSnapshotsConfig struct {
+	ScheduleCron string
+	Retention    int64
+	Directory    string
+	Compress     bool
+	S3           *EtcdS3Options
+}
+
+// Render returns the k3s configuration file setting the scheduled etcd snapshots.
+func (c EtcdSnapshotsConfig) Render() ([]byte, error) {
+	config := map[string]interface{}{}
+
+	if c.ScheduleCron != "" {
+		config["etcd-snapshot-schedule-cron"] = c.ScheduleCron
+	}
+	if c.Retention > 0 {
+		config["etcd-snapshot-retention"] = c.Retention
+	}
+	if c.Directory != "" {
+		config["etcd-snapshot-dir"] = c.Directory
+	}
+	if c.Compress {
+		config["etcd-snapshot-compress"] = true
+	}
+	if c.S3 != nil {
+		config["etcd-s3"] = true
+		for key, value := range map[string]string{
+			"etcd-s3-endpoint":   c.S3.Endpoint,
+			"etcd-s3-bucket":     c.S3.Bucket,
+			"etcd-s3-folder":     c.S3.Folder,
+			"etcd-s3-region":     c.S3.Region,
+			"etcd-s3-access-key": c.S3.AccessKey,
+			"etcd-s3-secret-key": c.S3.SecretKey,
+		} {
+			if value != "" {
+				config[key] = value
+			}
+		}
+		if c.S3.Insecure {
+			config["etcd-s3-insecure"] = true
+		}
+		if c.S3.SkipSslVerify {
+			config["etcd-s3-skip-ssl-verify"] = true
+		}
+	}
+
+	return renderConfig(config)
+}
+
+// WriteEtcdSnapshotsConfig writes the scheduled etcd snapshots configuration, which k3s reads on its next start.
+// A nil config removes a previously written configuration.
+func WriteEtcdSnapshotsConfig(files FileWriter, snapshots *EtcdSnapshotsConfig) error {
+	if snapshots == nil {
+		return files.WriteFile(EtcdSnapshotsConfigPath, nil, 0)
+	}
+
+	content, err := snapshots.Render()
+	if err != nil {
+		return err
+	}
+
+	// The file may hold the S3 credentials.
+	return files.WriteFile(EtcdSnapshotsConfigPath, content, 0600)
+}
+
+// SecretsEncryptionConfig describes the encryption at rest of the secrets stored by a server node.
+type SecretsEncryptionConfig struct {
+	Provider string
+}
+
+// Render returns the k3s configuration file enabling the secrets encryption.
+func (c SecretsEncryptionConfig) Render() ([]byte, error) {
+	config := map[string]interface{}{
+		"secrets-encryption": true,
+	}
+
+	if c.Provider != "" {
+		config["secrets-encryption-provider"] = c.Provider
+	}
+
+	return renderConfig(config)
+}
+
+// WriteSecretsEncryptionConfig writes the secrets encryption configuration, which k3s reads on its next start.
+// A nil config removes a previously written configuration.
+func WriteSecretsEncryptionConfig(files FileWriter, encryption *SecretsEncryptionConfig) error {
+	if encryption == nil {
+		return files.WriteFile(SecretsEncryptionConfigPath, nil, 0)
+	}
+
+	content, err := encryption.Render()
+	if err != nil {
+		return err
+	}
+
+	return files.WriteFile(SecretsEncryptionConfigPath, content, 0600)
+}
+
+// WriteAgentTokenConfig writes the agent token configuration of a server node, which k3s reads on its next start.
+// An empty token removes a previously written configuration.
+func WriteAgentTokenConfig(files FileWriter, agentToken string) error {
+	if agentToken == "" {
+		return files.WriteFile(AgentTokenConfigPath, nil, 0)
+	}
+
+	content, err := renderConfig(map[string]interface{}{
+		"agent-token": agentToken,
+	})
+	if err != nil {
+		return err
+	}
+
+	return files.WriteFile(AgentTokenConfigPath, content, 0600)
+}
+
+func renderConfig(config map[string]interface{}) ([]byte, error) {
+	content, err := MarshalYaml(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the k3s configuration: %w", err)
+	}
+
+	return append([]byte("# Managed by terraform-provider-yoshik3s.\n"), content...), nil
+}
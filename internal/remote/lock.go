@@ -0,0 +1,144 @@
+package remote
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/crypto/ssh"
+	"net"
+	"strings"
+	"time"
+)
+
+// Defaults of the remote_lock settings.
+const (
+	DefaultLockPath    = "/var/lock/yoshik3s.lock"
+	DefaultLockTimeout = 10 * time.Minute
+)
+
+// lockAcquiredMarker is printed once flock holds the lock.
+const lockAcquiredMarker = "yoshik3s-lock-acquired"
+
+// LockOptions describes the lock file taken on the hosts, guarding them against concurrent Terraform runs.
+type LockOptions struct {
+	Path    string
+	Timeout time.Duration
+}
+
+// NewLockOptions parses the remote_lock settings, an empty setting selects its default.
+func NewLockOptions(path string, timeout string) (LockOptions, error) {
+	options := LockOptions{
+		Path:    DefaultLockPath,
+		Timeout: DefaultLockTimeout,
+	}
+
+	if path != "" {
+		if !strings.HasPrefix(path, "/") {
+			return options, fmt.Errorf("path must be absolute, got: %q", path)
+		}
+		options.Path = path
+	}
+	if timeout != "" {
+		parsed, err := time.ParseDuration(timeout)
+		if err != nil || parsed < time.Second {
+			return options, fmt.Errorf("timeout must be a duration of at least 1s, e.g. 10m, got: %q", timeout)
+		}
+		options.Timeout = parsed
+	}
+
+	return options, nil
+}
+
+// AcquireLock takes an exclusive flock on the lock file of the host, waiting at most for the timeout of the options.
+// The lock is held by an SSH session until the returned function is called, or until the connection drops, so that
+// a crashed run never leaves the host locked.
+func AcquireLock(ctx context.Context, config *ssh_handler.SshConfig, options LockOptions) (func(), error) {
+	address := net.JoinHostPort(config.GetHost(), config.GetPort())
+	start := time.Now()
+
+	session, release, err := newSession(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock %s on %s: %w", options.Path, address, err)
+	}
+
+	stdin, stdinErr := session.StdinPipe()
+	stdout, stdoutErr := session.StdoutPipe()
+	if err := errors.Join(stdinErr, stdoutErr); err != nil {
+		release()
+		return nil, fmt.Errorf("failed to lock %s on %s: %w", options.Path, address, err)
+	}
+	var stderr bytes.Buffer
+	session.Stderr = &stderr
+
+	// The lock is held until cat reads the end of the standard input, i.e. until the session is closed.
+	become := BecomeFromContext(ctx, config)
+	command := become.Command(config, fmt.Sprintf(
+		"flock -w %d %s -c %s",
+		int(options.Timeout.Seconds()),
+		ShellQuote(options.Path),
+		ShellQuote("echo "+lockAcquiredMarker+"; exec cat > /dev/null"),
+	))
+	if err := session.Start(command); err != nil {
+		release()
+		return nil, fmt.Errorf("failed to lock %s on %s: %w", options.Path, address, err)
+	}
+	if _, err := stdin.Write(become.Stdin(config)); err != nil {
+		release()
+		return nil, fmt.Errorf("failed to lock %s on %s: %w", options.Path, address, err)
+	}
+
+	acquired := make(chan error, 1)
+	go func() {
+		line, err := bufio.NewReader(stdout).ReadString('\n')
+		if strings.TrimSpace(line) == lockAcquiredMarker {
+			acquired <- nil
+			return
+		}
+		acquired <- errors.Join(err, session.Wait())
+	}()
+
+	select {
+	case err = <-acquired:
+	case <-ctx.Done():
+		release()
+		return nil, fmt.Errorf("failed to lock %s on %s: %w", options.Path, address, ctx.Err())
+	}
+
+	fields := map[string]interface{}{
+		"host":     address,
+		"path":     options.Path,
+		"duration": time.Since(start).String(),
+	}
+	if err != nil {
+		release()
+
+		var exitError *ssh.ExitError
+		if errors.As(err, &exitError) && exitError.ExitStatus() == 1 && stderr.Len() == 0 {
+			err = fmt.Errorf("timed out after %s, another Terraform run may be changing the host", options.Timeout)
+		} else if stderr.Len() > 0 {
+			err = fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+
+		fields["error"] = err.Error()
+		logRemote(ctx, tflog.SubsystemWarn, tflog.Warn, "failed to acquire the remote lock", fields)
+
+		return nil, fmt.Errorf("failed to lock %s on %s: %w", options.Path, address, err)
+	}
+	logRemote(ctx, tflog.SubsystemDebug, tflog.Debug, "acquired the remote lock", fields)
+
+	return func() {
+		// Closing the standard input ends cat, releasing the lock.
+		stdin.Close()
+		_ = session.Wait()
+		release()
+
+		logRemote(ctx, tflog.SubsystemDebug, tflog.Debug, "released the remote lock", map[string]interface{}{
+			"host": address,
+			"path": options.Path,
+		})
+	}, nil
+}
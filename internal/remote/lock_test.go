@@ -0,0 +1,44 @@
+package remote
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewLockOptions(t *testing.T) {
+	for name, test := range map[string]struct {
+		path     string
+		timeout  string
+		expected LockOptions
+		err      string
+	}{
+		"defaults": {
+			expected: LockOptions{Path: DefaultLockPath, Timeout: DefaultLockTimeout},
+		},
+		"custom": {
+			path:     "/run/k3s.lock",
+			timeout:  "30s",
+			expected: LockOptions{Path: "/run/k3s.lock", Timeout: 30 * time.Second},
+		},
+		"relative path":     {path: "k3s.lock", err: "absolute"},
+		"invalid timeout":   {timeout: "later", err: "timeout"},
+		"timeout too short": {timeout: "500ms", err: "at least 1s"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			options, err := NewLockOptions(test.path, test.timeout)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected an error about %s, got: %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if options != test.expected {
+				t.Errorf("expected %+v, got %+v", test.expected, options)
+			}
+		})
+	}
+}
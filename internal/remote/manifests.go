@@ -0,0 +1,30 @@
+package remote
+
+import (
+	"context"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"path"
+)
+
+// K3sManifestsPath is the directory whose manifests are applied automatically by the k3s deploy controller.
+const K3sManifestsPath = "/var/lib/rancher/k3s/server/manifests"
+
+// ManifestPath returns the path of the auto-deploying manifest with the given name.
+func ManifestPath(name string) string {
+	return path.Join(K3sManifestsPath, name+".yaml")
+}
+
+// WriteManifest creates or replaces an auto-deploying manifest.
+func WriteManifest(ctx context.Context, config *ssh_handler.SshConfig, name string, content []byte) error {
+	return WriteFileAsRoot(ctx, config, ManifestPath(name), content, 0600)
+}
+
+// ManifestChecksum returns the checksum of an auto-deploying manifest, or an empty string when it does not exist.
+func ManifestChecksum(commands CommandRunner, name string) (string, error) {
+	return FileChecksumAsRoot(commands, ManifestPath(name))
+}
+
+// RemoveManifest removes an auto-deploying manifest.
+func RemoveManifest(ctx context.Context, config *ssh_handler.SshConfig, name string) error {
+	return RemoveFileAsRoot(ctx, config, ManifestPath(name))
+}
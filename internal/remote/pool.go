@@ -0,0 +1,274 @@
+package remote
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/crypto/ssh"
+	"net"
+	"sync"
+	"time"
+)
+
+// DefaultKeepAliveInterval is how often the cached connections are checked, a connection not answering within the
+// interval is closed and dialed again by its next user.
+const DefaultKeepAliveInterval = 30 * time.Second
+
+// maxPooledSessions bounds the sessions opened at once over a cached connection, sshd refuses the sessions beyond its
+// MaxSessions setting, 10 by default.
+const maxPooledSessions = 8
+
+// ConnectionPool caches the SSH connections of the provider, so that the operations on a host share a single
+// connection instead of completing a handshake each. Connections are identified by the host, port, user and a hash
+// of the credentials, and are multiplexed by opening a session per operation.
+type ConnectionPool struct {
+	keepAliveInterval time.Duration
+
+	mutex       sync.Mutex
+	connections map[string]*pooledConnection
+	closed      bool
+}
+
+type pooledConnection struct {
+	// ready is closed once the connection is dialed, client and err are set then.
+	ready  chan struct{}
+	client *ssh.Client
+	err    error
+
+	sessions chan struct{}
+	// done is closed once the connection is closed.
+	done chan struct{}
+}
+
+func NewConnectionPool() *ConnectionPool {
+	return &ConnectionPool{
+		keepAliveInterval: DefaultKeepAliveInterval,
+		connections:       make(map[string]*pooledConnection),
+	}
+}
+
+// Close closes the cached connections, the pool dials no connection afterwards.
+func (p *ConnectionPool) Close() {
+	p.mutex.Lock()
+	connections := p.connections
+	p.connections = make(map[string]*pooledConnection)
+	p.closed = true
+	p.mutex.Unlock()
+
+	for _, connection := range connections {
+		<-connection.ready
+		if connection.client != nil {
+			connection.client.Close()
+		}
+	}
+}
+
+// acquire returns the connection to the host once a session can be opened over it, and the function to call once
+// the session is closed.
+func (p *ConnectionPool) acquire(ctx context.Context, config *ssh_handler.SshConfig) (*ssh.Client, func(), error) {
+	connection, err := p.connection(ctx, config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	select {
+	case connection.sessions <- struct{}{}:
+		return connection.client, func() { <-connection.sessions }, nil
+	case <-connection.done:
+		return nil, nil, fmt.Errorf("the connection to %s was closed", net.JoinHostPort(config.GetHost(), config.GetPort()))
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+}
+
+func (p *ConnectionPool) connection(ctx context.Context, config *ssh_handler.SshConfig) (*pooledConnection, error) {
+	key := connectionKey(config)
+
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		return nil, errors.New("the connection pool is closed")
+	}
+	connection, found := p.connections[key]
+	if found {
+		p.mutex.Unlock()
+
+		select {
+		case <-connection.ready:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if connection.err != nil {
+			return nil, connection.err
+		}
+
+		logRemote(ctx, tflog.SubsystemTrace, tflog.Trace, "reusing the cached connection", map[string]interface{}{
+			"host": net.JoinHostPort(config.GetHost(), config.GetPort()),
+		})
+		return connection, nil
+	}
+
+	connection = &pooledConnection{
+		ready:    make(chan struct{}),
+		sessions: make(chan struct{}, maxPooledSessions),
+		done:     make(chan struct{}),
+	}
+	p.connections[key] = connection
+	p.mutex.Unlock()
+
+	// The connections waiting for this one to be dialed share its outcome.
+	connection.client, connection.err = Dial(ctx, config)
+	close(connection.ready)
+	if connection.err != nil {
+		p.remove(key, connection)
+		close(connection.done)
+		return nil, connection.err
+	}
+
+	go p.watch(key, connection)
+	go p.keepAlive(connection)
+
+	return connection, nil
+}
+
+// watch forgets the connection once it is closed, by the pool, by its keepalive or by the host.
+func (p *ConnectionPool) watch(key string, connection *pooledConnection) {
+	_ = connection.client.Wait()
+	p.remove(key, connection)
+	close(connection.done)
+}
+
+// keepAlive sends keepalive requests over the connection, closing it when the host does not answer one within the
+// interval, e.g. when the host rebooted or a NAT dropped the connection.
+func (p *ConnectionPool) keepAlive(connection *pooledConnection) {
+	ticker := time.NewTicker(p.keepAliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-connection.done:
+			return
+		case <-ticker.C:
+		}
+
+		answered := make(chan error, 1)
+		go func() {
+			_, _, err := connection.client.SendRequest("keepalive@openssh.com", true, nil)
+			answered <- err
+		}()
+
+		select {
+		case err := <-answered:
+			if err == nil {
+				continue
+			}
+		case <-time.After(p.keepAliveInterval):
+		case <-connection.done:
+			return
+		}
+		connection.client.Close()
+		return
+	}
+}
+
+// discard closes the connection to the host, and forgets it right away so that its next user dials a new one.
+func (p *ConnectionPool) discard(config *ssh_handler.SshConfig, client *ssh.Client) {
+	key := connectionKey(config)
+
+	p.mutex.Lock()
+	if connection, found := p.connections[key]; found {
+		// A connection still being dialed is not the one to discard, and its client is not set yet.
+		select {
+		case <-connection.ready:
+			if connection.client == client {
+				delete(p.connections, key)
+			}
+		default:
+		}
+	}
+	p.mutex.Unlock()
+
+	client.Close()
+}
+
+func (p *ConnectionPool) remove(key string, connection *pooledConnection) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.connections[key] == connection {
+		delete(p.connections, key)
+	}
+}
+
+// connectionKey identifies the connections to a host with the same credentials, which are hashed rather than kept.
+func connectionKey(config *ssh_handler.SshConfig) string {
+	credentials := sha256.Sum256([]byte(
+		config.GetPassword() + "\x00" + config.GetPrivateKey() + "\x00" + config.GetPrivateKeyPassphrase(),
+	))
+
+	return fmt.Sprintf("%s@%s#%x", config.GetUser(), net.JoinHostPort(config.GetHost(), config.GetPort()), credentials)
+}
+
+type connectionPoolKey struct{}
+
+// WithConnectionPool returns a context in which the connections to the hosts are taken from the pool.
+func WithConnectionPool(ctx context.Context, pool *ConnectionPool) context.Context {
+	if pool == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, connectionPoolKey{}, pool)
+}
+
+// newSession opens a session on a connection to the host, and returns the function closing it. The connection comes
+// from the pool of the context when there is one, and is dialed and closed with the session otherwise. A cached
+// connection failing to open the session, e.g. because the host dropped it, is closed and dialed again.
+func newSession(ctx context.Context, config *ssh_handler.SshConfig) (*ssh.Session, func(), error) {
+	pool, ok := ctx.Value(connectionPoolKey{}).(*ConnectionPool)
+	if !ok {
+		client, err := Dial(ctx, config)
+		if err != nil {
+			return nil, nil, err
+		}
+		session, err := client.NewSession()
+		if err != nil {
+			client.Close()
+			return nil, nil, err
+		}
+
+		return session, func() {
+			session.Close()
+			client.Close()
+		}, nil
+	}
+
+	for attempt := 1; ; attempt++ {
+		client, release, err := pool.acquire(ctx, config)
+		if err != nil {
+			return nil, nil, err
+		}
+		session, err := client.NewSession()
+		if err == nil {
+			return session, func() {
+				session.Close()
+				release()
+			}, nil
+		}
+
+		release()
+		// The host refusing the session, e.g. beyond its MaxSessions setting, does not mean the connection failed.
+		var openChannelError *ssh.OpenChannelError
+		if attempt > 1 || errors.As(err, &openChannelError) {
+			return nil, nil, err
+		}
+		pool.discard(config, client)
+
+		logRemote(ctx, tflog.SubsystemDebug, tflog.Debug, "the cached connection failed, reconnecting", map[string]interface{}{
+			"host":  net.JoinHostPort(config.GetHost(), config.GetPort()),
+			"error": err.Error(),
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package remote
+
+import (
+	"context"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/sshtest"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConnectionPoolSharesConnections(t *testing.T) {
+	server := sshtest.NewServer(t)
+	host := ssh_handler.NewSshConfig(server.Host(), server.Port(), server.User, server.Password, "", "")
+
+	pool := NewConnectionPool()
+	ctx := WithConnectionPool(context.Background(), pool)
+
+	var waitGroup sync.WaitGroup
+	for range 2 * maxPooledSessions {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			if _, err := Exec(ctx, host, Command{Command: "k3s --version"}); err != nil {
+				t.Errorf("failed to run the command: %s", err)
+			}
+		}()
+	}
+	waitGroup.Wait()
+
+	if accepted := server.Accepted(); accepted != 1 {
+		t.Errorf("expected the commands to share a single connection, got %d connections", accepted)
+	}
+
+	pool.Close()
+	if _, err := Exec(ctx, host, Command{Command: "k3s --version"}); err == nil || !strings.Contains(err.Error(), "closed") {
+		t.Errorf("expected the closed pool to refuse connections, got: %v", err)
+	}
+}
+
+func TestConnectionPoolReconnectsDroppedConnections(t *testing.T) {
+	server := sshtest.NewServer(t)
+	host := ssh_handler.NewSshConfig(server.Host(), server.Port(), server.User, server.Password, "", "")
+
+	pool := NewConnectionPool()
+	pool.keepAliveInterval = 10 * time.Millisecond
+	defer pool.Close()
+	ctx := WithConnectionPool(context.Background(), pool)
+
+	if _, err := Exec(ctx, host, Command{Command: "k3s --version"}); err != nil {
+		t.Fatalf("failed to run the command: %s", err)
+	}
+
+	// The keepalives answered by the host keep the connection open.
+	time.Sleep(50 * time.Millisecond)
+	if _, err := Exec(ctx, host, Command{Command: "k3s --version"}); err != nil {
+		t.Fatalf("failed to run the command: %s", err)
+	}
+	if accepted := server.Accepted(); accepted != 1 {
+		t.Fatalf("expected the connection to be kept open, got %d connections", accepted)
+	}
+
+	server.Disconnect()
+	if _, err := Exec(ctx, host, Command{Command: "k3s --version"}); err != nil {
+		t.Fatalf("expected the dropped connection to be dialed again, got: %s", err)
+	}
+	if accepted := server.Accepted(); accepted != 2 {
+		t.Errorf("expected a new connection, got %d connections", accepted)
+	}
+}
+
+func TestConnectionKeyHashesCredentials(t *testing.T) {
+	host := ssh_handler.NewSshConfig("10.0.0.1", "22", "root", "secret-password", "", "")
+	otherPassword := ssh_handler.NewSshConfig("10.0.0.1", "22", "root", "other-password", "", "")
+
+	key := connectionKey(host)
+	if strings.Contains(key, "secret-password") {
+		t.Errorf("expected the key not to contain the password, got: %s", key)
+	}
+	if key == connectionKey(otherPassword) {
+		t.Error("expected connections with different credentials not to be shared")
+	}
+}
@@ -0,0 +1,83 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"sort"
+	"strings"
+)
+
+// secretEnvDir holds the environment files of the commands run by RunAsRootWithEnv, it is a tmpfs on systemd hosts
+// so that the secrets never reach the disk.
+const secretEnvDir = "/run/yoshik3s"
+
+// CommandRunner runs commands as root on a host, with environment variables holding secrets. The commands inspecting
+// the host are run with Query and the ones changing it with Run, so that a dry run can record the latter instead.
+type CommandRunner interface {
+	// Query runs a command inspecting the host and returns its standard output.
+	Query(command string, env map[string]string) ([]byte, error)
+	// Run runs a command changing the host and returns its standard output.
+	Run(command string, env map[string]string) ([]byte, error)
+}
+
+// Run executes a command on the host described by the connection config and returns its standard output.
+func Run(ctx context.Context, config *ssh_handler.SshConfig, command string) ([]byte, error) {
+	return Exec(ctx, config, Command{Command: command})
+}
+
+// RunAsRoot executes a command on the host described by the connection config, with the privileges given by the
+// become settings of the context.
+func RunAsRoot(ctx context.Context, config *ssh_handler.SshConfig, command string) ([]byte, error) {
+	become := BecomeFromContext(ctx, config)
+
+	return Exec(WithSecrets(ctx, become.Password), config, Command{
+		Command: become.Command(config, command),
+		Stdin:   become.Stdin(config),
+	})
+}
+
+// RunAsRootWithEnv executes a command as RunAsRoot does, with environment variables holding secrets. They are written to
+// a file only root can read, which the command sources and removes before starting, so that they appear neither on
+// the command line nor in the logs.
+func RunAsRootWithEnv(ctx context.Context, config *ssh_handler.SshConfig, env map[string]string, command string) ([]byte, error) {
+	if len(env) == 0 {
+		return RunAsRoot(ctx, config, command)
+	}
+
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var content strings.Builder
+	secrets := make([]string, 0, len(names))
+	for _, name := range names {
+		fmt.Fprintf(&content, "%s=%s\n", name, ShellQuote(env[name]))
+		secrets = append(secrets, env[name])
+	}
+	ctx = WithSecrets(ctx, secrets...)
+
+	envPath, err := randomPath(secretEnvDir + "/env-")
+	if err != nil {
+		return nil, err
+	}
+	if err := WriteFileAsRoot(ctx, config, envPath, []byte(content.String()), 0600); err != nil {
+		return nil, err
+	}
+
+	script := fmt.Sprintf(
+		"set -a; . %s; loaded=$?; rm -f %s; [ $loaded -eq 0 ] && exec %s",
+		ShellQuote(envPath),
+		ShellQuote(envPath),
+		command,
+	)
+
+	return RunAsRoot(ctx, config, "sh -c "+ShellQuote(script))
+}
+
+// ShellQuote quotes a value so it is interpreted literally by a POSIX shell.
+func ShellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
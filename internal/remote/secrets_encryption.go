@@ -0,0 +1,82 @@
+package remote
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SecretsEncryptionStatus is the output of `k3s secrets-encrypt status`.
+type SecretsEncryptionStatus struct {
+	Status string
+	Stage  string
+	Hashes string
+}
+
+// ReadSecretsEncryptionStatus returns the secrets encryption status of a running server node.
+func ReadSecretsEncryptionStatus(commands CommandRunner) (*SecretsEncryptionStatus, error) {
+	output, err := commands.Query("k3s secrets-encrypt status", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the secrets encryption status: %w", err)
+	}
+
+	return ParseSecretsEncryptionStatus(string(output)), nil
+}
+
+// ParseSecretsEncryptionStatus parses the output of `k3s secrets-encrypt status`:
+//
+//	Encryption Status: Enabled
+//	Current Rotation Stage: start
+//	Server Encryption Hashes: All hashes match
+func ParseSecretsEncryptionStatus(output string) *SecretsEncryptionStatus {
+	status := &SecretsEncryptionStatus{}
+
+	for _, line := range strings.Split(output, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+
+		switch strings.TrimSpace(key) {
+		case "Encryption Status":
+			status.Status = strings.TrimSpace(value)
+		case "Current Rotation Stage":
+			status.Stage = strings.TrimSpace(value)
+		case "Server Encryption Hashes":
+			status.Hashes = strings.TrimSpace(value)
+		}
+	}
+
+	return status
+}
+
+// SecretsEncrypt runs a `k3s secrets-encrypt` subcommand, e.g. prepare, rotate or reencrypt.
+func SecretsEncrypt(commands CommandRunner, subcommand string, args ...string) error {
+	command := strings.Join(append([]string{"k3s secrets-encrypt", subcommand}, args...), " ")
+
+	if _, err := commands.Run(command, nil); err != nil {
+		return fmt.Errorf("failed to run k3s secrets-encrypt %s: %w", subcommand, err)
+	}
+
+	return nil
+}
+
+// WaitForSecretsEncryptionStage polls the secrets encryption status until the rotation reaches the stage.
+func WaitForSecretsEncryptionStage(commands CommandRunner, stage string, timeout time.Duration, interval time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		status, err := ReadSecretsEncryptionStatus(commands)
+		if err == nil && status.Stage == stage {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("timed out waiting for the secrets encryption stage %s, current stage: %s", stage, status.Stage)
+		}
+
+		time.Sleep(interval)
+	}
+}
@@ -0,0 +1,41 @@
+package remote
+
+import (
+	"fmt"
+)
+
+// StopK3s stops the k3s service of a server node.
+func StopK3s(commands CommandRunner) error {
+	if _, err := commands.Run("systemctl stop k3s", nil); err != nil {
+		return fmt.Errorf("failed to stop k3s: %w", err)
+	}
+
+	return nil
+}
+
+// StartK3s starts the k3s service of a server node.
+func StartK3s(commands CommandRunner) error {
+	if _, err := commands.Run("systemctl start k3s", nil); err != nil {
+		return fmt.Errorf("failed to start k3s: %w", err)
+	}
+
+	return nil
+}
+
+// RestartK3s restarts the k3s service of a server node, waiting for it to be ready.
+func RestartK3s(commands CommandRunner) error {
+	if _, err := commands.Run("systemctl restart k3s", nil); err != nil {
+		return fmt.Errorf("failed to restart k3s: %w", err)
+	}
+
+	return nil
+}
+
+// TryRestartService restarts the systemd service when it is running, e.g. k3s or k3s-agent.
+func TryRestartService(commands CommandRunner, service string) error {
+	if _, err := commands.Run("systemctl try-restart "+ShellQuote(service), nil); err != nil {
+		return fmt.Errorf("failed to restart %s: %w", service, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"github.com/pkg/sftp"
+	"os"
+)
+
+// UploadFileAsRoot uploads the content through SFTP to a temporary file, which is then moved to its
+// destination as root, since the SSH user is usually not allowed to write there.
+func UploadFileAsRoot(ctx context.Context, config *ssh_handler.SshConfig, filePath string, content []byte, mode os.FileMode, owner string) error {
+	tempPath, err := uploadTempFile(ctx, config, content)
+	if err != nil {
+		return fmt.Errorf("failed to upload %s: %w", filePath, err)
+	}
+
+	script := fmt.Sprintf(
+		"install -D -m %o %s %s && chown %s %s; status=$?; rm -f %s; exit $status",
+		mode.Perm(),
+		ShellQuote(tempPath),
+		ShellQuote(filePath),
+		ShellQuote(owner),
+		ShellQuote(filePath),
+		ShellQuote(tempPath),
+	)
+
+	if _, err := RunAsRoot(ctx, config, "sh -c "+ShellQuote(script)); err != nil {
+		return fmt.Errorf("failed to install %s: %w", filePath, err)
+	}
+
+	return nil
+}
+
+func uploadTempFile(ctx context.Context, config *ssh_handler.SshConfig, content []byte) (string, error) {
+	tempPath, err := uploadTempPath()
+	if err != nil {
+		return "", err
+	}
+
+	// SFTP cannot set the mode of the files it creates, the file is created beforehand by the shell so that it is
+	// only readable by the SSH user from the start, set -C failing when the path already exists.
+	if _, err := Run(ctx, config, "umask 077 && set -C && : > "+ShellQuote(tempPath)); err != nil {
+		return "", err
+	}
+
+	session, closeSession, err := newSession(ctx, config)
+	if err != nil {
+		return "", err
+	}
+	defer closeSession()
+
+	// Like sftp.NewClient, over a session which may share its connection with other operations.
+	stdin, stdinErr := session.StdinPipe()
+	stdout, stdoutErr := session.StdoutPipe()
+	if err := errors.Join(stdinErr, stdoutErr); err != nil {
+		return "", err
+	}
+	if err := session.RequestSubsystem("sftp"); err != nil {
+		return "", err
+	}
+	sftpClient, err := sftp.NewClientPipe(stdout, stdin)
+	if err != nil {
+		return "", err
+	}
+	defer sftpClient.Close()
+
+	file, err := sftpClient.OpenFile(tempPath, os.O_WRONLY|os.O_TRUNC)
+	if err != nil {
+		return "", err
+	}
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	return tempPath, nil
+}
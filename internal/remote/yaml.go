@@ -0,0 +1,22 @@
+package remote
+
+import (
+	"bytes"
+	"gopkg.in/yaml.v3"
+)
+
+// MarshalYaml encodes the value with the two spaces indentation used by Kubernetes manifests.
+func MarshalYaml(value interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,164 @@
+package resource
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/model"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// certificateAuthorityPair is a certificate authority of the certificate_authority attribute, together with the
+// name of its files in the K3S server tls directory.
+type certificateAuthorityPair struct {
+	certificateAttribute string
+	keyAttribute         string
+	fileName             string
+	certificate          types.String
+	key                  types.String
+}
+
+func certificateAuthorityPairs(caModel model.YoshiK3SCertificateAuthorityModel) []certificateAuthorityPair {
+	return []certificateAuthorityPair{
+		{"server_ca_certificate", "server_ca_key", "server-ca", caModel.ServerCaCertificate, caModel.ServerCaKey},
+		{"client_ca_certificate", "client_ca_key", "client-ca", caModel.ClientCaCertificate, caModel.ClientCaKey},
+		{"request_header_ca_certificate", "request_header_ca_key", "request-header-ca", caModel.RequestHeaderCaCertificate, caModel.RequestHeaderCaKey},
+		{"etcd_server_ca_certificate", "etcd_server_ca_key", "etcd/server-ca", caModel.EtcdServerCaCertificate, caModel.EtcdServerCaKey},
+		{"etcd_peer_ca_certificate", "etcd_peer_ca_key", "etcd/peer-ca", caModel.EtcdPeerCaCertificate, caModel.EtcdPeerCaKey},
+	}
+}
+
+// certificateAuthorityFiles returns the custom certificate authority files of the cluster, keyed by their path
+// relative to the K3S server tls directory. It is empty when the cluster has no custom certificate authorities.
+func certificateAuthorityFiles(ctx context.Context, cluster types.Object) (map[string][]byte, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	files := map[string][]byte{}
+
+	if cluster.IsNull() || cluster.IsUnknown() {
+		return files, diags
+	}
+
+	var clusterModel model.YoshiK3SClusterResourceModel
+	diags.Append(cluster.As(ctx, &clusterModel, basetypes.ObjectAsOptions{})...)
+	if diags.HasError() || clusterModel.CertificateAuthority.IsNull() || clusterModel.CertificateAuthority.IsUnknown() {
+		return files, diags
+	}
+
+	var caModel model.YoshiK3SCertificateAuthorityModel
+	diags.Append(clusterModel.CertificateAuthority.As(ctx, &caModel, basetypes.ObjectAsOptions{})...)
+	if diags.HasError() {
+		return files, diags
+	}
+
+	for _, pair := range certificateAuthorityPairs(caModel) {
+		if pair.certificate.IsNull() || pair.key.IsNull() {
+			continue
+		}
+
+		files[pair.fileName+".crt"] = []byte(pair.certificate.ValueString())
+		files[pair.fileName+".key"] = []byte(pair.key.ValueString())
+	}
+
+	return files, diags
+}
+
+// validateCertificateAuthorityUnchanged checks that the certificate authorities of the cluster of an installed master
+// node are not changed, since they are only uploaded before K3S bootstraps the cluster and are not updated afterwards.
+func validateCertificateAuthorityUnchanged(ctx context.Context, attributePath path.Path, cluster types.Object, priorCluster types.Object) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if cluster.IsNull() || cluster.IsUnknown() || priorCluster.IsNull() || priorCluster.IsUnknown() {
+		return diags
+	}
+
+	clusterModel, clusterDiags := parseClusterObject(ctx, cluster)
+	diags.Append(clusterDiags...)
+	priorClusterModel, priorDiags := parseClusterObject(ctx, priorCluster)
+	diags.Append(priorDiags...)
+	if diags.HasError() || clusterModel.CertificateAuthority.IsUnknown() {
+		return diags
+	}
+
+	if !clusterModel.CertificateAuthority.Equal(priorClusterModel.CertificateAuthority) {
+		diags.AddAttributeError(
+			attributePath,
+			"Certificate authorities cannot be changed",
+			"The certificate authorities are uploaded to the first master node before K3S bootstraps the cluster, "+
+				"K3S keeps using them afterwards. Restore the previous certificate_authority, or replace the cluster "+
+				"and its nodes to use different certificate authorities.",
+		)
+	}
+
+	return diags
+}
+
+// isJoiningServer reports whether the master node joins an existing cluster through the --server option,
+// otherwise it is the first master node of the cluster.
+func isJoiningServer(options []string) bool {
+	for _, option := range options {
+		if flag, ok := parseNodeOptionFlag(option); ok && (flag == "server" || flag == "s") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateCertificateAuthorityObject validates the certificate_authority attribute of the clusters: every
+// certificate must be a CA matching its private key.
+func validateCertificateAuthorityObject(ctx context.Context, attributePath path.Path, ca types.Object) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if ca.IsNull() || ca.IsUnknown() {
+		return diags
+	}
+
+	var caModel model.YoshiK3SCertificateAuthorityModel
+	diags.Append(ca.As(ctx, &caModel, basetypes.ObjectAsOptions{})...)
+	if diags.HasError() {
+		return diags
+	}
+
+	for _, pair := range certificateAuthorityPairs(caModel) {
+		if pair.certificate.IsUnknown() || pair.key.IsUnknown() {
+			continue
+		}
+
+		if pair.certificate.IsNull() != pair.key.IsNull() {
+			diags.AddAttributeError(
+				attributePath,
+				"Incomplete certificate authority",
+				fmt.Sprintf("The %s and %s attributes must be set together.", pair.certificateAttribute, pair.keyAttribute),
+			)
+			continue
+		}
+		if pair.certificate.IsNull() {
+			continue
+		}
+
+		keyPair, err := tls.X509KeyPair([]byte(pair.certificate.ValueString()), []byte(pair.key.ValueString()))
+		if err != nil {
+			diags.AddAttributeError(
+				attributePath.AtName(pair.keyAttribute),
+				"Invalid certificate authority",
+				fmt.Sprintf("The %s does not match the %s: %s.", pair.keyAttribute, pair.certificateAttribute, err.Error()),
+			)
+			continue
+		}
+
+		certificate, err := x509.ParseCertificate(keyPair.Certificate[0])
+		if err != nil || !certificate.IsCA {
+			diags.AddAttributeError(
+				attributePath.AtName(pair.certificateAttribute),
+				"Invalid certificate authority",
+				fmt.Sprintf("The %s must be a CA certificate.", pair.certificateAttribute),
+			)
+		}
+	}
+
+	return diags
+}
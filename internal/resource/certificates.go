@@ -0,0 +1,141 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/executor"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/model"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/remote"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"sort"
+	"strings"
+	"time"
+)
+
+// readCertificateExpiry returns the expiry of the server certificates of the node, formatted in RFC 3339.
+func readCertificateExpiry(ctx context.Context, commands remote.CommandRunner) (types.Map, error) {
+	expiry, err := remote.ReadCertificateExpiry(commands)
+	if err != nil {
+		return types.MapNull(types.StringType), err
+	}
+
+	values := make(map[string]string, len(expiry))
+	for name, notAfter := range expiry {
+		values[name] = notAfter.Format(time.RFC3339)
+	}
+
+	value, diags := types.MapValueFrom(ctx, types.StringType, values)
+	if diags.HasError() {
+		return types.MapNull(types.StringType), fmt.Errorf("failed to convert the certificate expiry: %v", diags)
+	}
+
+	return value, nil
+}
+
+// warnCertificateExpiry warns about the certificates expiring within the configured number of days.
+func warnCertificateExpiry(ctx context.Context, expiry types.Map, warningDays types.Int64, now time.Time) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if expiry.IsNull() || expiry.IsUnknown() || warningDays.IsNull() || warningDays.IsUnknown() {
+		return diags
+	}
+
+	values := map[string]string{}
+	diags.Append(expiry.ElementsAs(ctx, &values, false)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	deadline := now.AddDate(0, 0, int(warningDays.ValueInt64()))
+
+	var expiring []string
+	for name, value := range values {
+		notAfter, err := time.Parse(time.RFC3339, value)
+		if err != nil || notAfter.After(deadline) {
+			continue
+		}
+		expiring = append(expiring, fmt.Sprintf("%s (%s)", name, value))
+	}
+
+	if len(expiring) == 0 {
+		return diags
+	}
+
+	sort.Strings(expiring)
+	diags.AddAttributeWarning(
+		path.Root("certificate_expiry"),
+		"Certificates about to expire",
+		fmt.Sprintf(
+			"The following certificates expire within %d days: %s. Change rotate_certificates to rotate them.",
+			warningDays.ValueInt64(), strings.Join(expiring, ", "),
+		),
+	)
+
+	return diags
+}
+
+// isCertificateRotationPlanned reports whether the rotate_certificates trigger was set or changed, removing it
+// does not rotate the certificates.
+func isCertificateRotationPlanned(trigger types.String, priorTrigger types.String) bool {
+	if trigger.IsNull() {
+		return false
+	}
+
+	return !trigger.Equal(priorTrigger)
+}
+
+// rotateCertificates rotates the server certificates of the node and returns its kubeconfig again, since the
+// client certificate it embeds is rotated as well. No kubeconfig is returned by a dry run.
+func rotateCertificates(ctx context.Context, exec executor.Executor, sshConfig *ssh_handler.SshConfig, cluster types.Object) ([]byte, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	var clusterModel model.YoshiK3SClusterResourceModel
+	diags.Append(cluster.As(ctx, &clusterModel, basetypes.ObjectAsOptions{})...)
+
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	tflog.Info(ctx, "rotating the k3s certificates", map[string]interface{}{
+		"host": sshConfig.GetHost(),
+	})
+	if err := remote.RotateCertificates(executor.Commands(ctx, exec, sshConfig)); err != nil {
+		diags.AddError("failed to rotate the certificates", err.Error())
+		return nil, diags
+	}
+	if isDryRun(exec) {
+		return nil, diags
+	}
+
+	kubeconfig, err := readKubeconfig(ctx, exec, sshConfig, clusterModel.ClusterAddress.ValueString())
+	if err != nil {
+		diags.AddError("failed to rotate the certificates", err.Error())
+		return nil, diags
+	}
+
+	return kubeconfig, diags
+}
+
+// validateCertificateExpiryWarningDays validates the certificate_expiry_warning_days attribute of the master nodes.
+func validateCertificateExpiryWarningDays(attributePath path.Path, warningDays types.Int64) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if warningDays.IsNull() || warningDays.IsUnknown() {
+		return diags
+	}
+
+	if warningDays.ValueInt64() < 0 {
+		diags.AddAttributeError(
+			attributePath,
+			"Invalid certificate expiry warning",
+			fmt.Sprintf("The number of days must not be negative, got: %d.", warningDays.ValueInt64()),
+		)
+	}
+
+	return diags
+}
@@ -0,0 +1,140 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/model"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/providerdata"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// configureProviderData extracts the data shared by the provider, it is nil when the provider
+// has not been configured yet, e.g. during validation.
+func configureProviderData(providerData any, diags *diag.Diagnostics) *providerdata.Data {
+	if providerData == nil {
+		return nil
+	}
+
+	data, ok := providerData.(*providerdata.Data)
+	if !ok {
+		diags.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *providerdata.Data, got: %T. Please report this issue to the provider developers.", providerData),
+		)
+		return nil
+	}
+
+	return data
+}
+
+// planClusterReference returns the cluster object a node should be planned with. When the node
+// references a cluster through cluster_id, the cluster is resolved from the provider registry so
+// changes to the referenced cluster are planned as changes to the node.
+func planClusterReference(ctx context.Context, providerData *providerdata.Data, clusterId types.String, plannedCluster types.Object, priorCluster types.Object) (types.Object, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if clusterId.IsNull() {
+		return plannedCluster, diags
+	}
+	if clusterId.IsUnknown() {
+		return types.ObjectUnknown(plannedCluster.AttributeTypes(ctx)), diags
+	}
+
+	if providerData != nil {
+		if cluster, found := providerData.Clusters.Get(clusterId.ValueString()); found {
+			return clusterObjectValue(ctx, plannedCluster.AttributeTypes(ctx), cluster)
+		}
+	}
+
+	// The registry only holds the clusters Terraform handled in this run, the prior cluster is kept otherwise, e.g.
+	// when the node is targeted alone, but the changes to the cluster cannot be planned.
+	if !priorCluster.IsNull() && !priorCluster.IsUnknown() {
+		diags.AddAttributeWarning(
+			path.Root("cluster_id"),
+			"Cluster not found in this run",
+			fmt.Sprintf("The yoshik3s_cluster with the id %q was not read or planned in this run, e.g. because it is not "+
+				"part of -target. The node keeps the cluster attributes of its state, the changes made to the cluster are "+
+				"not planned for it until both resources are handled in the same run.", clusterId.ValueString()),
+		)
+		return priorCluster, diags
+	}
+
+	diags.AddAttributeError(path.Root("cluster_id"), "Unknown cluster", unknownClusterDetail(clusterId.ValueString()))
+	return plannedCluster, diags
+}
+
+// applyClusterReference resolves the cluster of a node whose cluster was unknown while planning,
+// which happens when the referenced cluster is created in the same run.
+func applyClusterReference(ctx context.Context, providerData *providerdata.Data, clusterId types.String, plannedCluster types.Object) (types.Object, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if !plannedCluster.IsUnknown() {
+		return plannedCluster, diags
+	}
+
+	if providerData != nil && !clusterId.IsNull() && !clusterId.IsUnknown() {
+		if cluster, found := providerData.Clusters.Get(clusterId.ValueString()); found {
+			return clusterObjectValue(ctx, plannedCluster.AttributeTypes(ctx), cluster)
+		}
+	}
+
+	diags.AddAttributeError(path.Root("cluster_id"), "Unknown cluster", unknownClusterDetail(clusterId.ValueString()))
+	return plannedCluster, diags
+}
+
+// unknownClusterDetail explains why a cluster_id could not be resolved: the clusters are shared with the nodes
+// through the provider while Terraform handles them, so only the clusters of the same run can be referenced.
+func unknownClusterDetail(clusterId string) string {
+	return fmt.Sprintf("No yoshik3s_cluster with the id %q was read or planned in this run. cluster_id must reference a "+
+		"yoshik3s_cluster resource of the same configuration and provider configuration, e.g. "+
+		"yoshik3s_cluster.example.id, and the cluster must be part of the run when using -target.", clusterId)
+}
+
+// parseClusterObject converts the cluster attribute of a node resource into the cluster model, the certificate
+// authorities left out of the worker nodes being null.
+func parseClusterObject(ctx context.Context, cluster types.Object) (model.YoshiK3SClusterResourceModel, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var clusterModel model.YoshiK3SClusterResourceModel
+
+	attributes := cluster.Attributes()
+	if _, found := attributes["certificate_authority"]; !found && !cluster.IsNull() && !cluster.IsUnknown() {
+		nodeAttributes := make(map[string]attr.Value, len(attributes)+1)
+		for name, value := range attributes {
+			nodeAttributes[name] = value
+		}
+		nodeAttributes["certificate_authority"] = types.ObjectNull(model.YoshiK3SCertificateAuthorityAttributeTypes)
+
+		cluster, diags = types.ObjectValue(model.YoshiK3SNodeClusterAttributeTypes, nodeAttributes)
+		if diags.HasError() {
+			return clusterModel, diags
+		}
+	}
+
+	diags.Append(cluster.As(ctx, &clusterModel, basetypes.ObjectAsOptions{})...)
+	return clusterModel, diags
+}
+
+// clusterObjectValue converts the cluster model into the cluster attribute of a node resource, keeping only the
+// attributes of its schema.
+func clusterObjectValue(ctx context.Context, attributeTypes map[string]attr.Type, clusterModel model.YoshiK3SClusterResourceModel) (types.Object, diag.Diagnostics) {
+	cluster, diags := types.ObjectValueFrom(ctx, model.YoshiK3SNodeClusterAttributeTypes, clusterModel)
+	if diags.HasError() {
+		return types.ObjectUnknown(attributeTypes), diags
+	}
+	if _, found := attributeTypes["certificate_authority"]; found {
+		return cluster, diags
+	}
+
+	attributes := make(map[string]attr.Value, len(attributeTypes))
+	for name, value := range cluster.Attributes() {
+		if _, found := attributeTypes[name]; found {
+			attributes[name] = value
+		}
+	}
+
+	return types.ObjectValue(attributeTypes, attributes)
+}
@@ -2,24 +2,34 @@ package resource
 
 import (
 	"context"
+	"fmt"
 	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/model"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/providerdata"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/remote"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"strings"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.Resource = &YoshiK3SClusterResource{}
 var _ resource.ResourceWithImportState = &YoshiK3SClusterResource{}
+var _ resource.ResourceWithValidateConfig = &YoshiK3SClusterResource{}
+var _ resource.ResourceWithUpgradeState = &YoshiK3SClusterResource{}
+var _ resource.ResourceWithConfigure = &YoshiK3SClusterResource{}
+var _ resource.ResourceWithModifyPlan = &YoshiK3SClusterResource{}
 
 func NewYoshiK3SClusterResource() resource.Resource {
 	return &YoshiK3SClusterResource{}
 }
 
 // YoshiK3SClusterResource defines the resource implementation.
-type YoshiK3SClusterResource struct{}
+type YoshiK3SClusterResource struct {
+	providerData *providerdata.Data
+}
 
 func (r *YoshiK3SClusterResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_cluster"
@@ -30,11 +40,53 @@ func (r *YoshiK3SClusterResource) Schema(ctx context.Context, req resource.Schem
 		// This description is used by the documentation generator and the language server.
 		MarkdownDescription: "K3S Cluster Resource",
 
+		Version: model.YoshiK3SClusterResourceModelSchemaVersion,
+
 		Attributes: model.YoshiK3SClusterResourceModelSchema,
 	}
 }
 
+func (r *YoshiK3SClusterResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		// Version 0 stored the cluster name wrapped in quotes as the id, or "<null>" when unnamed.
+		0: newRawStateUpgrader(unquoteId, replaceNullClusterId),
+	}
+}
+
 func (r *YoshiK3SClusterResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.providerData = configureProviderData(req.ProviderData, &resp.Diagnostics)
+}
+
+// ModifyPlan registers the planned cluster, so the nodes referencing it through cluster_id plan its changes.
+func (r *YoshiK3SClusterResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to plan when the resource is being destroyed.
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var data model.YoshiK3SClusterResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.registerCluster(data)
+}
+
+func (r *YoshiK3SClusterResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data model.YoshiK3SClusterResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(validateAddress(path.Root("address"), data.ClusterAddress)...)
+	resp.Diagnostics.Append(validateK3sVersion(path.Root("k3s_version"), data.ClusterVersion)...)
+	resp.Diagnostics.Append(validateCertificateAuthorityObject(ctx, path.Root("certificate_authority"), data.CertificateAuthority)...)
 }
 
 func (r *YoshiK3SClusterResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -46,9 +98,10 @@ func (r *YoshiK3SClusterResource) Create(ctx context.Context, req resource.Creat
 		return
 	}
 
-	data.Id = types.StringValue(data.ClusterName.String())
+	data.Id = clusterId(data)
 
 	tflog.Trace(ctx, "created a resource")
+	r.registerCluster(data)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -61,6 +114,7 @@ func (r *YoshiK3SClusterResource) Read(ctx context.Context, req resource.ReadReq
 		return
 	}
 
+	r.registerCluster(data)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -73,6 +127,7 @@ func (r *YoshiK3SClusterResource) Update(ctx context.Context, req resource.Updat
 		return
 	}
 
+	r.registerCluster(data)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -85,8 +140,84 @@ func (r *YoshiK3SClusterResource) Delete(ctx context.Context, req resource.Delet
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	if r.providerData != nil {
+		r.providerData.Clusters.Remove(data.Id.ValueString())
+	}
 }
 
+// ImportState accepts either the cluster name, or `<user>@<host>[:<port>]/<cluster_name>` pointing to
+// one of the master nodes, from which the token, address and K3S version are discovered.
 func (r *YoshiK3SClusterResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	ctx = remote.WithCommandLogging(ctx, "yoshik3s_cluster", "import")
+
+	if !strings.Contains(req.ID, "@") {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), req.ID)...)
+		return
+	}
+
+	importId, err := parseNodeImportId(req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid import ID", err.Error())
+		return
+	}
+
+	sshConfig := importId.sshConfig()
+	if err := sshConfig.IsValid(); err != nil {
+		resp.Diagnostics.AddError(
+			"Missing SSH credentials",
+			fmt.Sprintf("Set either %s or %s to import a cluster from a master node.", importPasswordEnvVar, importPrivateKeyEnvVar),
+		)
+		return
+	}
+
+	ctx = withConnectionSettings(ctx, r.providerData, sshConfig, types.ObjectNull(model.YoshiK3SConnectionModelAttributeTypes))
+	nodeInfo, err := nodeExecutor(r.providerData).Status(ctx, sshConfig)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to import cluster", err.Error())
+		return
+	}
+	if nodeInfo.Role != remote.NodeRoleServer {
+		resp.Diagnostics.AddError(
+			"Failed to import cluster",
+			fmt.Sprintf("Expected a K3S server installation on %s, found: %s.", importId.Host, nodeInfo.Role),
+		)
+		return
+	}
+
+	data := model.YoshiK3SClusterResourceModel{
+		ClusterName:    types.StringNull(),
+		ClusterToken:   types.StringValue(nodeInfo.Token),
+		AgentToken:     types.StringNull(),
+		ClusterAddress: types.StringValue(nodeInfo.ServerAddress),
+		ClusterVersion: types.StringValue(nodeInfo.Version),
+
+		CertificateAuthority: types.ObjectNull(model.YoshiK3SCertificateAuthorityAttributeTypes),
+	}
+	if importId.ClusterName != "" {
+		data.ClusterName = types.StringValue(importId.ClusterName)
+	}
+	if nodeInfo.ServerAddress == "" {
+		data.ClusterAddress = types.StringValue(importId.Host)
+	}
+	data.Id = clusterId(data)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// clusterId identifies the cluster by its name, falling back to its address for unnamed clusters.
+func clusterId(data model.YoshiK3SClusterResourceModel) types.String {
+	if data.ClusterName.IsNull() || data.ClusterName.ValueString() == "" {
+		return types.StringValue(data.ClusterAddress.ValueString())
+	}
+	return types.StringValue(data.ClusterName.ValueString())
+}
+
+func (r *YoshiK3SClusterResource) registerCluster(data model.YoshiK3SClusterResourceModel) {
+	if r.providerData == nil {
+		return
+	}
+
+	r.providerData.Clusters.Register(data)
 }
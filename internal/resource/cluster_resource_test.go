@@ -0,0 +1,224 @@
+package resource
+
+import (
+	"context"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/executor"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/model"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/providerdata"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/remote"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"strings"
+	"testing"
+)
+
+func TestClusterResourceLifecycle(t *testing.T) {
+	ctx := context.Background()
+	data := providerdata.New(executor.NewYoshiK3SExecutor())
+
+	r := &YoshiK3SClusterResource{providerData: data}
+	s := resourceSchema(t, r)
+
+	attributes := map[string]attr.Value{
+		"name":        types.StringValue("example-cluster"),
+		"token":       types.StringValue(testClusterToken),
+		"address":     types.StringValue(testClusterAddress),
+		"k3s_version": types.StringValue(testK3sVersion),
+	}
+
+	createResp := &resource.CreateResponse{State: emptyState(s)}
+	r.Create(ctx, resource.CreateRequest{Plan: newPlan(t, s, attributes)}, createResp)
+	requireNoErrors(t, createResp.Diagnostics)
+
+	if id := getStringAttribute(t, createResp.State, path.Root("id")); id.ValueString() != "example-cluster" {
+		t.Errorf("expected the cluster to be identified by its name, got: %s", id)
+	}
+	if _, found := data.Clusters.Get("example-cluster"); !found {
+		t.Fatal("expected the created cluster to be registered")
+	}
+
+	readResp := &resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, readResp)
+	requireNoErrors(t, readResp.Diagnostics)
+
+	attributes["id"] = types.StringValue("example-cluster")
+	attributes["k3s_version"] = types.StringValue("v1.31.0+k3s1")
+	updateResp := &resource.UpdateResponse{State: emptyState(s)}
+	r.Update(ctx, resource.UpdateRequest{Plan: newPlan(t, s, attributes), State: readResp.State}, updateResp)
+	requireNoErrors(t, updateResp.Diagnostics)
+
+	cluster, _ := data.Clusters.Get("example-cluster")
+	if cluster.ClusterVersion.ValueString() != "v1.31.0+k3s1" {
+		t.Errorf("expected the registered cluster to be updated, got: %s", cluster.ClusterVersion)
+	}
+
+	deleteResp := &resource.DeleteResponse{State: updateResp.State}
+	r.Delete(ctx, resource.DeleteRequest{State: updateResp.State}, deleteResp)
+	requireNoErrors(t, deleteResp.Diagnostics)
+
+	if _, found := data.Clusters.Get("example-cluster"); found {
+		t.Error("expected the deleted cluster to be removed from the registry")
+	}
+}
+
+func TestClusterResourceUnnamed(t *testing.T) {
+	ctx := context.Background()
+
+	r := &YoshiK3SClusterResource{}
+	s := resourceSchema(t, r)
+
+	resp := &resource.CreateResponse{State: emptyState(s)}
+	r.Create(ctx, resource.CreateRequest{Plan: newPlan(t, s, map[string]attr.Value{
+		"token":   types.StringValue(testClusterToken),
+		"address": types.StringValue(testClusterAddress),
+	})}, resp)
+	requireNoErrors(t, resp.Diagnostics)
+
+	if id := getStringAttribute(t, resp.State, path.Root("id")); id.ValueString() != testClusterAddress {
+		t.Errorf("expected the unnamed cluster to be identified by its address, got: %s", id)
+	}
+}
+
+func TestClusterResourceImportFromMasterNode(t *testing.T) {
+	ctx := context.Background()
+	server := newK3sServer(t, remote.NodeRoleServer)
+	t.Setenv(importPasswordEnvVar, server.Password)
+
+	r := &YoshiK3SClusterResource{}
+	s := resourceSchema(t, r)
+
+	resp := &resource.ImportStateResponse{State: emptyState(s)}
+	r.ImportState(ctx, resource.ImportStateRequest{
+		ID: server.User + "@" + server.Host() + ":" + server.Port() + "/example-cluster",
+	}, resp)
+	requireNoErrors(t, resp.Diagnostics)
+
+	expected := map[string]string{
+		"id":          "example-cluster",
+		"token":       testClusterToken,
+		"address":     testClusterAddress,
+		"k3s_version": testK3sVersion,
+	}
+	for name, value := range expected {
+		if actual := getStringAttribute(t, resp.State, path.Root(name)); actual.ValueString() != value {
+			t.Errorf("expected the imported %s to be %q, got: %s", name, value, actual)
+		}
+	}
+}
+
+func TestPlanClusterReferenceNotInRun(t *testing.T) {
+	ctx := context.Background()
+	data := providerdata.New(executor.NewYoshiK3SExecutor())
+	clusterId := types.StringValue("example-cluster")
+	planned := types.ObjectUnknown(model.YoshiK3SNodeClusterAttributeTypes)
+
+	// A node targeted alone keeps the cluster of its state, with a warning.
+	prior := testCluster(types.StringNull())
+	cluster, diags := planClusterReference(ctx, data, clusterId, planned, prior)
+	if diags.HasError() || diags.WarningsCount() != 1 {
+		t.Fatalf("expected a single warning, got: %v", diags)
+	}
+	if !cluster.Equal(prior) {
+		t.Errorf("expected the prior cluster to be kept, got: %s", cluster)
+	}
+
+	// A new node cannot be planned without its cluster.
+	_, diags = planClusterReference(ctx, data, clusterId, planned, types.ObjectNull(model.YoshiK3SNodeClusterAttributeTypes))
+	if !diags.HasError() || !strings.Contains(diags.Errors()[0].Detail(), "-target") {
+		t.Errorf("expected an error explaining how clusters are resolved, got: %v", diags)
+	}
+
+	_, diags = applyClusterReference(ctx, data, clusterId, planned)
+	if !diags.HasError() || !strings.Contains(diags.Errors()[0].Detail(), "-target") {
+		t.Errorf("expected an error explaining how clusters are resolved, got: %v", diags)
+	}
+}
+
+func testCertificateAuthority(serverCaCertificate string) types.Object {
+	attributes := map[string]attr.Value{}
+	for name := range model.YoshiK3SCertificateAuthorityAttributeTypes {
+		attributes[name] = types.StringNull()
+	}
+	attributes["server_ca_certificate"] = types.StringValue(serverCaCertificate)
+	attributes["server_ca_key"] = types.StringValue("server-ca-key")
+
+	return types.ObjectValueMust(model.YoshiK3SCertificateAuthorityAttributeTypes, attributes)
+}
+
+func TestPlanClusterReferenceWorkerNode(t *testing.T) {
+	ctx := context.Background()
+	data := providerdata.New(executor.NewYoshiK3SExecutor())
+	data.Clusters.Register(model.YoshiK3SClusterResourceModel{
+		Id:             types.StringValue("example-cluster"),
+		ClusterName:    types.StringValue("example-cluster"),
+		ClusterToken:   types.StringValue(testClusterToken),
+		AgentToken:     types.StringNull(),
+		ClusterAddress: types.StringValue(testClusterAddress),
+		ClusterVersion: types.StringValue(testK3sVersion),
+
+		CertificateAuthority: testCertificateAuthority("server-ca"),
+	})
+
+	// The certificate authorities are left out of the worker nodes.
+	planned := types.ObjectUnknown(model.YoshiK3SWorkerNodeClusterAttributeTypes)
+	cluster, diags := planClusterReference(ctx, data, types.StringValue("example-cluster"), planned, types.ObjectNull(model.YoshiK3SWorkerNodeClusterAttributeTypes))
+	if diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags)
+	}
+	if !cluster.Equal(testWorkerCluster(types.StringNull())) {
+		t.Errorf("expected the cluster without its certificate authorities, got: %s", cluster)
+	}
+
+	clusterModel, diags := parseClusterObject(ctx, cluster)
+	if diags.HasError() || clusterModel.ClusterToken.ValueString() != testClusterToken || !clusterModel.CertificateAuthority.IsNull() {
+		t.Errorf("expected the worker cluster to be parsed without certificate authorities, got: %+v %v", clusterModel, diags)
+	}
+}
+
+func TestValidateCertificateAuthorityUnchanged(t *testing.T) {
+	ctx := context.Background()
+	attributePath := path.Root("cluster").AtName("certificate_authority")
+
+	withCertificateAuthority := func(ca types.Object) types.Object {
+		attributes := testCluster(types.StringNull()).Attributes()
+		attributes["certificate_authority"] = ca
+		return types.ObjectValueMust(model.YoshiK3SNodeClusterAttributeTypes, attributes)
+	}
+	prior := withCertificateAuthority(testCertificateAuthority("server-ca"))
+
+	for name, test := range map[string]struct {
+		cluster  types.Object
+		expected bool
+	}{
+		"unchanged": {
+			cluster:  withCertificateAuthority(testCertificateAuthority("server-ca")),
+			expected: false,
+		},
+		"changed": {
+			cluster:  withCertificateAuthority(testCertificateAuthority("other-server-ca")),
+			expected: true,
+		},
+		"removed": {
+			cluster:  testCluster(types.StringNull()),
+			expected: true,
+		},
+		"unknown": {
+			cluster:  withCertificateAuthority(types.ObjectUnknown(model.YoshiK3SCertificateAuthorityAttributeTypes)),
+			expected: false,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			diags := validateCertificateAuthorityUnchanged(ctx, attributePath, test.cluster, prior)
+			if diags.HasError() != test.expected {
+				t.Errorf("expected an error: %t, got: %v", test.expected, diags)
+			}
+		})
+	}
+
+	// New master nodes upload the certificate authorities of their cluster.
+	if diags := validateCertificateAuthorityUnchanged(ctx, attributePath, prior, types.ObjectNull(model.YoshiK3SNodeClusterAttributeTypes)); diags.HasError() {
+		t.Errorf("unexpected errors: %v", diags)
+	}
+}
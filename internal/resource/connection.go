@@ -0,0 +1,144 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/model"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/providerdata"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/remote"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"net"
+)
+
+// sshConfigFromConnection converts a node_connection attribute into the connection config used by yoshi-k3s.
+func sshConfigFromConnection(ctx context.Context, connection types.Object) *ssh_handler.SshConfig {
+	connectionModel := parseConnectionModel(ctx, connection)
+	if connectionModel == nil {
+		return nil
+	}
+
+	return ssh_handler.NewSshConfig(
+		connectionModel.Host.ValueString(),
+		connectionModel.Port.ValueString(),
+		connectionModel.User.ValueString(),
+		connectionModel.Password.ValueString(),
+		connectionModel.PrivateKey.ValueString(),
+		connectionModel.PrivateKeyPassphrase.ValueString(),
+	)
+}
+
+// connectionAddress returns the host and port of a node_connection, the key of the node registries, it is empty when
+// either is not known yet.
+func connectionAddress(connectionModel *model.YoshiK3SConnectionModel) string {
+	if connectionModel == nil || connectionModel.Host.IsUnknown() || connectionModel.Port.IsUnknown() {
+		return ""
+	}
+
+	return net.JoinHostPort(connectionModel.Host.ValueString(), connectionModel.Port.ValueString())
+}
+
+// parseConnectionModel converts a node_connection attribute into its model, it is nil when the connection is not known.
+func parseConnectionModel(ctx context.Context, connection types.Object) *model.YoshiK3SConnectionModel {
+	if connection.IsNull() || connection.IsUnknown() {
+		return nil
+	}
+
+	var connectionModel model.YoshiK3SConnectionModel
+	diags := connection.As(ctx, &connectionModel, basetypes.ObjectAsOptions{})
+	if diags.HasError() {
+		return nil
+	}
+
+	return &connectionModel
+}
+
+// withConnectionSettings returns a context in which the connections to the host are taken from the connection pool of
+// the provider, and retried with the connection_retry settings of its node_connection attribute, or with the ones of
+// the provider when it has none. The commands needing privileges run with the become settings of the attribute.
+func withConnectionSettings(ctx context.Context, providerData *providerdata.Data, sshConfig *ssh_handler.SshConfig, connection types.Object) context.Context {
+	if sshConfig == nil {
+		return ctx
+	}
+
+	policy := remote.NoRetry
+	if providerData != nil {
+		policy = providerData.ConnectionRetry
+		ctx = remote.WithConnectionPool(ctx, providerData.Connections)
+	}
+
+	if connectionModel := parseConnectionModel(ctx, connection); connectionModel != nil {
+		if connectionPolicy, err := retryPolicyFromObject(ctx, connectionModel.ConnectionRetry); err == nil && connectionPolicy != nil {
+			policy = *connectionPolicy
+		}
+		if become, err := becomeFromObject(ctx, connectionModel.Become); err == nil && become != nil {
+			ctx = remote.WithBecome(ctx, sshConfig, *become)
+		}
+	}
+
+	return remote.WithRetryPolicy(ctx, sshConfig, policy)
+}
+
+// withReadTimeout returns a context bounding the inspection of the hosts while refreshing a resource, with the
+// read_timeout of the provider, and the function releasing it.
+func withReadTimeout(ctx context.Context, providerData *providerdata.Data) (context.Context, context.CancelFunc) {
+	timeout := remote.DefaultReadTimeout
+	if providerData != nil && providerData.ReadTimeout > 0 {
+		timeout = providerData.ReadTimeout
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// retryPolicyFromObject converts a connection_retry attribute into its policy, it is nil when the attribute is not set.
+func retryPolicyFromObject(ctx context.Context, retry types.Object) (*remote.RetryPolicy, error) {
+	if retry.IsNull() || retry.IsUnknown() {
+		return nil, nil
+	}
+
+	var retryModel model.YoshiK3SConnectionRetryModel
+	if diags := retry.As(ctx, &retryModel, basetypes.ObjectAsOptions{}); diags.HasError() {
+		return nil, fmt.Errorf("invalid connection_retry attribute")
+	}
+	if retryModel.MaxAttempts.IsUnknown() || retryModel.InitialDelay.IsUnknown() || retryModel.MaxDelay.IsUnknown() {
+		return nil, nil
+	}
+
+	policy, err := remote.NewRetryPolicy(
+		retryModel.MaxAttempts.ValueInt64Pointer(),
+		retryModel.InitialDelay.ValueString(),
+		retryModel.MaxDelay.ValueString(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+// becomeFromObject converts a become attribute into its settings, it is nil when the attribute is not set.
+func becomeFromObject(ctx context.Context, become types.Object) (*remote.Become, error) {
+	if become.IsNull() || become.IsUnknown() {
+		return nil, nil
+	}
+
+	var becomeModel model.YoshiK3SBecomeModel
+	if diags := become.As(ctx, &becomeModel, basetypes.ObjectAsOptions{}); diags.HasError() {
+		return nil, fmt.Errorf("invalid become attribute")
+	}
+	if becomeModel.Method.IsUnknown() || becomeModel.Password.IsUnknown() || becomeModel.User.IsUnknown() {
+		return nil, nil
+	}
+
+	settings, err := remote.NewBecome(
+		becomeModel.Method.ValueString(),
+		becomeModel.User.ValueString(),
+		becomeModel.Password.ValueString(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &settings, nil
+}
@@ -0,0 +1,128 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/executor"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/model"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/remote"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"strings"
+)
+
+// restoreFromSnapshot restores the datastore of a freshly installed master node from an etcd snapshot:
+// k3s is stopped, the cluster is reset from the snapshot and k3s is started again. The remaining master
+// nodes keep their previous datastore, so the user is told how to make them rejoin. The kubeconfig is
+// returned again since the restored datastore carries the certificate authorities of the snapshot, except by a dry
+// run.
+func restoreFromSnapshot(ctx context.Context, exec executor.Executor, sshConfig *ssh_handler.SshConfig, restore types.Object, cluster types.Object) ([]byte, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if restore.IsNull() || restore.IsUnknown() {
+		return nil, diags
+	}
+
+	var restoreModel model.YoshiK3SEtcdRestoreModel
+	diags.Append(restore.As(ctx, &restoreModel, basetypes.ObjectAsOptions{})...)
+
+	var clusterModel model.YoshiK3SClusterResourceModel
+	diags.Append(cluster.As(ctx, &clusterModel, basetypes.ObjectAsOptions{})...)
+
+	s3, s3Diags := etcdS3OptionsFromModel(ctx, restoreModel.S3)
+	diags.Append(s3Diags...)
+
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	commands := executor.Commands(ctx, exec, sshConfig)
+	snapshotPath := restoreModel.Path.ValueString()
+	logFields := map[string]interface{}{
+		"host":     sshConfig.GetHost(),
+		"snapshot": snapshotPath,
+	}
+
+	tflog.Info(ctx, "restoring etcd snapshot: stopping k3s", logFields)
+	if err := remote.StopK3s(commands); err != nil {
+		diags.AddError("failed to restore etcd snapshot", err.Error())
+		return nil, diags
+	}
+
+	tflog.Info(ctx, "restoring etcd snapshot: resetting the cluster from the snapshot", logFields)
+	if err := remote.ResetClusterFromSnapshot(commands, snapshotPath, clusterModel.ClusterToken.ValueString(), s3); err != nil {
+		diags.AddError("failed to restore etcd snapshot", err.Error())
+		return nil, diags
+	}
+
+	tflog.Info(ctx, "restoring etcd snapshot: starting k3s", logFields)
+	if err := remote.StartK3s(commands); err != nil {
+		diags.AddError("failed to restore etcd snapshot", err.Error())
+		return nil, diags
+	}
+	if isDryRun(exec) {
+		return nil, diags
+	}
+
+	kubeconfig, err := readKubeconfig(ctx, exec, sshConfig, clusterModel.ClusterAddress.ValueString())
+	if err != nil {
+		diags.AddError("failed to restore etcd snapshot", err.Error())
+		return nil, diags
+	}
+
+	tflog.Info(ctx, "restored etcd snapshot", logFields)
+	diags.AddAttributeWarning(
+		path.Root("restore_from_snapshot"),
+		"Remaining master nodes must rejoin the cluster",
+		fmt.Sprintf(
+			"The datastore of %s was restored from %s. On every other master node of the cluster run:\n\n"+
+				"  sudo systemctl stop k3s\n"+
+				"  sudo rm -rf %s\n"+
+				"  sudo systemctl start k3s\n\n"+
+				"so they rejoin the restored datastore through %s.",
+			sshConfig.GetHost(),
+			snapshotPath,
+			remote.K3sServerDatastorePath,
+			clusterModel.ClusterAddress.ValueString(),
+		),
+	)
+
+	return kubeconfig, diags
+}
+
+// validateEtcdRestoreObject validates the restore_from_snapshot attribute of the master nodes.
+func validateEtcdRestoreObject(ctx context.Context, attributePath path.Path, restore types.Object) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if restore.IsNull() || restore.IsUnknown() {
+		return diags
+	}
+
+	var restoreModel model.YoshiK3SEtcdRestoreModel
+	diags.Append(restore.As(ctx, &restoreModel, basetypes.ObjectAsOptions{})...)
+	if diags.HasError() || restoreModel.Path.IsUnknown() || restoreModel.S3.IsUnknown() {
+		return diags
+	}
+
+	snapshotPath := restoreModel.Path.ValueString()
+	if restoreModel.S3.IsNull() && !strings.HasPrefix(snapshotPath, "/") {
+		diags.AddAttributeError(
+			attributePath.AtName("path"),
+			"Invalid snapshot path",
+			fmt.Sprintf("Without s3 the snapshot must be given as an absolute path on the node, got: %q.", snapshotPath),
+		)
+	}
+	if !restoreModel.S3.IsNull() && strings.Contains(snapshotPath, "/") {
+		diags.AddAttributeError(
+			attributePath.AtName("path"),
+			"Invalid snapshot name",
+			fmt.Sprintf("With s3 the snapshot must be given by its name, as listed by `k3s etcd-snapshot ls`, got: %q.", snapshotPath),
+		)
+	}
+
+	return diags
+}
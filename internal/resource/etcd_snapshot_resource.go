@@ -0,0 +1,263 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/executor"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/model"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/providerdata"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/remote"
+	"github.com/hashicorp/terraform-plugin-framework-validators/resourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &YoshiK3SEtcdSnapshotResource{}
+var _ resource.ResourceWithConfigure = &YoshiK3SEtcdSnapshotResource{}
+var _ resource.ResourceWithConfigValidators = &YoshiK3SEtcdSnapshotResource{}
+var _ resource.ResourceWithValidateConfig = &YoshiK3SEtcdSnapshotResource{}
+
+func NewYoshiK3SEtcdSnapshotResource() resource.Resource {
+	return &YoshiK3SEtcdSnapshotResource{}
+}
+
+// YoshiK3SEtcdSnapshotResource defines the resource implementation.
+type YoshiK3SEtcdSnapshotResource struct {
+	providerData *providerdata.Data
+}
+
+func (r *YoshiK3SEtcdSnapshotResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_etcd_snapshot"
+}
+
+func (r *YoshiK3SEtcdSnapshotResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "K3S etcd Snapshot Resource, takes an on-demand snapshot of the embedded etcd datastore of a master node.",
+
+		Version: model.YoshiK3SEtcdSnapshotResourceModelSchemaVersion,
+
+		Attributes: model.YoshiK3SEtcdSnapshotResourceModelSchema,
+	}
+}
+
+func (r *YoshiK3SEtcdSnapshotResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.providerData = configureProviderData(req.ProviderData, &resp.Diagnostics)
+}
+
+func (r *YoshiK3SEtcdSnapshotResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		resourcevalidator.ExactlyOneOf(
+			path.MatchRoot("node_connection").AtName("password"),
+			path.MatchRoot("node_connection").AtName("private_key"),
+		),
+	}
+}
+
+func (r *YoshiK3SEtcdSnapshotResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data model.YoshiK3SEtcdSnapshotResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(validateConnectionObject(ctx, path.Root("node_connection"), data.Connection)...)
+}
+
+func (r *YoshiK3SEtcdSnapshotResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = remote.WithCommandLogging(ctx, "yoshik3s_etcd_snapshot", "create")
+
+	var data model.YoshiK3SEtcdSnapshotResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sshConfig := sshConfigFromConnection(ctx, data.Connection)
+	ctx = withConnectionSettings(ctx, r.providerData, sshConfig, data.Connection)
+	if sshConfig == nil {
+		resp.Diagnostics.AddError(
+			"Failed to create an etcd snapshot",
+			"Invalid node configuration. Please check the node configuration.",
+		)
+		return
+	}
+
+	unlock, err := lockHosts(ctx, r.providerData, sshConfig)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to lock the node", err.Error())
+		return
+	}
+	defer unlock()
+	s3, diags := etcdS3OptionsFromModel(ctx, data.S3)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	exec := nodeExecutor(r.providerData)
+	snapshot, err := remote.SaveEtcdSnapshot(executor.Commands(ctx, exec, sshConfig), data.Name.ValueString(), data.Compress.ValueBool(), s3)
+	if isDryRun(exec) {
+		// The recorded snapshot cannot be found on the node.
+		addDryRunError(&resp.Diagnostics)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("failed to create an etcd snapshot", err.Error())
+		return
+	}
+
+	tflog.Trace(ctx, "created a resource", map[string]interface{}{
+		"snapshot": snapshot.Name,
+	})
+	data.Id = types.StringValue(snapshot.Name)
+	setEtcdSnapshotAttributes(&data, snapshot)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *YoshiK3SEtcdSnapshotResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = remote.WithCommandLogging(ctx, "yoshik3s_etcd_snapshot", "read")
+	ctx, cancel := withReadTimeout(ctx, r.providerData)
+	defer cancel()
+
+	var data model.YoshiK3SEtcdSnapshotResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sshConfig := sshConfigFromConnection(ctx, data.Connection)
+	ctx = withConnectionSettings(ctx, r.providerData, sshConfig, data.Connection)
+	s3, diags := etcdS3OptionsFromModel(ctx, data.S3)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() || sshConfig == nil {
+		return
+	}
+
+	snapshot, err := remote.FindEtcdSnapshot(executor.Commands(ctx, nodeExecutor(r.providerData), sshConfig), data.SnapshotName.ValueString(), s3)
+	if err != nil {
+		resp.Diagnostics.AddWarning(
+			"Failed to refresh etcd snapshot",
+			fmt.Sprintf("The etcd snapshots could not be listed, keeping the previous state: %s", err.Error()),
+		)
+		return
+	}
+	if snapshot == nil {
+		tflog.Warn(ctx, "etcd snapshot no longer exists, removing it from the state", map[string]interface{}{
+			"snapshot": data.SnapshotName.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	setEtcdSnapshotAttributes(&data, snapshot)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Update only stores the new connection details, every other attribute requires a replacement.
+func (r *YoshiK3SEtcdSnapshotResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data model.YoshiK3SEtcdSnapshotResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *YoshiK3SEtcdSnapshotResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = remote.WithCommandLogging(ctx, "yoshik3s_etcd_snapshot", "delete")
+
+	var data model.YoshiK3SEtcdSnapshotResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sshConfig := sshConfigFromConnection(ctx, data.Connection)
+	ctx = withConnectionSettings(ctx, r.providerData, sshConfig, data.Connection)
+	if sshConfig == nil {
+		resp.Diagnostics.AddError(
+			"Failed to delete an etcd snapshot",
+			"Invalid node configuration. Please check the node configuration.",
+		)
+		return
+	}
+
+	unlock, err := lockHosts(ctx, r.providerData, sshConfig)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to lock the node", err.Error())
+		return
+	}
+	defer unlock()
+	s3, diags := etcdS3OptionsFromModel(ctx, data.S3)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err = remote.DeleteEtcdSnapshot(executor.Commands(ctx, nodeExecutor(r.providerData), sshConfig), data.SnapshotName.ValueString(), s3)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to delete an etcd snapshot", err.Error())
+		return
+	}
+	if dryRunExecutor(r.providerData) != nil {
+		addDryRunError(&resp.Diagnostics)
+	}
+}
+
+func setEtcdSnapshotAttributes(data *model.YoshiK3SEtcdSnapshotResourceModel, snapshot *remote.EtcdSnapshot) {
+	data.SnapshotName = types.StringValue(snapshot.Name)
+	data.Path = types.StringValue(snapshot.Location)
+	data.Size = types.Int64Value(snapshot.Size)
+	data.CreatedAt = types.StringValue(snapshot.Created)
+}
+
+// etcdS3OptionsFromModel converts the s3 attribute, returning nil when snapshots are only kept on the node.
+func etcdS3OptionsFromModel(ctx context.Context, s3 types.Object) (*remote.EtcdS3Options, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if s3.IsNull() || s3.IsUnknown() {
+		return nil, diags
+	}
+
+	var s3Model model.YoshiK3SEtcdS3Model
+	diags.Append(s3.As(ctx, &s3Model, basetypes.ObjectAsOptions{})...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	return &remote.EtcdS3Options{
+		Endpoint:      s3Model.Endpoint.ValueString(),
+		Bucket:        s3Model.Bucket.ValueString(),
+		Folder:        s3Model.Folder.ValueString(),
+		Region:        s3Model.Region.ValueString(),
+		AccessKey:     s3Model.AccessKey.ValueString(),
+		SecretKey:     s3Model.SecretKey.ValueString(),
+		Insecure:      s3Model.Insecure.ValueBool(),
+		SkipSslVerify: s3Model.SkipSslVerify.ValueBool(),
+	}, diags
+}
@@ -0,0 +1,58 @@
+package resource
+
+import (
+	"context"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/executor"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/providerdata"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/remote"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/kubeconfig"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// nodeExecutor returns the executor shared by the provider, or the default one when the resource was not
+// configured by the provider or the provider was not given an executor.
+func nodeExecutor(providerData *providerdata.Data) executor.Executor {
+	if providerData == nil || providerData.Executor == nil {
+		return executor.NewYoshiK3SExecutor()
+	}
+
+	return providerData.Executor
+}
+
+// dryRunExecutor returns the executor recording the operations of the node resources during a dry run, or nil.
+func dryRunExecutor(providerData *providerdata.Data) *executor.DryRunExecutor {
+	dryRun, _ := nodeExecutor(providerData).(*executor.DryRunExecutor)
+	return dryRun
+}
+
+// isDryRun reports whether the executor only records the operations changing the hosts.
+func isDryRun(exec executor.Executor) bool {
+	_, dryRun := exec.(*executor.DryRunExecutor)
+	return dryRun
+}
+
+// addDryRunError fails an operation recorded by a dry run so that the state is left as it was: nothing is stored for
+// a failed create, and the prior state is kept by a failed update or delete.
+func addDryRunError(diags *diag.Diagnostics) {
+	diags.AddError(
+		"Dry run",
+		"The operations changing the hosts were recorded in the dry run transcript instead of being run, the state is "+
+			"left unchanged.",
+	)
+}
+
+// readKubeconfig reads the kubeconfig of a server node through the executor, pointing it to the cluster address.
+func readKubeconfig(ctx context.Context, exec executor.Executor, sshConfig *ssh_handler.SshConfig, clusterAddress string) ([]byte, error) {
+	content, err := exec.ReadFile(ctx, sshConfig, remote.K3sKubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := kubeconfig.UpdateServerAddress(&content, clusterAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	return *updated, nil
+}
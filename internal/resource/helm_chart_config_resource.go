@@ -0,0 +1,501 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/executor"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/model"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/providerdata"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/remote"
+	"github.com/hashicorp/terraform-plugin-framework-validators/resourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"strings"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &YoshiK3SHelmChartConfigResource{}
+var _ resource.ResourceWithConfigure = &YoshiK3SHelmChartConfigResource{}
+var _ resource.ResourceWithConfigValidators = &YoshiK3SHelmChartConfigResource{}
+var _ resource.ResourceWithValidateConfig = &YoshiK3SHelmChartConfigResource{}
+var _ resource.ResourceWithModifyPlan = &YoshiK3SHelmChartConfigResource{}
+
+// k3sBundledHelmCharts maps the components that can be disabled with `--disable` to the HelmCharts deploying them.
+var k3sBundledHelmCharts = map[string][]string{
+	"traefik": {"traefik", "traefik-crd"},
+}
+
+// k3sBundledManifests lists the components that K3S deploys as plain manifests, a HelmChartConfig has no effect on them.
+var k3sBundledManifests = map[string]bool{
+	"coredns":        true,
+	"local-storage":  true,
+	"metrics-server": true,
+}
+
+func NewYoshiK3SHelmChartConfigResource() resource.Resource {
+	return &YoshiK3SHelmChartConfigResource{}
+}
+
+// YoshiK3SHelmChartConfigResource defines the resource implementation.
+type YoshiK3SHelmChartConfigResource struct {
+	providerData *providerdata.Data
+}
+
+func (r *YoshiK3SHelmChartConfigResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_helm_chart_config"
+}
+
+func (r *YoshiK3SHelmChartConfigResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "K3S HelmChartConfig Resource, overrides the values of a HelmChart deployed by K3S, such as the bundled traefik.",
+
+		Version: model.YoshiK3SHelmChartConfigResourceModelSchemaVersion,
+
+		Attributes: model.YoshiK3SHelmChartConfigResourceModelSchema,
+	}
+}
+
+func (r *YoshiK3SHelmChartConfigResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.providerData = configureProviderData(req.ProviderData, &resp.Diagnostics)
+}
+
+func (r *YoshiK3SHelmChartConfigResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		resourcevalidator.ExactlyOneOf(
+			path.MatchRoot("node_connection").AtName("password"),
+			path.MatchRoot("node_connection").AtName("private_key"),
+		),
+		resourcevalidator.Conflicting(
+			path.MatchRoot("values"),
+			path.MatchRoot("values_yaml"),
+		),
+	}
+}
+
+func (r *YoshiK3SHelmChartConfigResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data model.YoshiK3SHelmChartConfigResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(validateConnectionObject(ctx, path.Root("node_connection"), data.Connection)...)
+	resp.Diagnostics.Append(validateKubernetesName(path.Root("chart_name"), data.ChartName)...)
+	resp.Diagnostics.Append(validateKubernetesName(path.Root("namespace"), data.Namespace)...)
+	resp.Diagnostics.Append(validateYamlContent(path.Root("values_yaml"), data.ValuesYaml)...)
+
+	if !data.ChartName.IsNull() && !data.ChartName.IsUnknown() && k3sBundledManifests[data.ChartName.ValueString()] {
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("chart_name"),
+			"Component not deployed by Helm",
+			fmt.Sprintf("K3S deploys %s as a plain manifest rather than a HelmChart, so a HelmChartConfig has no effect on it.", data.ChartName.ValueString()),
+		)
+	}
+}
+
+// ModifyPlan checks that the chart is not disabled on the master node and plans the checksum of the rendered
+// manifest, so a manifest modified on the node is planned as an update.
+func (r *YoshiK3SHelmChartConfigResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to plan when the resource is being destroyed.
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan model.YoshiK3SHelmChartConfigResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	connectionModel := parseConnectionModel(ctx, plan.Connection)
+	if address := connectionAddress(connectionModel); r.providerData != nil && address != "" && !plan.ChartName.IsUnknown() {
+		if options, found := r.providerData.Masters.Get(address); found {
+			resp.Diagnostics.Append(validateHelmChartEnabled(path.Root("chart_name"), plan.ChartName.ValueString(), options)...)
+		}
+	}
+
+	helmChartConfig, known, diags := helmChartConfigFromModel(plan)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() || !known {
+		return
+	}
+
+	content, err := helmChartConfig.Render()
+	if err != nil {
+		resp.Diagnostics.AddError("failed to render the HelmChartConfig", err.Error())
+		return
+	}
+
+	checksum := types.StringValue(remote.Checksum(content))
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("checksum"), checksum)...)
+}
+
+func (r *YoshiK3SHelmChartConfigResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = remote.WithCommandLogging(ctx, "yoshik3s_helm_chart_config", "create")
+
+	var data model.YoshiK3SHelmChartConfigResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sshConfig := sshConfigFromConnection(ctx, data.Connection)
+	ctx = withConnectionSettings(ctx, r.providerData, sshConfig, data.Connection)
+	if sshConfig == nil {
+		resp.Diagnostics.AddError(
+			"Failed to create a HelmChartConfig",
+			"Invalid node configuration. Please check the node configuration.",
+		)
+		return
+	}
+
+	unlock, err := lockHosts(ctx, r.providerData, sshConfig)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to lock the node", err.Error())
+		return
+	}
+	defer unlock()
+
+	helmChartConfig, content, diags := renderHelmChartConfig(data)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err = remote.WriteManifest(ctx, sshConfig, helmChartConfig.ManifestName(), content)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to create a HelmChartConfig", err.Error())
+		return
+	}
+
+	tflog.Trace(ctx, "created a resource", map[string]interface{}{
+		"chart": helmChartConfig.Metadata.Name,
+	})
+	data.Id = types.StringValue(helmChartConfig.Metadata.Namespace + "/" + helmChartConfig.Metadata.Name)
+	data.Path = types.StringValue(remote.ManifestPath(helmChartConfig.ManifestName()))
+	data.Checksum = types.StringValue(remote.Checksum(content))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *YoshiK3SHelmChartConfigResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = remote.WithCommandLogging(ctx, "yoshik3s_helm_chart_config", "read")
+	ctx, cancel := withReadTimeout(ctx, r.providerData)
+	defer cancel()
+
+	var data model.YoshiK3SHelmChartConfigResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sshConfig := sshConfigFromConnection(ctx, data.Connection)
+	ctx = withConnectionSettings(ctx, r.providerData, sshConfig, data.Connection)
+	if sshConfig == nil {
+		return
+	}
+
+	manifestName := remote.NewHelmChartConfig(data.ChartName.ValueString(), data.Namespace.ValueString(), "").ManifestName()
+	checksum, err := remote.ManifestChecksum(executor.Commands(ctx, nodeExecutor(r.providerData), sshConfig), manifestName)
+	if err != nil {
+		resp.Diagnostics.AddWarning(
+			"Failed to refresh HelmChartConfig",
+			fmt.Sprintf("The HelmChartConfig manifest could not be read, keeping the previous state: %s", err.Error()),
+		)
+		return
+	}
+	if checksum == "" {
+		tflog.Warn(ctx, "HelmChartConfig manifest no longer exists, removing it from the state", map[string]interface{}{
+			"chart": data.ChartName.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	data.Checksum = types.StringValue(checksum)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *YoshiK3SHelmChartConfigResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = remote.WithCommandLogging(ctx, "yoshik3s_helm_chart_config", "update")
+
+	var data model.YoshiK3SHelmChartConfigResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sshConfig := sshConfigFromConnection(ctx, data.Connection)
+	ctx = withConnectionSettings(ctx, r.providerData, sshConfig, data.Connection)
+	if sshConfig == nil {
+		resp.Diagnostics.AddError(
+			"Failed to update a HelmChartConfig",
+			"Invalid node configuration. Please check the node configuration.",
+		)
+		return
+	}
+
+	unlock, err := lockHosts(ctx, r.providerData, sshConfig)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to lock the node", err.Error())
+		return
+	}
+	defer unlock()
+
+	helmChartConfig, content, diags := renderHelmChartConfig(data)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err = remote.WriteManifest(ctx, sshConfig, helmChartConfig.ManifestName(), content)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to update a HelmChartConfig", err.Error())
+		return
+	}
+	data.Checksum = types.StringValue(remote.Checksum(content))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *YoshiK3SHelmChartConfigResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = remote.WithCommandLogging(ctx, "yoshik3s_helm_chart_config", "delete")
+
+	var data model.YoshiK3SHelmChartConfigResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sshConfig := sshConfigFromConnection(ctx, data.Connection)
+	ctx = withConnectionSettings(ctx, r.providerData, sshConfig, data.Connection)
+	if sshConfig == nil {
+		resp.Diagnostics.AddError(
+			"Failed to delete a HelmChartConfig",
+			"Invalid node configuration. Please check the node configuration.",
+		)
+		return
+	}
+
+	unlock, err := lockHosts(ctx, r.providerData, sshConfig)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to lock the node", err.Error())
+		return
+	}
+	defer unlock()
+
+	manifestName := remote.NewHelmChartConfig(data.ChartName.ValueString(), data.Namespace.ValueString(), "").ManifestName()
+	err = remote.RemoveManifest(ctx, sshConfig, manifestName)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to delete a HelmChartConfig", err.Error())
+		return
+	}
+}
+
+// renderHelmChartConfig returns the HelmChartConfig of the model together with its rendered manifest.
+func renderHelmChartConfig(data model.YoshiK3SHelmChartConfigResourceModel) (*remote.HelmChartConfig, []byte, diag.Diagnostics) {
+	helmChartConfig, _, diags := helmChartConfigFromModel(data)
+	if diags.HasError() {
+		return nil, nil, diags
+	}
+
+	content, err := helmChartConfig.Render()
+	if err != nil {
+		diags.AddError("failed to render the HelmChartConfig", err.Error())
+		return nil, nil, diags
+	}
+
+	return helmChartConfig, content, diags
+}
+
+// helmChartConfigFromModel converts the model into a HelmChartConfig, reporting whether every value is known.
+func helmChartConfigFromModel(data model.YoshiK3SHelmChartConfigResourceModel) (*remote.HelmChartConfig, bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if data.ChartName.IsUnknown() || data.Namespace.IsUnknown() || data.ValuesYaml.IsUnknown() || data.Values.IsUnderlyingValueUnknown() {
+		return nil, false, diags
+	}
+
+	valuesContent := data.ValuesYaml.ValueString()
+	if !data.Values.IsNull() && !data.Values.IsUnderlyingValueNull() {
+		values, err := dynamicToYamlValue(data.Values.UnderlyingValue())
+		if err != nil {
+			diags.AddAttributeError(path.Root("values"), "Invalid values", err.Error())
+			return nil, false, diags
+		}
+
+		content, err := remote.MarshalYaml(values)
+		if err != nil {
+			diags.AddAttributeError(path.Root("values"), "Invalid values", err.Error())
+			return nil, false, diags
+		}
+		valuesContent = string(content)
+	}
+
+	helmChartConfig := remote.NewHelmChartConfig(data.ChartName.ValueString(), data.Namespace.ValueString(), valuesContent)
+	return &helmChartConfig, true, diags
+}
+
+// dynamicToYamlValue converts a Terraform value into the equivalent value encoded by the YAML package.
+func dynamicToYamlValue(value attr.Value) (interface{}, error) {
+	if value == nil || value.IsNull() {
+		return nil, nil
+	}
+	if value.IsUnknown() {
+		return nil, fmt.Errorf("the values must be known")
+	}
+
+	switch v := value.(type) {
+	case basetypes.DynamicValue:
+		return dynamicToYamlValue(v.UnderlyingValue())
+	case basetypes.StringValue:
+		return v.ValueString(), nil
+	case basetypes.BoolValue:
+		return v.ValueBool(), nil
+	case basetypes.Int64Value:
+		return v.ValueInt64(), nil
+	case basetypes.Float64Value:
+		return v.ValueFloat64(), nil
+	case basetypes.NumberValue:
+		number := v.ValueBigFloat()
+		if number.IsInt() {
+			if integer, accuracy := number.Int64(); accuracy == 0 {
+				return integer, nil
+			}
+		}
+		float, _ := number.Float64()
+		return float, nil
+	case basetypes.ListValue:
+		return elementsToYamlValue(v.Elements())
+	case basetypes.SetValue:
+		return elementsToYamlValue(v.Elements())
+	case basetypes.TupleValue:
+		return elementsToYamlValue(v.Elements())
+	case basetypes.MapValue:
+		return attributesToYamlValue(v.Elements())
+	case basetypes.ObjectValue:
+		return attributesToYamlValue(v.Attributes())
+	default:
+		return nil, fmt.Errorf("unsupported value type %s", value.Type(context.Background()))
+	}
+}
+
+func elementsToYamlValue(elements []attr.Value) (interface{}, error) {
+	values := make([]interface{}, 0, len(elements))
+	for _, element := range elements {
+		value, err := dynamicToYamlValue(element)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+func attributesToYamlValue(attributes map[string]attr.Value) (interface{}, error) {
+	values := make(map[string]interface{}, len(attributes))
+	for key, attribute := range attributes {
+		value, err := dynamicToYamlValue(attribute)
+		if err != nil {
+			return nil, err
+		}
+		values[key] = value
+	}
+
+	return values, nil
+}
+
+// validateHelmChartEnabled warns when the chart is disabled by the node options of the master node. The check is
+// advisory: it only sees the master nodes registered during the run, and not the options set outside of them.
+func validateHelmChartEnabled(attributePath path.Path, chartName string, options []string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	for _, option := range options {
+		flag, ok := parseNodeOptionFlag(option)
+		if !ok {
+			continue
+		}
+
+		if flag == "disable-helm-controller" {
+			diags.AddAttributeWarning(
+				attributePath,
+				"Helm controller disabled",
+				"The master node disables the K3S Helm controller with --disable-helm-controller, so HelmChartConfigs are not applied.",
+			)
+			return diags
+		}
+		if flag != "disable" {
+			continue
+		}
+
+		for _, component := range nodeOptionValues(option) {
+			charts, bundled := k3sBundledHelmCharts[component]
+			if !bundled {
+				charts = []string{component}
+			}
+
+			for _, chart := range charts {
+				if chart == chartName {
+					diags.AddAttributeWarning(
+						attributePath,
+						"Chart disabled",
+						fmt.Sprintf("The chart %q is not deployed since the master node disables %s through its node options.", chartName, component),
+					)
+					return diags
+				}
+			}
+		}
+	}
+
+	return diags
+}
+
+// nodeOptionValues returns the comma separated values of options such as "--disable traefik,servicelb".
+func nodeOptionValues(option string) []string {
+	fields := strings.Fields(option)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	var value string
+	if _, inline, found := strings.Cut(fields[0], "="); found {
+		value = inline
+	} else if len(fields) > 1 {
+		value = fields[1]
+	}
+
+	var values []string
+	for _, element := range strings.Split(strings.Trim(value, `"'`), ",") {
+		if element = strings.TrimSpace(element); element != "" {
+			values = append(values, element)
+		}
+	}
+
+	return values
+}
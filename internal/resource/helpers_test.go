@@ -0,0 +1,204 @@
+package resource
+
+import (
+	"context"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/model"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/remote"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/sshtest"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/identityschema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"strings"
+	"testing"
+)
+
+const (
+	testClusterToken   = "cluster-secret"
+	testClusterAddress = "10.0.0.1"
+	testK3sVersion     = "v1.30.2+k3s2"
+)
+
+// testKubeconfig is the kubeconfig written by K3S on a server node.
+const testKubeconfig = `apiVersion: v1
+clusters:
+- cluster:
+    certificate-authority-data: Y2VydGlmaWNhdGU=
+    server: https://127.0.0.1:6443
+  name: default
+contexts:
+- context:
+    cluster: default
+    user: default
+  name: default
+current-context: default
+kind: Config
+preferences: {}
+users:
+- name: default
+  user:
+    client-certificate-data: Y2VydGlmaWNhdGU=
+    client-key-data: a2V5
+`
+
+// newK3sServer starts an SSH server answering the commands run on a host where K3S was installed with the
+// given role by the install script.
+func newK3sServer(t *testing.T, role remote.NodeRole) *sshtest.Server {
+	t.Helper()
+
+	server := sshtest.NewServer(t)
+	server.Respond(`^if \[ -f /etc/systemd/system/k3s.service \]`, string(role)+"\n")
+	server.Respond(`^k3s --version$`, "k3s version "+testK3sVersion+" (a4f0ee1a)\ngo version go1.22.4\n")
+	server.Respond(`cat \$HOME/.kube/config$`, testKubeconfig)
+	server.Respond(`^cat '?/etc/rancher/k3s/k3s\.yaml'?$`, testKubeconfig)
+
+	if role == remote.NodeRoleServer {
+		server.Respond(`^cat /etc/systemd/system/k3s.service$`, "[Service]\nExecStart=/usr/local/bin/k3s \\\n    server \\\n\t'--tls-san' \\\n\t'"+testClusterAddress+"' \\\n\t'--disable' \\\n\t'traefik' \\\n")
+		server.Respond(`cat /etc/systemd/system/k3s.service.env$`, "K3S_TOKEN='"+testClusterToken+"'\n")
+	} else {
+		server.Respond(`^cat /etc/systemd/system/k3s-agent.service$`, "[Service]\nExecStart=/usr/local/bin/k3s \\\n    agent \\\n")
+		server.Respond(`cat /etc/systemd/system/k3s-agent.service.env$`, "K3S_TOKEN='"+testClusterToken+"'\nK3S_URL='https://"+testClusterAddress+":6443'\n")
+	}
+
+	return server
+}
+
+func testConnection(server *sshtest.Server) types.Object {
+	return types.ObjectValueMust(model.YoshiK3SConnectionModelAttributeTypes, map[string]attr.Value{
+		"host":                   types.StringValue(server.Host()),
+		"port":                   types.StringValue(server.Port()),
+		"user":                   types.StringValue(server.User),
+		"password":               types.StringValue(server.Password),
+		"private_key":            types.StringNull(),
+		"private_key_passphrase": types.StringNull(),
+		"connection_retry":       types.ObjectNull(model.YoshiK3SConnectionRetryModelAttributeTypes),
+		"become":                 types.ObjectNull(model.YoshiK3SBecomeModelAttributeTypes),
+	})
+}
+
+func testCluster(agentToken types.String) types.Object {
+	return types.ObjectValueMust(model.YoshiK3SNodeClusterAttributeTypes, map[string]attr.Value{
+		"id":                    types.StringValue("example-cluster"),
+		"name":                  types.StringValue("example-cluster"),
+		"token":                 types.StringValue(testClusterToken),
+		"agent_token":           agentToken,
+		"address":               types.StringValue(testClusterAddress),
+		"k3s_version":           types.StringValue(testK3sVersion),
+		"certificate_authority": types.ObjectNull(model.YoshiK3SCertificateAuthorityAttributeTypes),
+	})
+}
+
+// testWorkerCluster is the cluster attribute of the worker nodes, which has no certificate authorities.
+func testWorkerCluster(agentToken types.String) types.Object {
+	return types.ObjectValueMust(model.YoshiK3SWorkerNodeClusterAttributeTypes, map[string]attr.Value{
+		"id":          types.StringValue("example-cluster"),
+		"name":        types.StringValue("example-cluster"),
+		"token":       types.StringValue(testClusterToken),
+		"agent_token": agentToken,
+		"address":     types.StringValue(testClusterAddress),
+		"k3s_version": types.StringValue(testK3sVersion),
+	})
+}
+
+func testOptions(options ...string) types.List {
+	values := make([]attr.Value, 0, len(options))
+	for _, option := range options {
+		values = append(values, types.StringValue(option))
+	}
+
+	return types.ListValueMust(types.StringType, values)
+}
+
+func resourceSchema(t *testing.T, r resource.Resource) schema.Schema {
+	t.Helper()
+
+	resp := &resource.SchemaResponse{}
+	r.Schema(context.Background(), resource.SchemaRequest{}, resp)
+	requireNoErrors(t, resp.Diagnostics)
+
+	return resp.Schema
+}
+
+func resourceIdentitySchema(t *testing.T, r resource.ResourceWithIdentity) identityschema.Schema {
+	t.Helper()
+
+	resp := &resource.IdentitySchemaResponse{}
+	r.IdentitySchema(context.Background(), resource.IdentitySchemaRequest{}, resp)
+	requireNoErrors(t, resp.Diagnostics)
+
+	return resp.IdentitySchema
+}
+
+// newRaw builds a value of the schema type holding the given attributes, the remaining ones are null.
+func newRaw(t *testing.T, s schema.Schema, attributes map[string]attr.Value) tftypes.Value {
+	t.Helper()
+	ctx := context.Background()
+
+	state := tfsdk.State{
+		Schema: s,
+		Raw:    tftypes.NewValue(s.Type().TerraformType(ctx), nil),
+	}
+	for name, value := range attributes {
+		requireNoErrors(t, state.SetAttribute(ctx, path.Root(name), value))
+	}
+
+	return state.Raw
+}
+
+func newPlan(t *testing.T, s schema.Schema, attributes map[string]attr.Value) tfsdk.Plan {
+	t.Helper()
+
+	return tfsdk.Plan{Schema: s, Raw: newRaw(t, s, attributes)}
+}
+
+func newState(t *testing.T, s schema.Schema, attributes map[string]attr.Value) tfsdk.State {
+	t.Helper()
+
+	return tfsdk.State{Schema: s, Raw: newRaw(t, s, attributes)}
+}
+
+func emptyState(s schema.Schema) tfsdk.State {
+	return tfsdk.State{Schema: s, Raw: tftypes.NewValue(s.Type().TerraformType(context.Background()), nil)}
+}
+
+func emptyIdentity(s identityschema.Schema) *tfsdk.ResourceIdentity {
+	return &tfsdk.ResourceIdentity{Schema: s, Raw: tftypes.NewValue(s.Type().TerraformType(context.Background()), nil)}
+}
+
+func getStringAttribute(t *testing.T, state tfsdk.State, attributePath path.Path) types.String {
+	t.Helper()
+
+	var value types.String
+	requireNoErrors(t, state.GetAttribute(context.Background(), attributePath, &value))
+
+	return value
+}
+
+func requireNoErrors(t *testing.T, diags diag.Diagnostics) {
+	t.Helper()
+
+	if diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+}
+
+// requireCommand fails the test unless exactly one received command matches the pattern, and returns it.
+func requireCommand(t *testing.T, server *sshtest.Server, pattern string) sshtest.Command {
+	t.Helper()
+
+	received := server.Received(pattern)
+	if len(received) != 1 {
+		var commands []string
+		for _, command := range server.Commands() {
+			commands = append(commands, command.Command)
+		}
+		t.Fatalf("expected one command matching %q, got %d in:\n%s", pattern, len(received), strings.Join(commands, "\n"))
+	}
+
+	return received[0]
+}
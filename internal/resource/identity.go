@@ -0,0 +1,43 @@
+package resource
+
+import (
+	"context"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/model"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"net"
+)
+
+// nodeId returns the id of a node resource, the host and port of its connection, since several nodes may share a
+// host through different SSH ports.
+func nodeId(sshConfig *ssh_handler.SshConfig) string {
+	return net.JoinHostPort(sshConfig.GetHost(), sshConfig.GetPort())
+}
+
+// nodeIdentity builds the identity of a node resource from its cluster and connection attributes.
+func nodeIdentity(ctx context.Context, cluster types.Object, connection types.Object) (model.YoshiK3SNodeIdentityModel, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	identity := model.YoshiK3SNodeIdentityModel{
+		Host:        types.StringNull(),
+		Port:        types.StringNull(),
+		ClusterName: types.StringNull(),
+	}
+
+	if !connection.IsNull() && !connection.IsUnknown() {
+		var connectionModel model.YoshiK3SConnectionModel
+		diags.Append(connection.As(ctx, &connectionModel, basetypes.ObjectAsOptions{})...)
+		identity.Host = connectionModel.Host
+		identity.Port = connectionModel.Port
+	}
+
+	if !cluster.IsNull() && !cluster.IsUnknown() {
+		clusterModel, clusterDiags := parseClusterObject(ctx, cluster)
+		diags.Append(clusterDiags...)
+		identity.ClusterName = clusterModel.ClusterName
+	}
+
+	return identity, diags
+}
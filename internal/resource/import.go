@@ -0,0 +1,307 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/executor"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/model"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/remote"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"net"
+	"os"
+	"strings"
+)
+
+// Environment variables holding the SSH credentials used while importing, since
+// the import ID cannot carry secrets.
+const (
+	importUserEnvVar                 = "YOSHIK3S_SSH_USER"
+	importPasswordEnvVar             = "YOSHIK3S_SSH_PASSWORD"
+	importPrivateKeyEnvVar           = "YOSHIK3S_SSH_PRIVATE_KEY"
+	importPrivateKeyPassphraseEnvVar = "YOSHIK3S_SSH_PRIVATE_KEY_PASSPHRASE"
+
+	importBecomeMethodEnvVar   = "YOSHIK3S_BECOME_METHOD"
+	importBecomePasswordEnvVar = "YOSHIK3S_BECOME_PASSWORD"
+	importBecomeUserEnvVar     = "YOSHIK3S_BECOME_USER"
+)
+
+const defaultSshPort = "22"
+
+// nodeImportId is the parsed form of the import ID `<user>@<host>[:<port>][/<cluster_name>]`.
+type nodeImportId struct {
+	User        string
+	Host        string
+	Port        string
+	ClusterName string
+}
+
+// address returns the host and port of the import ID, which is the id of the imported node.
+func (i nodeImportId) address() string {
+	return net.JoinHostPort(i.Host, i.Port)
+}
+
+func parseNodeImportId(id string) (*nodeImportId, error) {
+	address, clusterName, _ := strings.Cut(id, "/")
+
+	user, hostPort, found := strings.Cut(address, "@")
+	if !found || user == "" || hostPort == "" {
+		return nil, fmt.Errorf("expected an import ID in the format <user>@<host>[:<port>][/<cluster_name>], got: %q", id)
+	}
+
+	host, port, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		host, port = strings.Trim(hostPort, "[]"), defaultSshPort
+	}
+	if !isValidAddress(host) {
+		return nil, fmt.Errorf("the host %q of the import ID is not a valid IP address or host name", host)
+	}
+
+	return &nodeImportId{
+		User:        user,
+		Host:        host,
+		Port:        port,
+		ClusterName: clusterName,
+	}, nil
+}
+
+// nodeImportIdFromRequest parses the import ID or, when importing through an import block with
+// identity, builds it from the identity and the SSH user set in the environment.
+func nodeImportIdFromRequest(ctx context.Context, req resource.ImportStateRequest) (*nodeImportId, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if req.ID != "" || req.Identity == nil {
+		importId, err := parseNodeImportId(req.ID)
+		if err != nil {
+			diags.AddError("Invalid import ID", err.Error())
+		}
+		return importId, diags
+	}
+
+	var identity model.YoshiK3SNodeIdentityModel
+	diags.Append(req.Identity.Get(ctx, &identity)...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	user := os.Getenv(importUserEnvVar)
+	if user == "" {
+		diags.AddError(
+			"Missing SSH user",
+			fmt.Sprintf("Set %s to import a node by its identity.", importUserEnvVar),
+		)
+		return nil, diags
+	}
+
+	importId := &nodeImportId{
+		User:        user,
+		Host:        identity.Host.ValueString(),
+		Port:        identity.Port.ValueString(),
+		ClusterName: identity.ClusterName.ValueString(),
+	}
+	if importId.Port == "" {
+		importId.Port = defaultSshPort
+	}
+
+	return importId, diags
+}
+
+// connectionObject builds the node_connection attribute, reading the credentials from the environment.
+func (i nodeImportId) connectionObject() (types.Object, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	password := optionalStringFromEnv(importPasswordEnvVar)
+	privateKey := optionalStringFromEnv(importPrivateKeyEnvVar)
+	if password.IsNull() && privateKey.IsNull() {
+		diags.AddError(
+			"Missing SSH credentials",
+			fmt.Sprintf("Set either %s or %s to import a node.", importPasswordEnvVar, importPrivateKeyEnvVar),
+		)
+		return types.ObjectNull(model.YoshiK3SConnectionModelAttributeTypes), diags
+	}
+
+	connection, connectionDiags := types.ObjectValueFrom(
+		context.Background(),
+		model.YoshiK3SConnectionModelAttributeTypes,
+		model.YoshiK3SConnectionModel{
+			Host:                 types.StringValue(i.Host),
+			Port:                 types.StringValue(i.Port),
+			User:                 types.StringValue(i.User),
+			Password:             password,
+			PrivateKey:           privateKey,
+			PrivateKeyPassphrase: optionalStringFromEnv(importPrivateKeyPassphraseEnvVar),
+			ConnectionRetry:      types.ObjectNull(model.YoshiK3SConnectionRetryModelAttributeTypes),
+			Become:               becomeObjectFromEnv(),
+		},
+	)
+	diags.Append(connectionDiags...)
+
+	return connection, diags
+}
+
+// sshConfig builds the connection config used to discover the cluster from one of its master nodes.
+func (i nodeImportId) sshConfig() *ssh_handler.SshConfig {
+	return ssh_handler.NewSshConfig(
+		i.Host,
+		i.Port,
+		i.User,
+		os.Getenv(importPasswordEnvVar),
+		os.Getenv(importPrivateKeyEnvVar),
+		os.Getenv(importPrivateKeyPassphraseEnvVar),
+	)
+}
+
+// clusterObject builds a cluster attribute, with the attribute types of the node schema, holding only the cluster
+// name, the remaining attributes are discovered on Read.
+func (i nodeImportId) clusterObject(attributeTypes map[string]attr.Type) types.Object {
+	clusterName := types.StringNull()
+	if i.ClusterName != "" {
+		clusterName = types.StringValue(i.ClusterName)
+	}
+
+	attributes := map[string]attr.Value{
+		"id":          clusterName,
+		"name":        clusterName,
+		"token":       types.StringNull(),
+		"agent_token": types.StringNull(),
+		"address":     types.StringNull(),
+		"k3s_version": types.StringNull(),
+	}
+	if _, found := attributeTypes["certificate_authority"]; found {
+		attributes["certificate_authority"] = types.ObjectNull(model.YoshiK3SCertificateAuthorityAttributeTypes)
+	}
+
+	return types.ObjectValueMust(attributeTypes, attributes)
+}
+
+// isPendingDiscovery reports whether the node state was imported and still lacks the cluster details.
+func isPendingDiscovery(ctx context.Context, cluster types.Object) bool {
+	if cluster.IsNull() || cluster.IsUnknown() {
+		return true
+	}
+
+	clusterModel, diags := parseClusterObject(ctx, cluster)
+	if diags.HasError() {
+		return true
+	}
+
+	return clusterModel.ClusterToken.IsNull() && clusterModel.AgentToken.IsNull()
+}
+
+// mergeDiscoveredCluster fills the cluster attributes missing from the state with the ones found on the host.
+// The K3S version is always refreshed when it is set, so manual upgrades show up as drift.
+func mergeDiscoveredCluster(ctx context.Context, cluster types.Object, nodeInfo *remote.NodeInfo) (types.Object, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	clusterModel := model.YoshiK3SClusterResourceModel{
+		Id:             types.StringNull(),
+		ClusterName:    types.StringNull(),
+		ClusterToken:   types.StringNull(),
+		AgentToken:     types.StringNull(),
+		ClusterAddress: types.StringNull(),
+		ClusterVersion: types.StringNull(),
+
+		CertificateAuthority: types.ObjectNull(model.YoshiK3SCertificateAuthorityAttributeTypes),
+	}
+	if !cluster.IsNull() && !cluster.IsUnknown() {
+		clusterModel, diags = parseClusterObject(ctx, cluster)
+		if diags.HasError() {
+			return cluster, diags
+		}
+	}
+
+	// The worker nodes may join with the agent token alone, the token is then not discovered.
+	pendingDiscovery := clusterModel.ClusterToken.IsNull() && clusterModel.AgentToken.IsNull()
+
+	if pendingDiscovery && nodeInfo.Token != "" {
+		clusterModel.ClusterToken = types.StringValue(nodeInfo.Token)
+	}
+	if clusterModel.ClusterAddress.IsNull() && nodeInfo.ServerAddress != "" {
+		clusterModel.ClusterAddress = types.StringValue(nodeInfo.ServerAddress)
+	}
+	if (pendingDiscovery || !clusterModel.ClusterVersion.IsNull()) && nodeInfo.Version != "" {
+		clusterModel.ClusterVersion = types.StringValue(nodeInfo.Version)
+	}
+
+	merged, mergeDiags := clusterObjectValue(ctx, cluster.AttributeTypes(ctx), clusterModel)
+	diags.Append(mergeDiags...)
+
+	return merged, diags
+}
+
+func optionalStringFromEnv(key string) types.String {
+	value, found := os.LookupEnv(key)
+	if !found || value == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(value)
+}
+
+// becomeObjectFromEnv builds the become attribute of an imported node from the environment, it is null when no
+// become setting is set.
+func becomeObjectFromEnv() types.Object {
+	become := model.YoshiK3SBecomeModel{
+		Method:   optionalStringFromEnv(importBecomeMethodEnvVar),
+		Password: optionalStringFromEnv(importBecomePasswordEnvVar),
+		User:     optionalStringFromEnv(importBecomeUserEnvVar),
+	}
+	if become.Method.IsNull() && become.Password.IsNull() && become.User.IsNull() {
+		return types.ObjectNull(model.YoshiK3SBecomeModelAttributeTypes)
+	}
+
+	return types.ObjectValueMust(model.YoshiK3SBecomeModelAttributeTypes, map[string]attr.Value{
+		"method":   become.Method,
+		"password": become.Password,
+		"user":     become.User,
+	})
+}
+
+// discoverNodeOnRead inspects the K3S installation of the node. It returns nil when the state should
+// be kept unchanged, either because no credentials are available or because the host could not be
+// reached outside an import, in which case a warning is emitted instead of failing the refresh.
+func discoverNodeOnRead(ctx context.Context, exec executor.Executor, sshConfig *ssh_handler.SshConfig, cluster types.Object, expectedRole remote.NodeRole, diags *diag.Diagnostics) *remote.NodeInfo {
+	pendingDiscovery := isPendingDiscovery(ctx, cluster)
+
+	if sshConfig == nil || sshConfig.IsValid() != nil {
+		if pendingDiscovery {
+			diags.AddError(
+				"Failed to read node",
+				"The node connection has no credentials, the K3S installation could not be discovered.",
+			)
+		}
+		return nil
+	}
+
+	nodeInfo, err := exec.Status(ctx, sshConfig)
+	if err != nil {
+		if pendingDiscovery {
+			diags.AddError("Failed to read node", err.Error())
+		} else {
+			diags.AddWarning(
+				"Failed to refresh node",
+				fmt.Sprintf("The K3S installation could not be inspected, keeping the previous state: %s", err.Error()),
+			)
+		}
+		return nil
+	}
+
+	if nodeInfo.Role != remote.NodeRoleNone && nodeInfo.Role != expectedRole {
+		diags.AddError(
+			"Failed to read node",
+			fmt.Sprintf("Expected a K3S %s installation on %s, found a K3S %s.", expectedRole, sshConfig.GetHost(), nodeInfo.Role),
+		)
+		return nil
+	}
+
+	tflog.Debug(ctx, "discovered k3s installation", map[string]interface{}{
+		"host":    sshConfig.GetHost(),
+		"role":    string(nodeInfo.Role),
+		"version": nodeInfo.Version,
+	})
+
+	return nodeInfo
+}
@@ -0,0 +1,281 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/model"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/providerdata"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/remote"
+	"github.com/hashicorp/terraform-plugin-framework-validators/resourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"time"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &YoshiK3SJoinTokenResource{}
+var _ resource.ResourceWithConfigure = &YoshiK3SJoinTokenResource{}
+var _ resource.ResourceWithConfigValidators = &YoshiK3SJoinTokenResource{}
+var _ resource.ResourceWithValidateConfig = &YoshiK3SJoinTokenResource{}
+
+// joinTokenUsages lists the usages accepted by `k3s token create`.
+var joinTokenUsages = map[string]bool{
+	"signing":        true,
+	"authentication": true,
+}
+
+func NewYoshiK3SJoinTokenResource() resource.Resource {
+	return &YoshiK3SJoinTokenResource{}
+}
+
+// YoshiK3SJoinTokenResource defines the resource implementation.
+type YoshiK3SJoinTokenResource struct {
+	providerData *providerdata.Data
+}
+
+func (r *YoshiK3SJoinTokenResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_join_token"
+}
+
+func (r *YoshiK3SJoinTokenResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "K3S Join Token Resource, creates a short-lived bootstrap token on a master node with `k3s token create`.",
+
+		Version: model.YoshiK3SJoinTokenResourceModelSchemaVersion,
+
+		Attributes: model.YoshiK3SJoinTokenResourceModelSchema,
+	}
+}
+
+func (r *YoshiK3SJoinTokenResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.providerData = configureProviderData(req.ProviderData, &resp.Diagnostics)
+}
+
+func (r *YoshiK3SJoinTokenResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		resourcevalidator.ExactlyOneOf(
+			path.MatchRoot("node_connection").AtName("password"),
+			path.MatchRoot("node_connection").AtName("private_key"),
+		),
+	}
+}
+
+func (r *YoshiK3SJoinTokenResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data model.YoshiK3SJoinTokenResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(validateConnectionObject(ctx, path.Root("node_connection"), data.Connection)...)
+	resp.Diagnostics.Append(validateJoinTokenTtl(path.Root("ttl"), data.Ttl)...)
+	resp.Diagnostics.Append(validateJoinTokenUsages(ctx, path.Root("usages"), data.Usages)...)
+}
+
+func (r *YoshiK3SJoinTokenResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = remote.WithCommandLogging(ctx, "yoshik3s_join_token", "create")
+
+	var data model.YoshiK3SJoinTokenResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sshConfig := sshConfigFromConnection(ctx, data.Connection)
+	ctx = withConnectionSettings(ctx, r.providerData, sshConfig, data.Connection)
+	if sshConfig == nil {
+		resp.Diagnostics.AddError(
+			"Failed to create a join token",
+			"Invalid node configuration. Please check the node configuration.",
+		)
+		return
+	}
+
+	unlock, err := lockHosts(ctx, r.providerData, sshConfig)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to lock the node", err.Error())
+		return
+	}
+	defer unlock()
+
+	var usages []string
+	if !data.Usages.IsNull() {
+		resp.Diagnostics.Append(data.Usages.ElementsAs(ctx, &usages, false)...)
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	token, err := remote.CreateJoinToken(ctx, sshConfig, remote.JoinTokenOptions{
+		Ttl:         data.Ttl.ValueString(),
+		Description: data.Description.ValueString(),
+		Usages:      usages,
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("failed to create a join token", err.Error())
+		return
+	}
+
+	tflog.Trace(ctx, "created a resource", map[string]interface{}{
+		"id": remote.ParseJoinTokenId(token),
+	})
+	data.Id = types.StringValue(remote.ParseJoinTokenId(token))
+	data.Token = types.StringValue(token)
+	data.Expired = types.BoolValue(false)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *YoshiK3SJoinTokenResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = remote.WithCommandLogging(ctx, "yoshik3s_join_token", "read")
+	ctx, cancel := withReadTimeout(ctx, r.providerData)
+	defer cancel()
+
+	var data model.YoshiK3SJoinTokenResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sshConfig := sshConfigFromConnection(ctx, data.Connection)
+	ctx = withConnectionSettings(ctx, r.providerData, sshConfig, data.Connection)
+	if sshConfig == nil {
+		return
+	}
+
+	exists, err := remote.JoinTokenExists(ctx, sshConfig, data.Id.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddWarning(
+			"Failed to refresh join token",
+			fmt.Sprintf("The join tokens could not be listed, keeping the previous state: %s", err.Error()),
+		)
+		return
+	}
+	// An expired token is kept in the state, removing it would plan a new token and update the worker nodes using it.
+	if !exists {
+		tflog.Warn(ctx, "join token expired or was deleted", map[string]interface{}{
+			"id": data.Id.ValueString(),
+		})
+		resp.Diagnostics.AddWarning(
+			"Join token expired",
+			fmt.Sprintf("The join token %q expired or was deleted from the master node, change renew to create a new one "+
+				"before joining more worker nodes with it.", data.Id.ValueString()),
+		)
+	}
+	data.Expired = types.BoolValue(!exists)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Update only stores the new connection details, every other attribute requires a replacement.
+func (r *YoshiK3SJoinTokenResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data model.YoshiK3SJoinTokenResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *YoshiK3SJoinTokenResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = remote.WithCommandLogging(ctx, "yoshik3s_join_token", "delete")
+
+	var data model.YoshiK3SJoinTokenResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sshConfig := sshConfigFromConnection(ctx, data.Connection)
+	ctx = withConnectionSettings(ctx, r.providerData, sshConfig, data.Connection)
+	if sshConfig == nil {
+		resp.Diagnostics.AddError(
+			"Failed to delete a join token",
+			"Invalid node configuration. Please check the node configuration.",
+		)
+		return
+	}
+
+	unlock, err := lockHosts(ctx, r.providerData, sshConfig)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to lock the node", err.Error())
+		return
+	}
+	defer unlock()
+
+	err = remote.DeleteJoinToken(ctx, sshConfig, data.Id.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("failed to delete a join token", err.Error())
+		return
+	}
+}
+
+// validateJoinTokenTtl checks that the value is a duration accepted by `k3s token create --ttl`.
+func validateJoinTokenTtl(attributePath path.Path, value types.String) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if value.IsNull() || value.IsUnknown() {
+		return diags
+	}
+
+	ttl, err := time.ParseDuration(value.ValueString())
+	if err != nil || ttl < 0 {
+		diags.AddAttributeError(
+			attributePath,
+			"Invalid token TTL",
+			fmt.Sprintf("The TTL must be a non-negative duration, e.g. 1h or 24h, got: %q.", value.ValueString()),
+		)
+	}
+
+	return diags
+}
+
+// validateJoinTokenUsages checks that every usage is accepted by `k3s token create --usages`.
+func validateJoinTokenUsages(ctx context.Context, attributePath path.Path, usages types.List) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if usages.IsNull() || usages.IsUnknown() {
+		return diags
+	}
+
+	elements := make([]types.String, 0, len(usages.Elements()))
+	diags.Append(usages.ElementsAs(ctx, &elements, true)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	for index, element := range elements {
+		if element.IsNull() || element.IsUnknown() {
+			continue
+		}
+
+		if !joinTokenUsages[element.ValueString()] {
+			diags.AddAttributeError(
+				attributePath.AtListIndex(index),
+				"Invalid token usage",
+				fmt.Sprintf("The usage must be either signing or authentication, got: %q.", element.ValueString()),
+			)
+		}
+	}
+
+	return diags
+}
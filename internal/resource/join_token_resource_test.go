@@ -0,0 +1,64 @@
+package resource
+
+import (
+	"context"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/sshtest"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"testing"
+)
+
+const testJoinTokenList = "TOKEN    TTL  EXPIRES               USAGES                  DESCRIPTION  EXTRA GROUPS\n" +
+	"abcdef   23h  2024-06-11T07:00:00Z  authentication,signing  workers      system:bootstrappers:k3s:default-node-token\n"
+
+func TestJoinTokenResourceRead(t *testing.T) {
+	for name, testCase := range map[string]struct {
+		id       string
+		expired  bool
+		warnings int
+	}{
+		"valid": {
+			id:       "abcdef",
+			expired:  false,
+			warnings: 0,
+		},
+		"expired": {
+			id:       "ghijkl",
+			expired:  true,
+			warnings: 1,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			server := sshtest.NewServer(t)
+			server.Respond(`k3s token list$`, testJoinTokenList)
+
+			r := &YoshiK3SJoinTokenResource{}
+			s := resourceSchema(t, r)
+
+			resp := &resource.ReadResponse{State: emptyState(s)}
+			r.Read(ctx, resource.ReadRequest{State: newState(t, s, map[string]attr.Value{
+				"id":              types.StringValue(testCase.id),
+				"node_connection": testConnection(server),
+				"token":           types.StringValue("K10abc::" + testCase.id + ".0123456789abcdef"),
+				"expired":         types.BoolValue(false),
+			})}, resp)
+			requireNoErrors(t, resp.Diagnostics)
+
+			// The expired tokens are kept in the state, so that the worker nodes using them are not updated.
+			if resp.State.Raw.IsNull() {
+				t.Fatal("expected the token to be kept in the state")
+			}
+			var expired types.Bool
+			requireNoErrors(t, resp.State.GetAttribute(ctx, path.Root("expired"), &expired))
+			if expired.ValueBool() != testCase.expired {
+				t.Errorf("expected expired to be %t, got: %s", testCase.expired, expired)
+			}
+			if warnings := resp.Diagnostics.WarningsCount(); warnings != testCase.warnings {
+				t.Errorf("expected %d warnings, got: %v", testCase.warnings, resp.Diagnostics)
+			}
+		})
+	}
+}
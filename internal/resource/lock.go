@@ -0,0 +1,64 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/providerdata"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/remote"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"net"
+	"slices"
+)
+
+// lockHosts holds the hosts for the duration of an operation changing them, and returns the function releasing them.
+// Every host is locked within the provider, and with the remote_lock file of the provider when it is set. The hosts
+// are locked in the order of their addresses, so that operations spanning several hosts cannot deadlock.
+func lockHosts(ctx context.Context, providerData *providerdata.Data, hosts ...*ssh_handler.SshConfig) (func(), error) {
+	if providerData == nil || providerData.Hosts == nil {
+		return func() {}, nil
+	}
+
+	configs := make(map[string]*ssh_handler.SshConfig, len(hosts))
+	for _, host := range hosts {
+		configs[net.JoinHostPort(host.GetHost(), host.GetPort())] = host
+	}
+	addresses := make([]string, 0, len(configs))
+	for address := range configs {
+		addresses = append(addresses, address)
+	}
+	slices.Sort(addresses)
+
+	var releases []func()
+	unlock := func() {
+		for index := len(releases) - 1; index >= 0; index-- {
+			releases[index]()
+		}
+	}
+
+	// Dry runs do not change the hosts, there is no other Terraform run to guard them against.
+	remoteLock := providerData.RemoteLock
+	if dryRunExecutor(providerData) != nil {
+		remoteLock = nil
+	}
+
+	for _, address := range addresses {
+		release, err := providerData.Hosts.Lock(ctx, address)
+		if err != nil {
+			unlock()
+			return nil, fmt.Errorf("failed to lock %s: %w", address, err)
+		}
+		releases = append(releases, release)
+
+		if remoteLock == nil {
+			continue
+		}
+		release, err = remote.AcquireLock(ctx, configs[address], *remoteLock)
+		if err != nil {
+			unlock()
+			return nil, err
+		}
+		releases = append(releases, release)
+	}
+
+	return unlock, nil
+}
@@ -0,0 +1,59 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/executor"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/providerdata"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"testing"
+	"time"
+)
+
+func TestLockHostsSerializesOperations(t *testing.T) {
+	providerData := providerdata.New(executor.NewYoshiK3SExecutor())
+	first := ssh_handler.NewSshConfig("10.0.0.1", "22", "root", "password", "", "")
+	second := ssh_handler.NewSshConfig("10.0.0.2", "22", "root", "password", "", "")
+
+	unlockSecond, err := lockHosts(context.Background(), providerData, second)
+	if err != nil {
+		t.Fatalf("failed to lock the host: %s", err)
+	}
+
+	// An operation spanning the locked host waits for it, gives up with its context and releases the other host.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := lockHosts(ctx, providerData, second, first); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the lock to wait for the host, got: %v", err)
+	}
+
+	unlockFirst, err := lockHosts(context.Background(), providerData, first)
+	if err != nil {
+		t.Fatalf("failed to lock the released host: %s", err)
+	}
+	unlockFirst()
+
+	locked := make(chan func(), 1)
+	go func() {
+		unlockBoth, err := lockHosts(context.Background(), providerData, first, second, first)
+		if err != nil {
+			t.Errorf("failed to lock the hosts: %s", err)
+			unlockBoth = func() {}
+		}
+		locked <- unlockBoth
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("expected the lock to wait until the host is released")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlockSecond()
+	select {
+	case unlockBoth := <-locked:
+		unlockBoth()
+	case <-time.After(time.Second):
+		t.Fatal("expected the lock to be acquired once the host is released")
+	}
+}
@@ -0,0 +1,257 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/executor"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/model"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/providerdata"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/remote"
+	"github.com/hashicorp/terraform-plugin-framework-validators/resourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &YoshiK3SManifestResource{}
+var _ resource.ResourceWithConfigure = &YoshiK3SManifestResource{}
+var _ resource.ResourceWithConfigValidators = &YoshiK3SManifestResource{}
+var _ resource.ResourceWithValidateConfig = &YoshiK3SManifestResource{}
+var _ resource.ResourceWithModifyPlan = &YoshiK3SManifestResource{}
+
+func NewYoshiK3SManifestResource() resource.Resource {
+	return &YoshiK3SManifestResource{}
+}
+
+// YoshiK3SManifestResource defines the resource implementation.
+type YoshiK3SManifestResource struct {
+	providerData *providerdata.Data
+}
+
+func (r *YoshiK3SManifestResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_manifest"
+}
+
+func (r *YoshiK3SManifestResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "K3S Manifest Resource, deploys a manifest in the directory automatically applied by K3S on a master node.",
+
+		Version: model.YoshiK3SManifestResourceModelSchemaVersion,
+
+		Attributes: model.YoshiK3SManifestResourceModelSchema,
+	}
+}
+
+func (r *YoshiK3SManifestResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.providerData = configureProviderData(req.ProviderData, &resp.Diagnostics)
+}
+
+func (r *YoshiK3SManifestResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		resourcevalidator.ExactlyOneOf(
+			path.MatchRoot("node_connection").AtName("password"),
+			path.MatchRoot("node_connection").AtName("private_key"),
+		),
+	}
+}
+
+func (r *YoshiK3SManifestResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data model.YoshiK3SManifestResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(validateConnectionObject(ctx, path.Root("node_connection"), data.Connection)...)
+	resp.Diagnostics.Append(validateManifestName(path.Root("name"), data.Name)...)
+	resp.Diagnostics.Append(validateYamlContent(path.Root("content"), data.Content)...)
+}
+
+// ModifyPlan plans the checksum of the content, so a manifest modified on the node is planned as an update.
+func (r *YoshiK3SManifestResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to plan when the resource is being destroyed.
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var content types.String
+
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("content"), &content)...)
+
+	if resp.Diagnostics.HasError() || content.IsUnknown() {
+		return
+	}
+
+	checksum := types.StringValue(remote.Checksum([]byte(content.ValueString())))
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("checksum"), checksum)...)
+}
+
+func (r *YoshiK3SManifestResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = remote.WithCommandLogging(ctx, "yoshik3s_manifest", "create")
+
+	var data model.YoshiK3SManifestResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sshConfig := sshConfigFromConnection(ctx, data.Connection)
+	ctx = withConnectionSettings(ctx, r.providerData, sshConfig, data.Connection)
+	if sshConfig == nil {
+		resp.Diagnostics.AddError(
+			"Failed to create a manifest",
+			"Invalid node configuration. Please check the node configuration.",
+		)
+		return
+	}
+
+	unlock, err := lockHosts(ctx, r.providerData, sshConfig)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to lock the node", err.Error())
+		return
+	}
+	defer unlock()
+
+	name := data.Name.ValueString()
+	content := []byte(data.Content.ValueString())
+
+	err = remote.WriteManifest(ctx, sshConfig, name, content)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to create a manifest", err.Error())
+		return
+	}
+
+	tflog.Trace(ctx, "created a resource", map[string]interface{}{
+		"manifest": name,
+	})
+	data.Id = types.StringValue(name)
+	data.Path = types.StringValue(remote.ManifestPath(name))
+	data.Checksum = types.StringValue(remote.Checksum(content))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *YoshiK3SManifestResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = remote.WithCommandLogging(ctx, "yoshik3s_manifest", "read")
+	ctx, cancel := withReadTimeout(ctx, r.providerData)
+	defer cancel()
+
+	var data model.YoshiK3SManifestResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sshConfig := sshConfigFromConnection(ctx, data.Connection)
+	ctx = withConnectionSettings(ctx, r.providerData, sshConfig, data.Connection)
+	if sshConfig == nil {
+		return
+	}
+
+	checksum, err := remote.ManifestChecksum(executor.Commands(ctx, nodeExecutor(r.providerData), sshConfig), data.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddWarning(
+			"Failed to refresh manifest",
+			fmt.Sprintf("The manifest could not be read, keeping the previous state: %s", err.Error()),
+		)
+		return
+	}
+	if checksum == "" {
+		tflog.Warn(ctx, "manifest no longer exists, removing it from the state", map[string]interface{}{
+			"manifest": data.Name.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	data.Checksum = types.StringValue(checksum)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *YoshiK3SManifestResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = remote.WithCommandLogging(ctx, "yoshik3s_manifest", "update")
+
+	var data model.YoshiK3SManifestResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sshConfig := sshConfigFromConnection(ctx, data.Connection)
+	ctx = withConnectionSettings(ctx, r.providerData, sshConfig, data.Connection)
+	if sshConfig == nil {
+		resp.Diagnostics.AddError(
+			"Failed to update a manifest",
+			"Invalid node configuration. Please check the node configuration.",
+		)
+		return
+	}
+
+	unlock, err := lockHosts(ctx, r.providerData, sshConfig)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to lock the node", err.Error())
+		return
+	}
+	defer unlock()
+
+	content := []byte(data.Content.ValueString())
+
+	err = remote.WriteManifest(ctx, sshConfig, data.Name.ValueString(), content)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to update a manifest", err.Error())
+		return
+	}
+	data.Checksum = types.StringValue(remote.Checksum(content))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *YoshiK3SManifestResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = remote.WithCommandLogging(ctx, "yoshik3s_manifest", "delete")
+
+	var data model.YoshiK3SManifestResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sshConfig := sshConfigFromConnection(ctx, data.Connection)
+	ctx = withConnectionSettings(ctx, r.providerData, sshConfig, data.Connection)
+	if sshConfig == nil {
+		resp.Diagnostics.AddError(
+			"Failed to delete a manifest",
+			"Invalid node configuration. Please check the node configuration.",
+		)
+		return
+	}
+
+	unlock, err := lockHosts(ctx, r.providerData, sshConfig)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to lock the node", err.Error())
+		return
+	}
+	defer unlock()
+
+	err = remote.RemoveManifest(ctx, sshConfig, data.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("failed to delete a manifest", err.Error())
+		return
+	}
+}
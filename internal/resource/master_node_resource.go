@@ -5,31 +5,47 @@ package resource
 
 import (
 	"context"
+	"fmt"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/executor"
 	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/model"
-	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/cluster"
-	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/resources"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/providerdata"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/remote"
 	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"github.com/hashicorp/terraform-plugin-framework-validators/resourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/identityschema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"time"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.Resource = &YoshiK3SMasterNodeResource{}
 var _ resource.ResourceWithImportState = &YoshiK3SMasterNodeResource{}
+var _ resource.ResourceWithConfigValidators = &YoshiK3SMasterNodeResource{}
+var _ resource.ResourceWithValidateConfig = &YoshiK3SMasterNodeResource{}
+var _ resource.ResourceWithIdentity = &YoshiK3SMasterNodeResource{}
+var _ resource.ResourceWithUpgradeState = &YoshiK3SMasterNodeResource{}
+var _ resource.ResourceWithConfigure = &YoshiK3SMasterNodeResource{}
+var _ resource.ResourceWithModifyPlan = &YoshiK3SMasterNodeResource{}
 
 func NewYoshiK3SMasterNodeResource() resource.Resource {
 	return &YoshiK3SMasterNodeResource{}
 }
 
 // YoshiK3SMasterNodeResource defines the resource implementation.
-type YoshiK3SMasterNodeResource struct{}
+type YoshiK3SMasterNodeResource struct {
+	providerData *providerdata.Data
+}
 
 func (r *YoshiK3SMasterNodeResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_master_node"
+	// The identity follows the cluster name, which can be renamed in place.
+	resp.ResourceBehavior.MutableIdentity = true
 }
 
 func (r *YoshiK3SMasterNodeResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
@@ -37,15 +53,119 @@ func (r *YoshiK3SMasterNodeResource) Schema(ctx context.Context, req resource.Sc
 		// This description is used by the documentation generator and the language server.
 		MarkdownDescription: "K3S Master Node Resource",
 
+		Version: model.YoshiK3SMasterNodeResourceModelSchemaVersion,
+
 		Attributes: model.YoshiK3SMasterNodeResourceModelSchema,
 	}
 }
 
+func (r *YoshiK3SMasterNodeResource) IdentitySchema(ctx context.Context, req resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = identityschema.Schema{
+		Attributes: model.YoshiK3SNodeIdentitySchema,
+	}
+}
+
+func (r *YoshiK3SMasterNodeResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		// Version 0 stored the node and cluster ids wrapped in quotes.
+		0: newRawStateUpgrader(unquoteId, unquoteNestedClusterId, nodeAddressId),
+		// Version 1 identified the nodes by their host only.
+		1: newRawStateUpgrader(nodeAddressId),
+	}
+}
+
 func (r *YoshiK3SMasterNodeResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-	//	No configuration is needed for this resource.
+	r.providerData = configureProviderData(req.ProviderData, &resp.Diagnostics)
+}
+
+func (r *YoshiK3SMasterNodeResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		resourcevalidator.ExactlyOneOf(
+			path.MatchRoot("cluster_id"),
+			path.MatchRoot("cluster"),
+		),
+		resourcevalidator.ExactlyOneOf(
+			path.MatchRoot("node_connection").AtName("password"),
+			path.MatchRoot("node_connection").AtName("private_key"),
+		),
+	}
+}
+
+func (r *YoshiK3SMasterNodeResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data model.YoshiK3SMasterNodeResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(validateClusterObject(ctx, path.Root("cluster"), data.Cluster)...)
+	resp.Diagnostics.Append(validateConnectionObject(ctx, path.Root("node_connection"), data.Connection)...)
+	resp.Diagnostics.Append(validateNodeOptions(ctx, path.Root("node_options"), data.Options, isK3sServerFlag)...)
+	resp.Diagnostics.Append(validateEtcdSnapshotsObject(ctx, path.Root("etcd_snapshots"), data.EtcdSnapshots, path.Root("node_options"), data.Options)...)
+	resp.Diagnostics.Append(validateEtcdRestoreObject(ctx, path.Root("restore_from_snapshot"), data.RestoreFromSnapshot)...)
+	resp.Diagnostics.Append(validateSecretsEncryptionObject(ctx, path.Root("secrets_encryption"), data.SecretsEncryption, path.Root("node_options"), data.Options)...)
+	if r.agentTokenFromModel(data) != "" {
+		resp.Diagnostics.Append(validateConflictingNodeOptions(ctx, path.Root("node_options"), data.Options, "cluster.agent_token", agentTokenFlags)...)
+	}
+	resp.Diagnostics.Append(validateCertificateExpiryWarningDays(path.Root("certificate_expiry_warning_days"), data.CertificateExpiryWarningDays)...)
+}
+
+func (r *YoshiK3SMasterNodeResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to plan when the resource is being destroyed.
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan model.YoshiK3SMasterNodeResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	priorCluster := types.ObjectNull(model.YoshiK3SNodeClusterAttributeTypes)
+	priorSecretsEncryption := types.ObjectNull(plan.SecretsEncryption.AttributeTypes(ctx))
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("cluster"), &priorCluster)...)
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("secrets_encryption"), &priorSecretsEncryption)...)
+	}
+
+	// Removing the flag would leave k3s unable to read the secrets encrypted so far.
+	if !priorSecretsEncryption.IsNull() && plan.SecretsEncryption.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("secrets_encryption"),
+			"Secrets encryption cannot be disabled",
+			"The secrets encryption is enabled on the master node. Disable it with `k3s secrets-encrypt disable` "+
+				"followed by `k3s secrets-encrypt reencrypt` before removing the attribute.",
+		)
+		return
+	}
+
+	cluster, diags := planClusterReference(ctx, r.providerData, plan.ClusterId, plan.Cluster, priorCluster)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("cluster"), cluster)...)
+	resp.Diagnostics.Append(validateCertificateAuthorityUnchanged(ctx, path.Root("cluster").AtName("certificate_authority"), cluster, priorCluster)...)
+
+	// The expiry is unknown whenever an update is planned, since updating the node or rotating its certificates may
+	// reissue them, so the warnings are only given with the values read by the last refresh.
+	resp.Diagnostics.Append(warnCertificateExpiry(ctx, plan.CertificateExpiry, plan.CertificateExpiryWarningDays, time.Now())...)
+
+	if !plan.Options.IsUnknown() {
+		r.registerMaster(ctx, plan)
+	}
 }
 
 func (r *YoshiK3SMasterNodeResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = remote.WithCommandLogging(ctx, "yoshik3s_master_node", "create")
+
 	var data model.YoshiK3SMasterNodeResourceModel
 
 	// Read Terraform plan data into the model
@@ -55,44 +175,149 @@ func (r *YoshiK3SMasterNodeResource) Create(ctx context.Context, req resource.Cr
 		return
 	}
 
-	client := r.createClientFromModel(data)
-	if client == nil {
+	var diags diag.Diagnostics
+	data.Cluster, diags = applyClusterReference(ctx, r.providerData, data.ClusterId, data.Cluster)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	install := r.createInstallFromModel(data)
+	if install == nil {
 		resp.Diagnostics.AddError(
 			"Failed to create a master node",
 			"Invalid cluster configuration. Please check the cluster configuration.",
 		)
 		return
 	}
-	nodeConfig := r.createNodeConfigFromModel(data)
-	if nodeConfig == nil {
+	sshConfig := sshConfigFromConnection(ctx, data.Connection)
+	ctx = withConnectionSettings(ctx, r.providerData, sshConfig, data.Connection)
+	if sshConfig == nil {
 		resp.Diagnostics.AddError(
 			"Failed to create a master node",
 			"Invalid node configuration. Please check the node configuration.",
 		)
 		return
 	}
-	options := r.createNodeOptionsFromModel(data)
 
-	kubeconfig, err := client.ConfigureMasterNode(
-		*nodeConfig,
-		options,
-	)
+	unlock, err := lockHosts(ctx, r.providerData, sshConfig)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to lock the node", err.Error())
+		return
+	}
+	defer unlock()
+
+	etcdSnapshots, diags := r.createEtcdSnapshotsConfigFromModel(ctx, data)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	secretsEncryption, diags := secretsEncryptionConfigFromModel(ctx, data.SecretsEncryption)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The configuration is written before running the installer, which (re)starts k3s.
+	files := executor.Files(ctx, nodeExecutor(r.providerData), sshConfig)
+	err = remote.WriteEtcdSnapshotsConfig(files, etcdSnapshots)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to configure the etcd snapshots", err.Error())
+		return
+	}
+	err = remote.WriteSecretsEncryptionConfig(files, secretsEncryption)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to configure the secrets encryption", err.Error())
+		return
+	}
+	err = remote.WriteAgentTokenConfig(files, r.agentTokenFromModel(data))
+	if err != nil {
+		resp.Diagnostics.AddError("failed to configure the agent token", err.Error())
+		return
+	}
+
+	// The first master node generates the certificate authorities of the cluster on its first start,
+	// the joining ones download them from the datastore.
+	if !isJoiningServer(install.Options) {
+		caFiles, diags := certificateAuthorityFiles(ctx, data.Cluster)
+		resp.Diagnostics.Append(diags...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if len(caFiles) > 0 {
+			tflog.Info(ctx, "uploading the custom certificate authorities", map[string]interface{}{
+				"host": sshConfig.GetHost(),
+			})
+			err = remote.WriteCertificateAuthorityFiles(files, caFiles)
+			if err != nil {
+				resp.Diagnostics.AddError("failed to upload the certificate authorities", err.Error())
+				return
+			}
+		}
+	}
+
+	kubeconfig, err := nodeExecutor(r.providerData).Install(ctx, sshConfig, *install)
 	if err != nil {
 		resp.Diagnostics.AddError("failed to create a master node", err.Error())
 		return
 	}
+	if dryRunExecutor(r.providerData) != nil {
+		// The restore is recorded along with the install, the node is not saved since neither was run.
+		_, diags := restoreFromSnapshot(ctx, nodeExecutor(r.providerData), sshConfig, data.RestoreFromSnapshot, data.Cluster)
+		resp.Diagnostics.Append(diags...)
+		addDryRunError(&resp.Diagnostics)
+		return
+	}
 
 	//// Write logs using the tflog package
 	//// Documentation: https://terraform.io/plugin/log
 	tflog.Trace(ctx, "created a resource")
-	data.Id = types.StringValue(data.Connection.Attributes()["host"].String())
-	data.Kubeconfig = types.StringValue(string((*kubeconfig)[:]))
+	data.Id = types.StringValue(nodeId(sshConfig))
+
+	// K3S is installed from now on, the node is saved to the state before the remaining steps so that their failure
+	// leaves it tainted instead of untracked.
+	data.Kubeconfig = types.StringValue(string(kubeconfig))
+	data.SecretsEncryptionStatus = types.StringNull()
+	data.CertificateExpiry = types.MapNull(types.StringType)
+	r.registerMaster(ctx, data)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	identity, identityDiags := nodeIdentity(ctx, data.Cluster, data.Connection)
+	resp.Diagnostics.Append(identityDiags...)
+	resp.Diagnostics.Append(resp.Identity.Set(ctx, identity)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	restoredKubeconfig, diags := restoreFromSnapshot(ctx, nodeExecutor(r.providerData), sshConfig, data.RestoreFromSnapshot, data.Cluster)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if restoredKubeconfig != nil {
+		data.Kubeconfig = types.StringValue(string(restoredKubeconfig))
+	}
+
+	resp.Diagnostics.Append(readMasterNodeStatus(ctx, nodeExecutor(r.providerData), sshConfig, &data)...)
 
 	//// Save data into Terraform state
+	r.registerMaster(ctx, data)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 func (r *YoshiK3SMasterNodeResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = remote.WithCommandLogging(ctx, "yoshik3s_master_node", "read")
+	ctx, cancel := withReadTimeout(ctx, r.providerData)
+	defer cancel()
+
 	var data model.YoshiK3SMasterNodeResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
@@ -101,10 +326,52 @@ func (r *YoshiK3SMasterNodeResource) Read(ctx context.Context, req resource.Read
 		return
 	}
 
+	sshConfig := sshConfigFromConnection(ctx, data.Connection)
+	ctx = withConnectionSettings(ctx, r.providerData, sshConfig, data.Connection)
+
+	pendingDiscovery := isPendingDiscovery(ctx, data.Cluster)
+	nodeInfo := discoverNodeOnRead(ctx, nodeExecutor(r.providerData), sshConfig, data.Cluster, remote.NodeRoleServer, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if nodeInfo != nil {
+		if nodeInfo.Role == remote.NodeRoleNone {
+			tflog.Warn(ctx, "k3s is no longer installed on the node, removing it from the state")
+			resp.State.RemoveResource(ctx)
+			return
+		}
+
+		var diags diag.Diagnostics
+		data.Cluster, diags = mergeDiscoveredCluster(ctx, data.Cluster, nodeInfo)
+		resp.Diagnostics.Append(diags...)
+
+		if pendingDiscovery && data.Options.IsNull() && len(nodeInfo.Options) > 0 {
+			data.Options, diags = types.ListValueFrom(ctx, types.StringType, nodeInfo.Options)
+			resp.Diagnostics.Append(diags...)
+		}
+		if data.Kubeconfig.IsNull() && len(nodeInfo.Kubeconfig) > 0 {
+			data.Kubeconfig = types.StringValue(string(nodeInfo.Kubeconfig))
+		}
+
+		resp.Diagnostics.Append(readMasterNodeStatus(ctx, nodeExecutor(r.providerData), sshConfig, &data)...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	r.registerMaster(ctx, data)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	identity, identityDiags := nodeIdentity(ctx, data.Cluster, data.Connection)
+	resp.Diagnostics.Append(identityDiags...)
+	resp.Diagnostics.Append(resp.Identity.Set(ctx, identity)...)
 }
 
 func (r *YoshiK3SMasterNodeResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = remote.WithCommandLogging(ctx, "yoshik3s_master_node", "update")
+
 	var data model.YoshiK3SMasterNodeResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -113,78 +380,184 @@ func (r *YoshiK3SMasterNodeResource) Update(ctx context.Context, req resource.Up
 		return
 	}
 
-	client := r.createClientFromModel(data)
-	if client == nil {
+	var diags diag.Diagnostics
+	data.Cluster, diags = applyClusterReference(ctx, r.providerData, data.ClusterId, data.Cluster)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	install := r.createInstallFromModel(data)
+	if install == nil {
 		resp.Diagnostics.AddError(
 			"Failed to create a master node",
 			"Invalid cluster configuration. Please check the cluster configuration.",
 		)
 		return
 	}
-	nodeConfig := r.createNodeConfigFromModel(data)
-	if nodeConfig == nil {
+	sshConfig := sshConfigFromConnection(ctx, data.Connection)
+	ctx = withConnectionSettings(ctx, r.providerData, sshConfig, data.Connection)
+	if sshConfig == nil {
 		resp.Diagnostics.AddError(
 			"Failed to create a master node",
 			"Invalid node configuration. Please check the node configuration.",
 		)
 		return
 	}
-	options := r.createNodeOptionsFromModel(data)
 
-	kubeconfig, err := client.ConfigureMasterNode(
-		*nodeConfig,
-		options,
-	)
+	unlock, err := lockHosts(ctx, r.providerData, sshConfig)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to lock the node", err.Error())
+		return
+	}
+	defer unlock()
+
+	etcdSnapshots, diags := r.createEtcdSnapshotsConfigFromModel(ctx, data)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	secretsEncryption, diags := secretsEncryptionConfigFromModel(ctx, data.SecretsEncryption)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The configuration is written before running the installer, which (re)starts k3s.
+	files := executor.Files(ctx, nodeExecutor(r.providerData), sshConfig)
+	err = remote.WriteEtcdSnapshotsConfig(files, etcdSnapshots)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to configure the etcd snapshots", err.Error())
+		return
+	}
+	err = remote.WriteSecretsEncryptionConfig(files, secretsEncryption)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to configure the secrets encryption", err.Error())
+		return
+	}
+	err = remote.WriteAgentTokenConfig(files, r.agentTokenFromModel(data))
+	if err != nil {
+		resp.Diagnostics.AddError("failed to configure the agent token", err.Error())
+		return
+	}
+
+	kubeconfig, err := nodeExecutor(r.providerData).Install(ctx, sshConfig, *install)
 	if err != nil {
 		resp.Diagnostics.AddError("failed to update master node", err.Error())
 		return
 	}
-	data.Kubeconfig = types.StringValue(string((*kubeconfig)[:]))
+	var priorRotateCertificates types.String
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("rotate_certificates"), &priorRotateCertificates)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	rotationPlanned := isCertificateRotationPlanned(data.RotateCertificates, priorRotateCertificates)
+
+	data.Kubeconfig = types.StringValue(string(kubeconfig))
+
+	if rotationPlanned {
+		rotatedKubeconfig, diags := rotateCertificates(ctx, nodeExecutor(r.providerData), sshConfig, data.Cluster)
+		resp.Diagnostics.Append(diags...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if rotatedKubeconfig != nil {
+			data.Kubeconfig = types.StringValue(string(rotatedKubeconfig))
+		}
+	}
+
+	if dryRunExecutor(r.providerData) != nil {
+		resp.State.Raw = req.State.Raw
+		addDryRunError(&resp.Diagnostics)
+		return
+	}
 
+	resp.Diagnostics.Append(readMasterNodeStatus(ctx, nodeExecutor(r.providerData), sshConfig, &data)...)
+
+	r.registerMaster(ctx, data)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	identity, identityDiags := nodeIdentity(ctx, data.Cluster, data.Connection)
+	resp.Diagnostics.Append(identityDiags...)
+	resp.Diagnostics.Append(resp.Identity.Set(ctx, identity)...)
 }
 
 func (r *YoshiK3SMasterNodeResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = remote.WithCommandLogging(ctx, "yoshik3s_master_node", "delete")
+
 	var data model.YoshiK3SMasterNodeResourceModel
 
 	// Read Terraform prior state data into the model
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
 
-	client := r.createClientFromModel(data)
-	if client == nil {
+	sshConfig := sshConfigFromConnection(ctx, data.Connection)
+	ctx = withConnectionSettings(ctx, r.providerData, sshConfig, data.Connection)
+	if sshConfig == nil {
 		resp.Diagnostics.AddError(
 			"Failed to create a master node",
-			"Invalid cluster configuration. Please check the cluster configuration.",
+			"Invalid node configuration. Please check the node configuration.",
 		)
 		return
 	}
-	nodeConfig := r.createNodeConfigFromModel(data)
-	if nodeConfig == nil {
-		resp.Diagnostics.AddError(
-			"Failed to create a master node",
-			"Invalid node configuration. Please check the node configuration.",
-		)
+
+	unlock, err := lockHosts(ctx, r.providerData, sshConfig)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to lock the node", err.Error())
 		return
 	}
+	defer unlock()
 
-	err := client.DestroyMasterNode(
-		*nodeConfig,
-	)
+	err = nodeExecutor(r.providerData).Uninstall(ctx, sshConfig, remote.NodeRoleServer)
 	if err != nil {
 		resp.Diagnostics.AddError("failed to delete a master node", err.Error())
 		return
 	}
+	if dryRunExecutor(r.providerData) != nil {
+		addDryRunError(&resp.Diagnostics)
+		return
+	}
+
+	if r.providerData != nil {
+		r.providerData.Masters.Remove(nodeId(sshConfig))
+	}
 
 	if resp.Diagnostics.HasError() {
 		return
 	}
 }
 
+// ImportState accepts IDs in the format `<user>@<host>[:<port>][/<cluster_name>]` or the resource identity,
+// the SSH credentials are read from the environment and the remaining attributes are discovered from the host on Read.
 func (r *YoshiK3SMasterNodeResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	importId, diags := nodeImportIdFromRequest(ctx, req)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	connection, diags := importId.connectionObject()
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), importId.address())...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("node_connection"), connection)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("cluster"), importId.clusterObject(model.YoshiK3SNodeClusterAttributeTypes))...)
+	if importId.ClusterName != "" {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("cluster_id"), importId.ClusterName)...)
+	}
 }
 
-func (r *YoshiK3SMasterNodeResource) createClientFromModel(data model.YoshiK3SMasterNodeResourceModel) *cluster.K3sCluster {
+func (r *YoshiK3SMasterNodeResource) createInstallFromModel(data model.YoshiK3SMasterNodeResourceModel) *executor.Install {
 	if data.Cluster.IsNull() || data.Cluster.IsUnknown() {
 		return nil
 	}
@@ -199,65 +572,98 @@ func (r *YoshiK3SMasterNodeResource) createClientFromModel(data model.YoshiK3SMa
 	k3sToken := clusterModel.ClusterToken.ValueString()
 	k3sClusterAddress := clusterModel.ClusterAddress.ValueString()
 
-	return cluster.NewK3sClientWithVersion(k3sVersion, k3sToken, k3sClusterAddress)
+	return &executor.Install{
+		Role:    remote.NodeRoleServer,
+		Version: k3sVersion,
+		Token:   k3sToken,
+		Address: k3sClusterAddress,
+		Options: r.createNodeOptionsFromModel(data),
+	}
 }
 
-func (r *YoshiK3SMasterNodeResource) createNodeConfigFromModel(data model.YoshiK3SMasterNodeResourceModel) *resources.NodeConfig {
-	return resources.NewNodeConfig(
-		data.Connection.Attributes()["host"].String(),
-		r.createSshConfigFromModel(data),
-	)
-}
+// readMasterNodeStatus reads the secrets encryption status and the certificate expiry of the master node. They only
+// report on the node, so failing to read them is a warning: the values known so far are kept, and the unknown ones are
+// left null until the next refresh.
+func readMasterNodeStatus(ctx context.Context, exec executor.Executor, sshConfig *ssh_handler.SshConfig, data *model.YoshiK3SMasterNodeResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	commands := executor.Commands(ctx, exec, sshConfig)
 
-func (r *YoshiK3SMasterNodeResource) createSshConfigFromModel(data model.YoshiK3SMasterNodeResourceModel) *ssh_handler.SshConfig {
-	connectionModel := r.parseSshConnectionModel(data.Connection)
-	if connectionModel == nil {
-		return nil
+	status, err := readSecretsEncryptionStatus(commands, data.SecretsEncryption)
+	if err != nil {
+		diags.AddWarning(
+			"Failed to read the secrets encryption status",
+			fmt.Sprintf("The secrets encryption status could not be read, it is read again on the next refresh: %s", err.Error()),
+		)
+		if data.SecretsEncryptionStatus.IsUnknown() {
+			data.SecretsEncryptionStatus = types.StringNull()
+		}
+	} else {
+		data.SecretsEncryptionStatus = status
 	}
 
-	var password string
-	if connectionModel.Password.IsNull() || connectionModel.Password.IsUnknown() {
-		password = ""
+	expiry, err := readCertificateExpiry(ctx, commands)
+	if err != nil {
+		diags.AddWarning(
+			"Failed to read the certificate expiry",
+			fmt.Sprintf("The certificates could not be read, they are read again on the next refresh: %s", err.Error()),
+		)
+		if data.CertificateExpiry.IsUnknown() {
+			data.CertificateExpiry = types.MapNull(types.StringType)
+		}
 	} else {
-		password = connectionModel.Password.ValueString()
+		data.CertificateExpiry = expiry
 	}
 
-	var privateKey string
-	if connectionModel.PrivateKey.IsNull() || connectionModel.PrivateKey.IsUnknown() {
-		privateKey = ""
-	} else {
-		privateKey = connectionModel.PrivateKey.ValueString()
+	return diags
+}
+
+// agentTokenFromModel returns the agent token of the cluster, or an empty string when the cluster has none.
+func (r *YoshiK3SMasterNodeResource) agentTokenFromModel(data model.YoshiK3SMasterNodeResourceModel) string {
+	if data.Cluster.IsNull() || data.Cluster.IsUnknown() {
+		return ""
 	}
 
-	var privateKeyPassphrase string
-	if connectionModel.PrivateKeyPassphrase.IsNull() || connectionModel.PrivateKeyPassphrase.IsUnknown() {
-		privateKeyPassphrase = ""
-	} else {
-		privateKeyPassphrase = connectionModel.PrivateKeyPassphrase.ValueString()
+	var clusterModel model.YoshiK3SClusterResourceModel
+	diags := data.Cluster.As(context.Background(), &clusterModel, basetypes.ObjectAsOptions{})
+	if diags.HasError() {
+		return ""
 	}
 
-	return ssh_handler.NewSshConfig(
-		connectionModel.Host.ValueString(),
-		connectionModel.Port.ValueString(),
-		connectionModel.User.ValueString(),
-		password,
-		privateKey,
-		privateKeyPassphrase,
-	)
+	return clusterModel.AgentToken.ValueString()
 }
 
-func (r *YoshiK3SMasterNodeResource) parseSshConnectionModel(data types.Object) *model.YoshiK3SConnectionModel {
-	if data.IsNull() || data.IsUnknown() {
-		return nil
+func (r *YoshiK3SMasterNodeResource) registerMaster(ctx context.Context, data model.YoshiK3SMasterNodeResourceModel) {
+	if r.providerData == nil {
+		return
+	}
+
+	r.providerData.Masters.Register(connectionAddress(parseConnectionModel(ctx, data.Connection)), r.createNodeOptionsFromModel(data))
+}
+
+// createEtcdSnapshotsConfigFromModel returns nil when the scheduled etcd snapshots are left to the K3S defaults.
+func (r *YoshiK3SMasterNodeResource) createEtcdSnapshotsConfigFromModel(ctx context.Context, data model.YoshiK3SMasterNodeResourceModel) (*remote.EtcdSnapshotsConfig, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if data.EtcdSnapshots.IsNull() || data.EtcdSnapshots.IsUnknown() {
+		return nil, diags
 	}
 
-	var connectionModel model.YoshiK3SConnectionModel
-	diags := data.As(context.Background(), &connectionModel, basetypes.ObjectAsOptions{})
+	var snapshotsModel model.YoshiK3SEtcdSnapshotsModel
+	diags.Append(data.EtcdSnapshots.As(ctx, &snapshotsModel, basetypes.ObjectAsOptions{})...)
 	if diags.HasError() {
-		return nil
+		return nil, diags
 	}
 
-	return &connectionModel
+	s3, s3Diags := etcdS3OptionsFromModel(ctx, snapshotsModel.S3)
+	diags.Append(s3Diags...)
+
+	return &remote.EtcdSnapshotsConfig{
+		ScheduleCron: snapshotsModel.ScheduleCron.ValueString(),
+		Retention:    snapshotsModel.Retention.ValueInt64(),
+		Directory:    snapshotsModel.Directory.ValueString(),
+		Compress:     snapshotsModel.Compress.ValueBool(),
+		S3:           s3,
+	}, diags
 }
 
 func (r *YoshiK3SMasterNodeResource) createNodeOptionsFromModel(model model.YoshiK3SMasterNodeResourceModel) []string {
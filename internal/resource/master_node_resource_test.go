@@ -0,0 +1,464 @@
+package resource
+
+import (
+	"context"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/executor"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/model"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/providerdata"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/remote"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/sshtest"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMasterNodeResourceCreate(t *testing.T) {
+	ctx := context.Background()
+	server := newK3sServer(t, remote.NodeRoleServer)
+
+	r := &YoshiK3SMasterNodeResource{}
+	s := resourceSchema(t, r)
+
+	req := resource.CreateRequest{
+		Plan: newPlan(t, s, map[string]attr.Value{
+			"cluster":                         testCluster(types.StringNull()),
+			"node_connection":                 testConnection(server),
+			"node_options":                    testOptions("--disable traefik"),
+			"certificate_expiry_warning_days": types.Int64Value(30),
+		}),
+	}
+	resp := &resource.CreateResponse{
+		State:    emptyState(s),
+		Identity: emptyIdentity(resourceIdentitySchema(t, r)),
+	}
+
+	r.Create(ctx, req, resp)
+	requireNoErrors(t, resp.Diagnostics)
+
+	install := requireCommand(t, server, `get\.k3s\.io`)
+	for _, expected := range []string{
+		`K3S_TOKEN="` + testClusterToken + `"`,
+		`INSTALL_K3S_VERSION="` + testK3sVersion + `"`,
+		"--tls-san " + testClusterAddress + " server --disable traefik",
+	} {
+		if !strings.Contains(install.Command, expected) {
+			t.Errorf("expected the install command to contain %q, got: %s", expected, install.Command)
+		}
+	}
+	if install.Stdin != server.Password+"\n" {
+		t.Errorf("expected the sudo password on the standard input, got: %q", install.Stdin)
+	}
+
+	if id := getStringAttribute(t, resp.State, path.Root("id")); id.ValueString() != net.JoinHostPort(server.Host(), server.Port()) {
+		t.Errorf("expected the id %q, got: %s", net.JoinHostPort(server.Host(), server.Port()), id)
+	}
+	kubeconfig := getStringAttribute(t, resp.State, path.Root("kubeconfig"))
+	if !strings.Contains(kubeconfig.ValueString(), "server: https://"+testClusterAddress+":6443") {
+		t.Errorf("expected the kubeconfig to point to the cluster address, got:\n%s", kubeconfig.ValueString())
+	}
+}
+
+func TestMasterNodeResourceCreateFailure(t *testing.T) {
+	ctx := context.Background()
+	server := newK3sServer(t, remote.NodeRoleServer)
+	server.Fail(`get\.k3s\.io`, "[ERROR]  Download failed\n", 1)
+
+	r := &YoshiK3SMasterNodeResource{}
+	s := resourceSchema(t, r)
+
+	req := resource.CreateRequest{
+		Plan: newPlan(t, s, map[string]attr.Value{
+			"cluster":         testCluster(types.StringNull()),
+			"node_connection": testConnection(server),
+		}),
+	}
+	resp := &resource.CreateResponse{
+		State:    emptyState(s),
+		Identity: emptyIdentity(resourceIdentitySchema(t, r)),
+	}
+
+	r.Create(ctx, req, resp)
+
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected the failed installation to be reported")
+	}
+	if detail := resp.Diagnostics.Errors()[0].Detail(); !strings.Contains(detail, "[ERROR]  Download failed") {
+		t.Errorf("expected the output of the install script in the diagnostic, got: %s", detail)
+	}
+	if !resp.State.Raw.IsNull() {
+		t.Error("expected no state to be saved")
+	}
+}
+
+func TestMasterNodeResourceCreateRestoreFailure(t *testing.T) {
+	ctx := context.Background()
+	server := newK3sServer(t, remote.NodeRoleServer)
+	server.Fail(`systemctl stop k3s$`, "Failed to stop k3s.service: Access denied\n", 1)
+
+	r := &YoshiK3SMasterNodeResource{}
+	s := resourceSchema(t, r)
+
+	req := resource.CreateRequest{
+		Plan: newPlan(t, s, map[string]attr.Value{
+			"cluster":         testCluster(types.StringNull()),
+			"node_connection": testConnection(server),
+			"restore_from_snapshot": types.ObjectValueMust(
+				map[string]attr.Type{
+					"path": types.StringType,
+					"s3":   types.ObjectType{AttrTypes: model.YoshiK3SEtcdS3AttributeTypes},
+				},
+				map[string]attr.Value{
+					"path": types.StringValue("/var/lib/rancher/k3s/server/db/snapshots/pre-upgrade"),
+					"s3":   types.ObjectNull(model.YoshiK3SEtcdS3AttributeTypes),
+				},
+			),
+		}),
+	}
+	resp := &resource.CreateResponse{
+		State:    emptyState(s),
+		Identity: emptyIdentity(resourceIdentitySchema(t, r)),
+	}
+
+	r.Create(ctx, req, resp)
+
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected the failed restore to be reported")
+	}
+	// The installed node is kept in the state, Terraform taints it.
+	if id := getStringAttribute(t, resp.State, path.Root("id")); id.ValueString() != net.JoinHostPort(server.Host(), server.Port()) {
+		t.Errorf("expected the installed node to be saved, got the id: %s", id)
+	}
+}
+
+func TestMasterNodeResourceCreateStatusFailure(t *testing.T) {
+	ctx := context.Background()
+	server := newK3sServer(t, remote.NodeRoleServer)
+	server.Fail(`server/tls/\*\.crt`, "Permission denied\n", 1)
+
+	r := &YoshiK3SMasterNodeResource{}
+	s := resourceSchema(t, r)
+
+	req := resource.CreateRequest{
+		Plan: newPlan(t, s, map[string]attr.Value{
+			"cluster":            testCluster(types.StringNull()),
+			"node_connection":    testConnection(server),
+			"certificate_expiry": types.MapUnknown(types.StringType),
+		}),
+	}
+	resp := &resource.CreateResponse{
+		State:    emptyState(s),
+		Identity: emptyIdentity(resourceIdentitySchema(t, r)),
+	}
+
+	r.Create(ctx, req, resp)
+	requireNoErrors(t, resp.Diagnostics)
+
+	if resp.Diagnostics.WarningsCount() != 1 {
+		t.Errorf("expected a warning about the certificates, got: %v", resp.Diagnostics)
+	}
+	var expiry types.Map
+	requireNoErrors(t, resp.State.GetAttribute(ctx, path.Root("certificate_expiry"), &expiry))
+	if !expiry.IsNull() {
+		t.Errorf("expected the certificate expiry to be left null, got: %s", expiry)
+	}
+}
+
+func TestMasterNodeResourceCreateDryRun(t *testing.T) {
+	ctx := context.Background()
+	server := sshtest.NewServer(t)
+
+	transcript := filepath.Join(t.TempDir(), "transcript.log")
+	data := providerdata.New(executor.NewDryRunExecutor(executor.NewYoshiK3SExecutor(), transcript))
+
+	r := &YoshiK3SMasterNodeResource{providerData: data}
+	s := resourceSchema(t, r)
+
+	req := resource.CreateRequest{
+		Plan: newPlan(t, s, map[string]attr.Value{
+			"id":                              types.StringUnknown(),
+			"kubeconfig":                      types.StringUnknown(),
+			"certificate_expiry":              types.MapUnknown(types.StringType),
+			"cluster":                         testCluster(types.StringValue("agent-secret")),
+			"node_connection":                 testConnection(server),
+			"node_options":                    testOptions("--disable traefik"),
+			"certificate_expiry_warning_days": types.Int64Value(30),
+			"restore_from_snapshot": types.ObjectValueMust(
+				map[string]attr.Type{
+					"path": types.StringType,
+					"s3":   types.ObjectType{AttrTypes: model.YoshiK3SEtcdS3AttributeTypes},
+				},
+				map[string]attr.Value{
+					"path": types.StringValue("/var/lib/rancher/k3s/server/db/snapshots/pre-upgrade"),
+					"s3":   types.ObjectNull(model.YoshiK3SEtcdS3AttributeTypes),
+				},
+			),
+		}),
+	}
+	resp := &resource.CreateResponse{
+		State:    emptyState(s),
+		Identity: emptyIdentity(resourceIdentitySchema(t, r)),
+	}
+
+	r.Create(ctx, req, resp)
+
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected the dry run create to be reported as not applied")
+	}
+	if commands := server.Commands(); len(commands) != 0 {
+		t.Errorf("expected no command to be run during a dry run, got: %v", commands)
+	}
+
+	content, err := os.ReadFile(transcript)
+	if err != nil {
+		t.Fatalf("failed to read the transcript: %s", err)
+	}
+	for _, expected := range []string{
+		"write " + remote.AgentTokenConfigPath,
+		"server --disable traefik",
+		"$ systemctl stop k3s",
+		"--cluster-reset-restore-path='/var/lib/rancher/k3s/server/db/snapshots/pre-upgrade'",
+		"$ systemctl start k3s",
+	} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("expected the transcript to contain %q, got:\n%s", expected, content)
+		}
+	}
+	for _, secret := range []string{testClusterToken, "agent-secret"} {
+		if strings.Contains(string(content), secret) {
+			t.Errorf("expected %q to be redacted from the transcript, got:\n%s", secret, content)
+		}
+	}
+
+	if !resp.State.Raw.IsNull() {
+		t.Errorf("expected nothing to be saved to the state, got: %s", resp.State.Raw)
+	}
+}
+
+func TestMasterNodeResourceRead(t *testing.T) {
+	ctx := context.Background()
+	server := newK3sServer(t, remote.NodeRoleServer)
+	server.Respond(`^k3s --version$`, "k3s version v1.31.0+k3s1 (34be6d96)\n")
+
+	r := &YoshiK3SMasterNodeResource{}
+	s := resourceSchema(t, r)
+
+	state := newState(t, s, map[string]attr.Value{
+		"id":              types.StringValue(server.Host()),
+		"kubeconfig":      types.StringValue(testKubeconfig),
+		"cluster":         testCluster(types.StringNull()),
+		"node_connection": testConnection(server),
+		"node_options":    testOptions("--disable traefik"),
+	})
+	resp := &resource.ReadResponse{
+		State:    state,
+		Identity: emptyIdentity(resourceIdentitySchema(t, r)),
+	}
+
+	r.Read(ctx, resource.ReadRequest{State: state}, resp)
+	requireNoErrors(t, resp.Diagnostics)
+
+	version := getStringAttribute(t, resp.State, path.Root("cluster").AtName("k3s_version"))
+	if version.ValueString() != "v1.31.0+k3s1" {
+		t.Errorf("expected the upgraded K3S version to be refreshed, got: %s", version)
+	}
+}
+
+func TestMasterNodeResourceReadUninstalled(t *testing.T) {
+	ctx := context.Background()
+	server := newK3sServer(t, remote.NodeRoleNone)
+
+	r := &YoshiK3SMasterNodeResource{}
+	s := resourceSchema(t, r)
+
+	state := newState(t, s, map[string]attr.Value{
+		"id":              types.StringValue(server.Host()),
+		"cluster":         testCluster(types.StringNull()),
+		"node_connection": testConnection(server),
+	})
+	resp := &resource.ReadResponse{
+		State:    state,
+		Identity: emptyIdentity(resourceIdentitySchema(t, r)),
+	}
+
+	r.Read(ctx, resource.ReadRequest{State: state}, resp)
+	requireNoErrors(t, resp.Diagnostics)
+
+	if !resp.State.Raw.IsNull() {
+		t.Error("expected the uninstalled node to be removed from the state")
+	}
+}
+
+func TestMasterNodeResourceUpdate(t *testing.T) {
+	ctx := context.Background()
+	server := newK3sServer(t, remote.NodeRoleServer)
+
+	r := &YoshiK3SMasterNodeResource{}
+	s := resourceSchema(t, r)
+
+	attributes := map[string]attr.Value{
+		"id":              types.StringValue(server.Host()),
+		"cluster":         testCluster(types.StringNull()),
+		"node_connection": testConnection(server),
+		"node_options":    testOptions("--disable traefik"),
+	}
+	state := newState(t, s, attributes)
+
+	attributes["node_options"] = testOptions("--disable traefik", "--disable servicelb")
+	attributes["rotate_certificates"] = types.StringValue("2026-10")
+	plan := newPlan(t, s, attributes)
+
+	resp := &resource.UpdateResponse{
+		State:    emptyState(s),
+		Identity: emptyIdentity(resourceIdentitySchema(t, r)),
+	}
+
+	r.Update(ctx, resource.UpdateRequest{Plan: plan, State: state}, resp)
+	requireNoErrors(t, resp.Diagnostics)
+
+	install := requireCommand(t, server, `get\.k3s\.io`)
+	if !strings.HasSuffix(install.Command, "server --disable traefik --disable servicelb") {
+		t.Errorf("expected the install command to use the new options, got: %s", install.Command)
+	}
+	requireCommand(t, server, `k3s certificate rotate$`)
+}
+
+func TestMasterNodeResourceUpdateRenamedCluster(t *testing.T) {
+	ctx := context.Background()
+	server := newK3sServer(t, remote.NodeRoleServer)
+
+	r := &YoshiK3SMasterNodeResource{}
+	s := resourceSchema(t, r)
+
+	metadata := &resource.MetadataResponse{}
+	r.Metadata(ctx, resource.MetadataRequest{ProviderTypeName: "yoshik3s"}, metadata)
+	if !metadata.ResourceBehavior.MutableIdentity {
+		t.Error("expected the identity to be mutable, it follows the name of the cluster")
+	}
+
+	attributes := map[string]attr.Value{
+		"id":              types.StringValue(server.Host()),
+		"cluster":         testCluster(types.StringNull()),
+		"node_connection": testConnection(server),
+	}
+	state := newState(t, s, attributes)
+
+	renamed := make(map[string]attr.Value)
+	for name, value := range testCluster(types.StringNull()).Attributes() {
+		renamed[name] = value
+	}
+	renamed["name"] = types.StringValue("renamed-cluster")
+	attributes["cluster"] = types.ObjectValueMust(model.YoshiK3SNodeClusterAttributeTypes, renamed)
+	plan := newPlan(t, s, attributes)
+
+	resp := &resource.UpdateResponse{
+		State:    emptyState(s),
+		Identity: emptyIdentity(resourceIdentitySchema(t, r)),
+	}
+
+	r.Update(ctx, resource.UpdateRequest{Plan: plan, State: state}, resp)
+	requireNoErrors(t, resp.Diagnostics)
+
+	var identity model.YoshiK3SNodeIdentityModel
+	requireNoErrors(t, resp.Identity.Get(ctx, &identity))
+	if identity.ClusterName.ValueString() != "renamed-cluster" {
+		t.Errorf("expected the identity to hold the new cluster name, got: %s", identity.ClusterName)
+	}
+	if identity.Host.ValueString() != server.Host() || identity.Port.ValueString() != server.Port() {
+		t.Errorf("expected the identity to keep the node address, got: %s:%s", identity.Host, identity.Port)
+	}
+}
+
+func TestMasterNodeResourceDelete(t *testing.T) {
+	ctx := context.Background()
+	server := newK3sServer(t, remote.NodeRoleServer)
+
+	r := &YoshiK3SMasterNodeResource{}
+	s := resourceSchema(t, r)
+
+	state := newState(t, s, map[string]attr.Value{
+		"id":              types.StringValue(server.Host()),
+		"cluster":         testCluster(types.StringNull()),
+		"node_connection": testConnection(server),
+	})
+	resp := &resource.DeleteResponse{
+		State: state,
+	}
+
+	r.Delete(ctx, resource.DeleteRequest{State: state}, resp)
+	requireNoErrors(t, resp.Diagnostics)
+
+	requireCommand(t, server, `^sudo k3s-uninstall\.sh$`)
+}
+
+func TestMasterNodeResourceUpdateAndDeleteDryRun(t *testing.T) {
+	ctx := context.Background()
+	server := newK3sServer(t, remote.NodeRoleServer)
+
+	transcript := filepath.Join(t.TempDir(), "transcript.log")
+	data := providerdata.New(executor.NewDryRunExecutor(executor.NewYoshiK3SExecutor(), transcript))
+
+	r := &YoshiK3SMasterNodeResource{providerData: data}
+	s := resourceSchema(t, r)
+
+	attributes := map[string]attr.Value{
+		"id":              types.StringValue(server.Host()),
+		"kubeconfig":      types.StringValue(testKubeconfig),
+		"cluster":         testCluster(types.StringNull()),
+		"node_connection": testConnection(server),
+		"node_options":    testOptions("--disable traefik"),
+	}
+	state := newState(t, s, attributes)
+
+	attributes["node_options"] = testOptions("--disable traefik", "--disable servicelb")
+	attributes["rotate_certificates"] = types.StringValue("2026-10")
+	plan := newPlan(t, s, attributes)
+
+	updateResp := &resource.UpdateResponse{
+		State:    tfsdk.State{Schema: s, Raw: plan.Raw},
+		Identity: emptyIdentity(resourceIdentitySchema(t, r)),
+	}
+
+	r.Update(ctx, resource.UpdateRequest{Plan: plan, State: state}, updateResp)
+
+	if !updateResp.Diagnostics.HasError() {
+		t.Fatal("expected the dry run update to be reported as not applied")
+	}
+	if !updateResp.State.Raw.Equal(state.Raw) {
+		t.Errorf("expected the prior state to be kept, got: %s", updateResp.State.Raw)
+	}
+
+	deleteResp := &resource.DeleteResponse{
+		State: state,
+	}
+
+	r.Delete(ctx, resource.DeleteRequest{State: state}, deleteResp)
+
+	if !deleteResp.Diagnostics.HasError() {
+		t.Fatal("expected the dry run delete to be reported as not applied")
+	}
+
+	if commands := server.Received(`get\.k3s\.io|certificate rotate|uninstall`); len(commands) != 0 {
+		t.Errorf("expected no change to be run during a dry run, got: %v", commands)
+	}
+
+	content, err := os.ReadFile(transcript)
+	if err != nil {
+		t.Fatalf("failed to read the transcript: %s", err)
+	}
+	for _, expected := range []string{
+		"server --disable traefik --disable servicelb",
+		"$ k3s certificate rotate",
+		"$ sudo k3s-uninstall.sh",
+	} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("expected the transcript to contain %q, got:\n%s", expected, content)
+		}
+	}
+}
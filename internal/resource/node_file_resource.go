@@ -0,0 +1,356 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/executor"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/model"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/providerdata"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/remote"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"github.com/hashicorp/terraform-plugin-framework-validators/resourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &YoshiK3SNodeFileResource{}
+var _ resource.ResourceWithConfigure = &YoshiK3SNodeFileResource{}
+var _ resource.ResourceWithConfigValidators = &YoshiK3SNodeFileResource{}
+var _ resource.ResourceWithValidateConfig = &YoshiK3SNodeFileResource{}
+var _ resource.ResourceWithModifyPlan = &YoshiK3SNodeFileResource{}
+
+func NewYoshiK3SNodeFileResource() resource.Resource {
+	return &YoshiK3SNodeFileResource{}
+}
+
+// YoshiK3SNodeFileResource defines the resource implementation.
+type YoshiK3SNodeFileResource struct {
+	providerData *providerdata.Data
+}
+
+func (r *YoshiK3SNodeFileResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_node_file"
+}
+
+func (r *YoshiK3SNodeFileResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "K3S Node File Resource, uploads a file to a node, e.g. an audit policy or a kubelet configuration read by K3S.",
+
+		Version: model.YoshiK3SNodeFileResourceModelSchemaVersion,
+
+		Attributes: model.YoshiK3SNodeFileResourceModelSchema,
+	}
+}
+
+func (r *YoshiK3SNodeFileResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.providerData = configureProviderData(req.ProviderData, &resp.Diagnostics)
+}
+
+func (r *YoshiK3SNodeFileResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		resourcevalidator.ExactlyOneOf(
+			path.MatchRoot("node_connection").AtName("password"),
+			path.MatchRoot("node_connection").AtName("private_key"),
+		),
+		resourcevalidator.ExactlyOneOf(
+			path.MatchRoot("content"),
+			path.MatchRoot("sensitive_content"),
+		),
+	}
+}
+
+func (r *YoshiK3SNodeFileResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data model.YoshiK3SNodeFileResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(validateConnectionObject(ctx, path.Root("node_connection"), data.Connection)...)
+	resp.Diagnostics.Append(validateAbsolutePath(path.Root("path"), data.Path)...)
+	resp.Diagnostics.Append(validateFileMode(path.Root("mode"), data.Mode)...)
+	resp.Diagnostics.Append(validateFileOwner(path.Root("owner"), data.Owner)...)
+	resp.Diagnostics.Append(validateK3sService(path.Root("restart_service"), data.RestartService)...)
+}
+
+// ModifyPlan plans the checksum of the content, so a file modified on the node is planned as an update.
+func (r *YoshiK3SNodeFileResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to plan when the resource is being destroyed.
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan model.YoshiK3SNodeFileResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	if resp.Diagnostics.HasError() || plan.Content.IsUnknown() || plan.SensitiveContent.IsUnknown() {
+		return
+	}
+
+	checksum := types.StringValue(remote.Checksum(nodeFileContent(plan)))
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("checksum"), checksum)...)
+}
+
+func (r *YoshiK3SNodeFileResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = remote.WithCommandLogging(ctx, "yoshik3s_node_file", "create")
+
+	var data model.YoshiK3SNodeFileResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sshConfig := sshConfigFromConnection(ctx, data.Connection)
+	ctx = withConnectionSettings(ctx, r.providerData, sshConfig, data.Connection)
+	if sshConfig == nil {
+		resp.Diagnostics.AddError(
+			"Failed to create a node file",
+			"Invalid node configuration. Please check the node configuration.",
+		)
+		return
+	}
+
+	unlock, err := lockHosts(ctx, r.providerData, sshConfig)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to lock the node", err.Error())
+		return
+	}
+	defer unlock()
+
+	resp.Diagnostics.Append(uploadNodeFile(ctx, nodeExecutor(r.providerData), sshConfig, data, true)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if dryRunExecutor(r.providerData) != nil {
+		addDryRunError(&resp.Diagnostics)
+		return
+	}
+
+	tflog.Trace(ctx, "created a resource", map[string]interface{}{
+		"path": data.Path.ValueString(),
+	})
+	data.Id = types.StringValue(data.Path.ValueString())
+	data.Checksum = types.StringValue(remote.Checksum(nodeFileContent(data)))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *YoshiK3SNodeFileResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = remote.WithCommandLogging(ctx, "yoshik3s_node_file", "read")
+	ctx, cancel := withReadTimeout(ctx, r.providerData)
+	defer cancel()
+
+	var data model.YoshiK3SNodeFileResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sshConfig := sshConfigFromConnection(ctx, data.Connection)
+	ctx = withConnectionSettings(ctx, r.providerData, sshConfig, data.Connection)
+	if sshConfig == nil {
+		return
+	}
+
+	checksum, err := remote.FileChecksumAsRoot(executor.Commands(ctx, nodeExecutor(r.providerData), sshConfig), data.Path.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddWarning(
+			"Failed to refresh node file",
+			fmt.Sprintf("The file could not be read, keeping the previous state: %s", err.Error()),
+		)
+		return
+	}
+	if checksum == "" {
+		tflog.Warn(ctx, "node file no longer exists, removing it from the state", map[string]interface{}{
+			"path": data.Path.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	data.Checksum = types.StringValue(checksum)
+
+	attributes, err := remote.FileAttributesAsRoot(executor.Commands(ctx, nodeExecutor(r.providerData), sshConfig), data.Path.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddWarning(
+			"Failed to refresh node file",
+			fmt.Sprintf("The file attributes could not be read, keeping the previous mode and owner: %s", err.Error()),
+		)
+	} else if attributes != nil {
+		data.Mode = nodeFileMode(data.Mode, attributes)
+		data.Owner = nodeFileOwner(data.Owner, attributes)
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *YoshiK3SNodeFileResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = remote.WithCommandLogging(ctx, "yoshik3s_node_file", "update")
+
+	var data model.YoshiK3SNodeFileResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sshConfig := sshConfigFromConnection(ctx, data.Connection)
+	ctx = withConnectionSettings(ctx, r.providerData, sshConfig, data.Connection)
+	if sshConfig == nil {
+		resp.Diagnostics.AddError(
+			"Failed to update a node file",
+			"Invalid node configuration. Please check the node configuration.",
+		)
+		return
+	}
+
+	unlock, err := lockHosts(ctx, r.providerData, sshConfig)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to lock the node", err.Error())
+		return
+	}
+	defer unlock()
+
+	var priorChecksum types.String
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("checksum"), &priorChecksum)...)
+
+	checksum := remote.Checksum(nodeFileContent(data))
+	resp.Diagnostics.Append(uploadNodeFile(ctx, nodeExecutor(r.providerData), sshConfig, data, priorChecksum.ValueString() != checksum)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if dryRunExecutor(r.providerData) != nil {
+		resp.State.Raw = req.State.Raw
+		addDryRunError(&resp.Diagnostics)
+		return
+	}
+
+	data.Checksum = types.StringValue(checksum)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *YoshiK3SNodeFileResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = remote.WithCommandLogging(ctx, "yoshik3s_node_file", "delete")
+
+	var data model.YoshiK3SNodeFileResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sshConfig := sshConfigFromConnection(ctx, data.Connection)
+	ctx = withConnectionSettings(ctx, r.providerData, sshConfig, data.Connection)
+	if sshConfig == nil {
+		resp.Diagnostics.AddError(
+			"Failed to delete a node file",
+			"Invalid node configuration. Please check the node configuration.",
+		)
+		return
+	}
+
+	unlock, err := lockHosts(ctx, r.providerData, sshConfig)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to lock the node", err.Error())
+		return
+	}
+	defer unlock()
+
+	err = nodeExecutor(r.providerData).WriteFile(ctx, sshConfig, data.Path.ValueString(), nil, 0)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to delete a node file", err.Error())
+		return
+	}
+	if dryRunExecutor(r.providerData) != nil {
+		addDryRunError(&resp.Diagnostics)
+	}
+}
+
+// uploadNodeFile uploads the file and, when its content changed, restarts the configured K3S service so it
+// picks up the new content.
+func uploadNodeFile(ctx context.Context, exec executor.Executor, sshConfig *ssh_handler.SshConfig, data model.YoshiK3SNodeFileResourceModel, contentChanged bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	mode, err := strconv.ParseUint(data.Mode.ValueString(), 8, 32)
+	if err != nil {
+		diags.AddAttributeError(path.Root("mode"), "Invalid file mode", err.Error())
+		return diags
+	}
+
+	err = exec.UploadFile(ctx, sshConfig, data.Path.ValueString(), nodeFileContent(data), os.FileMode(mode), data.Owner.ValueString())
+	if err != nil {
+		diags.AddError("failed to upload a node file", err.Error())
+		return diags
+	}
+
+	if data.RestartService.IsNull() || !contentChanged {
+		return diags
+	}
+
+	tflog.Info(ctx, "restarting the k3s service after uploading a node file", map[string]interface{}{
+		"path":    data.Path.ValueString(),
+		"service": data.RestartService.ValueString(),
+	})
+	if err := remote.TryRestartService(executor.Commands(ctx, exec, sshConfig), data.RestartService.ValueString()); err != nil {
+		diags.AddError("failed to restart the k3s service", err.Error())
+	}
+
+	return diags
+}
+
+// nodeFileMode returns the mode of the file on the node, keeping the notation of the prior mode when it did not change.
+func nodeFileMode(prior types.String, attributes *remote.FileAttributes) types.String {
+	if mode, err := strconv.ParseUint(prior.ValueString(), 8, 32); err == nil && uint32(mode) == attributes.Mode {
+		return prior
+	}
+
+	return types.StringValue(fmt.Sprintf("%04o", attributes.Mode))
+}
+
+// nodeFileOwner returns the owner of the file on the node, keeping the prior owner when it matches by name or by id.
+// The group is only compared when the prior owner has one.
+func nodeFileOwner(prior types.String, attributes *remote.FileAttributes) types.String {
+	user, group, hasGroup := strings.Cut(prior.ValueString(), ":")
+
+	userMatches := user == attributes.User || user == attributes.UserId
+	groupMatches := !hasGroup || group == attributes.Group || group == attributes.GroupId
+	if userMatches && groupMatches {
+		return prior
+	}
+
+	if !hasGroup {
+		return types.StringValue(attributes.User)
+	}
+	return types.StringValue(attributes.User + ":" + attributes.Group)
+}
+
+func nodeFileContent(data model.YoshiK3SNodeFileResourceModel) []byte {
+	if !data.SensitiveContent.IsNull() {
+		return []byte(data.SensitiveContent.ValueString())
+	}
+
+	return []byte(data.Content.ValueString())
+}
@@ -0,0 +1,85 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/model"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/remote"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// secretsEncryptionProviders lists the encryption providers supported by K3S.
+var secretsEncryptionProviders = map[string]bool{
+	"aescbc":    true,
+	"secretbox": true,
+}
+
+// secretsEncryptionFlags lists the flags rendered from the secrets_encryption attribute of the master nodes.
+var secretsEncryptionFlags = map[string]bool{
+	"secrets-encryption":          true,
+	"secrets-encryption-provider": true,
+}
+
+// secretsEncryptionConfigFromModel returns nil when the secrets encryption is not enabled.
+func secretsEncryptionConfigFromModel(ctx context.Context, encryption types.Object) (*remote.SecretsEncryptionConfig, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if encryption.IsNull() || encryption.IsUnknown() {
+		return nil, diags
+	}
+
+	var encryptionModel model.YoshiK3SSecretsEncryptionModel
+	diags.Append(encryption.As(ctx, &encryptionModel, basetypes.ObjectAsOptions{})...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	return &remote.SecretsEncryptionConfig{
+		Provider: encryptionModel.Provider.ValueString(),
+	}, diags
+}
+
+// readSecretsEncryptionStatus returns the secrets encryption status of the node, or null when it is not enabled.
+func readSecretsEncryptionStatus(commands remote.CommandRunner, encryption types.Object) (types.String, error) {
+	if encryption.IsNull() {
+		return types.StringNull(), nil
+	}
+
+	status, err := remote.ReadSecretsEncryptionStatus(commands)
+	if err != nil {
+		return types.StringNull(), err
+	}
+
+	return types.StringValue(status.Status), nil
+}
+
+// validateSecretsEncryptionObject validates the secrets_encryption attribute of the master nodes, which must
+// not be combined with node options setting the same K3S flags.
+func validateSecretsEncryptionObject(ctx context.Context, attributePath path.Path, encryption types.Object, optionsPath path.Path, options types.List) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if encryption.IsNull() || encryption.IsUnknown() {
+		return diags
+	}
+
+	var encryptionModel model.YoshiK3SSecretsEncryptionModel
+	diags.Append(encryption.As(ctx, &encryptionModel, basetypes.ObjectAsOptions{})...)
+	if diags.HasError() {
+		return diags
+	}
+
+	if !encryptionModel.Provider.IsNull() && !encryptionModel.Provider.IsUnknown() && !secretsEncryptionProviders[encryptionModel.Provider.ValueString()] {
+		diags.AddAttributeError(
+			attributePath.AtName("provider"),
+			"Invalid secrets encryption provider",
+			fmt.Sprintf("The provider must be either aescbc or secretbox, got: %q.", encryptionModel.Provider.ValueString()),
+		)
+	}
+
+	diags.Append(validateConflictingNodeOptions(ctx, optionsPath, options, "secrets_encryption", secretsEncryptionFlags)...)
+
+	return diags
+}
@@ -0,0 +1,220 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/executor"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/model"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/providerdata"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/remote"
+	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"time"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &YoshiK3SSecretsEncryptionRotationResource{}
+var _ resource.ResourceWithConfigure = &YoshiK3SSecretsEncryptionRotationResource{}
+var _ resource.ResourceWithValidateConfig = &YoshiK3SSecretsEncryptionRotationResource{}
+
+// secretsReencryptionTimeout bounds the wait for the secrets to be reencrypted with the new key.
+const secretsReencryptionTimeout = 10 * time.Minute
+
+func NewYoshiK3SSecretsEncryptionRotationResource() resource.Resource {
+	return &YoshiK3SSecretsEncryptionRotationResource{}
+}
+
+// YoshiK3SSecretsEncryptionRotationResource defines the resource implementation.
+type YoshiK3SSecretsEncryptionRotationResource struct {
+	providerData *providerdata.Data
+}
+
+func (r *YoshiK3SSecretsEncryptionRotationResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_secrets_encryption_rotation"
+}
+
+func (r *YoshiK3SSecretsEncryptionRotationResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "K3S Secrets Encryption Rotation Resource, rotates the secrets encryption key of a cluster " +
+			"when created or when its triggers change, running prepare, rotate and reencrypt across every master node.",
+
+		Version: model.YoshiK3SSecretsEncryptionRotationResourceModelSchemaVersion,
+
+		Attributes: model.YoshiK3SSecretsEncryptionRotationResourceModelSchema,
+	}
+}
+
+func (r *YoshiK3SSecretsEncryptionRotationResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.providerData = configureProviderData(req.ProviderData, &resp.Diagnostics)
+}
+
+func (r *YoshiK3SSecretsEncryptionRotationResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data model.YoshiK3SSecretsEncryptionRotationResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() || data.Connections.IsNull() || data.Connections.IsUnknown() {
+		return
+	}
+
+	connections := make([]types.Object, 0, len(data.Connections.Elements()))
+	resp.Diagnostics.Append(data.Connections.ElementsAs(ctx, &connections, false)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if len(connections) == 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("node_connections"),
+			"Missing master nodes",
+			"At least one master node connection is required.",
+		)
+	}
+
+	for index, connection := range connections {
+		resp.Diagnostics.Append(validateConnectionObject(ctx, path.Root("node_connections").AtListIndex(index), connection)...)
+		resp.Diagnostics.Append(validateConnectionCredentials(ctx, path.Root("node_connections").AtListIndex(index), connection)...)
+	}
+}
+
+func (r *YoshiK3SSecretsEncryptionRotationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = remote.WithCommandLogging(ctx, "yoshik3s_secrets_encryption_rotation", "create")
+
+	var data model.YoshiK3SSecretsEncryptionRotationResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	connections := make([]types.Object, 0, len(data.Connections.Elements()))
+	resp.Diagnostics.Append(data.Connections.ElementsAs(ctx, &connections, false)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	servers := make([]*ssh_handler.SshConfig, 0, len(connections))
+	for _, connection := range connections {
+		sshConfig := sshConfigFromConnection(ctx, connection)
+		if sshConfig == nil {
+			resp.Diagnostics.AddError(
+				"Failed to rotate the secrets encryption key",
+				"Invalid node configuration. Please check the node configuration.",
+			)
+			return
+		}
+		servers = append(servers, sshConfig)
+		ctx = withConnectionSettings(ctx, r.providerData, sshConfig, connection)
+	}
+
+	unlock, err := lockHosts(ctx, r.providerData, servers...)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to lock the nodes", err.Error())
+		return
+	}
+	defer unlock()
+
+	exec := nodeExecutor(r.providerData)
+	stage, err := rotateSecretsEncryption(ctx, exec, servers)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to rotate the secrets encryption key", err.Error())
+		return
+	}
+	if isDryRun(exec) {
+		addDryRunError(&resp.Diagnostics)
+		return
+	}
+
+	tflog.Trace(ctx, "created a resource")
+	data.Id = types.StringValue(time.Now().UTC().Format(time.RFC3339))
+	data.Stage = types.StringValue(stage)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Read keeps the state as is, the rotation is a one-off operation.
+func (r *YoshiK3SSecretsEncryptionRotationResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data model.YoshiK3SSecretsEncryptionRotationResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Update only stores the new connection details, a new rotation is triggered by replacing the resource.
+func (r *YoshiK3SSecretsEncryptionRotationResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data model.YoshiK3SSecretsEncryptionRotationResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Delete only removes the resource from the state, the rotated key stays in use.
+func (r *YoshiK3SSecretsEncryptionRotationResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+}
+
+// rotateSecretsEncryption rotates the secrets encryption key following the K3S procedure for clusters with
+// multiple servers: every stage is run on the first server, then every server is restarted in order so
+// they all load the new encryption configuration before the next stage. A dry run does not wait for the stages.
+func rotateSecretsEncryption(ctx context.Context, exec executor.Executor, servers []*ssh_handler.SshConfig) (string, error) {
+	primary := executor.Commands(ctx, exec, servers[0])
+	dryRun := isDryRun(exec)
+
+	for _, stage := range []string{"prepare", "rotate", "reencrypt"} {
+		tflog.Info(ctx, "rotating the secrets encryption key", map[string]interface{}{
+			"host":  servers[0].GetHost(),
+			"stage": stage,
+		})
+		if err := remote.SecretsEncrypt(primary, stage); err != nil {
+			return "", err
+		}
+
+		if stage == "reencrypt" && !dryRun {
+			tflog.Info(ctx, "waiting for the secrets to be reencrypted", map[string]interface{}{
+				"host": servers[0].GetHost(),
+			})
+			if err := remote.WaitForSecretsEncryptionStage(primary, "reencrypt_finished", secretsReencryptionTimeout, 5*time.Second); err != nil {
+				return "", err
+			}
+		}
+
+		for _, server := range servers {
+			tflog.Info(ctx, "restarting k3s to load the secrets encryption configuration", map[string]interface{}{
+				"host":  server.GetHost(),
+				"stage": stage,
+			})
+			if err := remote.RestartK3s(executor.Commands(ctx, exec, server)); err != nil {
+				return "", fmt.Errorf("%s stage: %w", stage, err)
+			}
+		}
+	}
+
+	if dryRun {
+		return "", nil
+	}
+
+	status, err := remote.ReadSecretsEncryptionStatus(primary)
+	if err != nil {
+		return "", err
+	}
+
+	return status.Stage, nil
+}
@@ -0,0 +1,104 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"net"
+	"strconv"
+)
+
+// rawStateUpgrade rewrites, in place, the JSON state written by a prior schema version.
+type rawStateUpgrade func(state map[string]interface{})
+
+// newRawStateUpgrader returns a StateUpgrader applying the upgrades in order. Working on the raw JSON
+// state avoids keeping a copy of every prior schema, and lets the upgraders of older versions be chained
+// with the ones of newer versions.
+func newRawStateUpgrader(upgrades ...rawStateUpgrade) resource.StateUpgrader {
+	return resource.StateUpgrader{
+		StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+			if req.RawState == nil || req.RawState.JSON == nil {
+				resp.Diagnostics.AddError(
+					"Unable to upgrade resource state",
+					"The prior state is not stored in the JSON format, it was likely written by an unsupported Terraform version.",
+				)
+				return
+			}
+
+			var state map[string]interface{}
+			if err := json.Unmarshal(req.RawState.JSON, &state); err != nil {
+				resp.Diagnostics.AddError("Unable to upgrade resource state", err.Error())
+				return
+			}
+
+			for _, upgrade := range upgrades {
+				upgrade(state)
+			}
+
+			upgradedState, err := json.Marshal(state)
+			if err != nil {
+				resp.Diagnostics.AddError("Unable to upgrade resource state", err.Error())
+				return
+			}
+
+			resp.DynamicValue = &tfprotov6.DynamicValue{
+				JSON: upgradedState,
+			}
+		},
+	}
+}
+
+// unquoteId removes the quotes added to the id by versions that stored the output of types.String.String().
+func unquoteId(state map[string]interface{}) {
+	id, ok := state["id"].(string)
+	if !ok {
+		return
+	}
+
+	if unquotedId, err := strconv.Unquote(id); err == nil {
+		state["id"] = unquotedId
+	}
+}
+
+// unquoteNestedClusterId removes the quotes from the id of the cluster object nested in the node resources,
+// which was copied from the quoted id of the cluster resource.
+func unquoteNestedClusterId(state map[string]interface{}) {
+	if cluster, ok := state["cluster"].(map[string]interface{}); ok {
+		unquoteId(cluster)
+	}
+}
+
+// replaceNullClusterId replaces the "<null>" id stored for unnamed clusters with the cluster address.
+func replaceNullClusterId(state map[string]interface{}) {
+	if state["id"] != "<null>" {
+		return
+	}
+
+	if address, ok := state["address"].(string); ok {
+		state["id"] = address
+	}
+}
+
+// nodeAddressId replaces the id of the node resources, which was their host, with the host and port of their
+// connection, so that the nodes sharing a host through different ports get different ids.
+func nodeAddressId(state map[string]interface{}) {
+	connection, ok := state["node_connection"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	host, hostOk := connection["host"].(string)
+	port, portOk := connection["port"].(string)
+	if hostOk && portOk {
+		state["id"] = net.JoinHostPort(host, port)
+	}
+}
+
+// removeNestedCertificateAuthority removes the certificate authorities from the cluster object nested in the worker
+// nodes, which do not use them.
+func removeNestedCertificateAuthority(state map[string]interface{}) {
+	if cluster, ok := state["cluster"].(map[string]interface{}); ok {
+		delete(cluster, "certificate_authority")
+	}
+}
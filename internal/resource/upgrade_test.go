@@ -0,0 +1,150 @@
+package resource
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUpgradeState(t *testing.T) {
+	testCases := map[string]struct {
+		resource       resource.ResourceWithUpgradeState
+		fixture        string
+		version        int64
+		expectedId     string
+		expectedNested string
+	}{
+		"cluster_v0": {
+			resource:   &YoshiK3SClusterResource{},
+			fixture:    "cluster_v0.json",
+			version:    0,
+			expectedId: "example-cluster",
+		},
+		"cluster_v0_unnamed": {
+			resource:   &YoshiK3SClusterResource{},
+			fixture:    "cluster_v0_unnamed.json",
+			version:    0,
+			expectedId: "master_node",
+		},
+		"master_node_v0": {
+			resource:       &YoshiK3SMasterNodeResource{},
+			fixture:        "master_node_v0.json",
+			version:        0,
+			expectedId:     "localhost:2222",
+			expectedNested: "example-cluster",
+		},
+		"worker_node_v0": {
+			resource:       &YoshiK3SWorkerNodeResource{},
+			fixture:        "worker_node_v0.json",
+			version:        0,
+			expectedId:     "localhost:3333",
+			expectedNested: "example-cluster",
+		},
+		"master_node_v1": {
+			resource:       &YoshiK3SMasterNodeResource{},
+			fixture:        "master_node_v1.json",
+			version:        1,
+			expectedId:     "localhost:2222",
+			expectedNested: "example-cluster",
+		},
+		"worker_node_v1": {
+			resource:       &YoshiK3SWorkerNodeResource{},
+			fixture:        "worker_node_v1.json",
+			version:        1,
+			expectedId:     "localhost:3333",
+			expectedNested: "example-cluster",
+		},
+		"worker_node_v2": {
+			resource:       &YoshiK3SWorkerNodeResource{},
+			fixture:        "worker_node_v2.json",
+			version:        2,
+			expectedId:     "localhost:3333",
+			expectedNested: "example-cluster",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			fixture, err := os.ReadFile(filepath.Join("testdata", testCase.fixture))
+			if err != nil {
+				t.Fatalf("failed to read fixture: %s", err)
+			}
+
+			upgrader, ok := testCase.resource.UpgradeState(ctx)[testCase.version]
+			if !ok {
+				t.Fatalf("no state upgrader for version %d", testCase.version)
+			}
+
+			req := resource.UpgradeStateRequest{
+				RawState: &tfprotov6.RawState{JSON: fixture},
+			}
+			resp := resource.UpgradeStateResponse{}
+			upgrader.StateUpgrader(ctx, req, &resp)
+
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+			}
+			if resp.DynamicValue == nil {
+				t.Fatal("expected the upgraded state to be returned")
+			}
+
+			schemaResp := resource.SchemaResponse{}
+			testCase.resource.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+
+			// The upgraded state must be decodable with the current schema.
+			upgradedState, err := resp.DynamicValue.Unmarshal(schemaResp.Schema.Type().TerraformType(ctx))
+			if err != nil {
+				t.Fatalf("upgraded state does not match the current schema: %s", err)
+			}
+
+			var attributes map[string]tftypes.Value
+			if err := upgradedState.As(&attributes); err != nil {
+				t.Fatalf("failed to read upgraded state: %s", err)
+			}
+
+			var id string
+			if err := attributes["id"].As(&id); err != nil {
+				t.Fatalf("failed to read id: %s", err)
+			}
+			if id != testCase.expectedId {
+				t.Errorf("expected id %q, got %q", testCase.expectedId, id)
+			}
+
+			if testCase.expectedNested == "" {
+				return
+			}
+
+			var cluster map[string]tftypes.Value
+			if err := attributes["cluster"].As(&cluster); err != nil {
+				t.Fatalf("failed to read cluster: %s", err)
+			}
+
+			var clusterId string
+			if err := cluster["id"].As(&clusterId); err != nil {
+				t.Fatalf("failed to read cluster id: %s", err)
+			}
+			if clusterId != testCase.expectedNested {
+				t.Errorf("expected cluster id %q, got %q", testCase.expectedNested, clusterId)
+			}
+		})
+	}
+}
+
+func TestUpgradeState_RequiresJSON(t *testing.T) {
+	upgrader := newRawStateUpgrader(unquoteId)
+
+	resp := resource.UpgradeStateResponse{}
+	upgrader.StateUpgrader(context.Background(), resource.UpgradeStateRequest{
+		RawState: &tfprotov6.RawState{Flatmap: map[string]string{"id": "\"localhost\""}},
+	}, &resp)
+
+	if !resp.Diagnostics.HasError() {
+		t.Error("expected an error for flatmap states")
+	}
+}
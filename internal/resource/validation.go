@@ -0,0 +1,685 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/model"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/robfig/cron/v3"
+	"gopkg.in/yaml.v3"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// k3sVersionRegex matches released K3S versions, e.g. v1.30.2+k3s2 or v1.31.0-rc1+k3s1.
+var k3sVersionRegex = regexp.MustCompile(`^v\d+\.\d+\.\d+(-rc\d+)?\+k3s\d+$`)
+
+// hostnameLabelRegex matches a single DNS label. Underscores are accepted
+// because container runtimes commonly resolve service names such as master_node.
+var hostnameLabelRegex = regexp.MustCompile(`^[a-zA-Z0-9_]([a-zA-Z0-9_-]{0,61}[a-zA-Z0-9_])?$`)
+
+// manifestNameRegex matches the names of the manifests deployed in the K3S manifests directory.
+var manifestNameRegex = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9._-]{0,251}[a-zA-Z0-9])?$`)
+
+// kubernetesNameRegex matches the DNS subdomain names of Kubernetes objects.
+var kubernetesNameRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9.]{0,251}[a-z0-9])?$`)
+
+// fileModeRegex matches file permissions in octal notation, e.g. 0644 or 600.
+var fileModeRegex = regexp.MustCompile(`^0?[0-7]{3}$`)
+
+// fileOwnerRegex matches the `user` or `user:group` arguments of chown, by name or id.
+var fileOwnerRegex = regexp.MustCompile(`^[a-z_0-9][a-z0-9_.-]*\$?(:[a-z_0-9][a-z0-9_.-]*\$?)?$`)
+
+// k3sServices lists the systemd services installed by K3S.
+var k3sServices = map[string]bool{
+	"k3s":       true,
+	"k3s-agent": true,
+}
+
+// k3sPackagedManifests lists the manifests written by K3S itself, which are overwritten on every start.
+var k3sPackagedManifests = map[string]bool{
+	"ccm":            true,
+	"coredns":        true,
+	"local-storage":  true,
+	"metrics-server": true,
+	"rolebindings":   true,
+	"runtimes":       true,
+	"traefik":        true,
+}
+
+// k3sAgentFlags lists the flags accepted by `k3s agent`.
+var k3sAgentFlags = map[string]bool{
+	"config":                            true,
+	"debug":                             true,
+	"v":                                 true,
+	"vmodule":                           true,
+	"log":                               true,
+	"alsologtostderr":                   true,
+	"token":                             true,
+	"token-file":                        true,
+	"server":                            true,
+	"data-dir":                          true,
+	"node-name":                         true,
+	"with-node-id":                      true,
+	"node-label":                        true,
+	"node-taint":                        true,
+	"image-credential-provider-bin-dir": true,
+	"image-credential-provider-config":  true,
+	"docker":                            true,
+	"container-runtime-endpoint":        true,
+	"default-runtime":                   true,
+	"image-service-endpoint":            true,
+	"disable-default-registry-endpoint": true,
+	"nonroot-devices":                   true,
+	"pause-image":                       true,
+	"snapshotter":                       true,
+	"private-registry":                  true,
+	"system-default-registry":           true,
+	"node-ip":                           true,
+	"node-external-ip":                  true,
+	"node-internal-dns":                 true,
+	"node-external-dns":                 true,
+	"resolv-conf":                       true,
+	"flannel-iface":                     true,
+	"flannel-conf":                      true,
+	"flannel-cni-conf-file":             true,
+	"kubelet-arg":                       true,
+	"kube-proxy-arg":                    true,
+	"protect-kernel-defaults":           true,
+	"selinux":                           true,
+	"lb-server-port":                    true,
+	"rootless":                          true,
+	"prefer-bundled-bin":                true,
+	"vpn-auth":                          true,
+	"vpn-auth-file":                     true,
+	"disable-apiserver-lb":              true,
+	"enable-pprof":                      true,
+	"airgap-extra-registry":             true,
+	"c":                                 true,
+	"t":                                 true,
+	"s":                                 true,
+	"d":                                 true,
+}
+
+// k3sServerOnlyFlags lists the flags accepted by `k3s server` on top of the agent flags.
+var k3sServerOnlyFlags = map[string]bool{
+	"bind-address":                      true,
+	"https-listen-port":                 true,
+	"supervisor-port":                   true,
+	"apiserver-port":                    true,
+	"apiserver-bind-address":            true,
+	"advertise-address":                 true,
+	"advertise-port":                    true,
+	"tls-san":                           true,
+	"tls-san-security":                  true,
+	"cluster-cidr":                      true,
+	"service-cidr":                      true,
+	"service-node-port-range":           true,
+	"cluster-dns":                       true,
+	"cluster-domain":                    true,
+	"flannel-backend":                   true,
+	"flannel-ipv6-masq":                 true,
+	"flannel-external-ip":               true,
+	"egress-selector-mode":              true,
+	"servicelb-namespace":               true,
+	"write-kubeconfig":                  true,
+	"write-kubeconfig-mode":             true,
+	"write-kubeconfig-group":            true,
+	"helm-job-image":                    true,
+	"agent-token":                       true,
+	"agent-token-file":                  true,
+	"cluster-init":                      true,
+	"cluster-reset":                     true,
+	"cluster-reset-restore-path":        true,
+	"kube-apiserver-arg":                true,
+	"etcd-arg":                          true,
+	"kube-controller-manager-arg":       true,
+	"kube-scheduler-arg":                true,
+	"kube-cloud-controller-manager-arg": true,
+	"datastore-endpoint":                true,
+	"datastore-cafile":                  true,
+	"datastore-certfile":                true,
+	"datastore-keyfile":                 true,
+	"etcd-expose-metrics":               true,
+	"etcd-disable-snapshots":            true,
+	"etcd-snapshot-name":                true,
+	"etcd-snapshot-schedule-cron":       true,
+	"etcd-snapshot-retention":           true,
+	"etcd-snapshot-dir":                 true,
+	"etcd-snapshot-compress":            true,
+	"etcd-s3":                           true,
+	"etcd-s3-endpoint":                  true,
+	"etcd-s3-endpoint-ca":               true,
+	"etcd-s3-skip-ssl-verify":           true,
+	"etcd-s3-access-key":                true,
+	"etcd-s3-secret-key":                true,
+	"etcd-s3-bucket":                    true,
+	"etcd-s3-region":                    true,
+	"etcd-s3-folder":                    true,
+	"etcd-s3-insecure":                  true,
+	"etcd-s3-timeout":                   true,
+	"etcd-s3-proxy":                     true,
+	"etcd-s3-config-secret":             true,
+	"default-local-storage-path":        true,
+	"disable":                           true,
+	"disable-scheduler":                 true,
+	"disable-cloud-controller":          true,
+	"disable-kube-proxy":                true,
+	"disable-network-policy":            true,
+	"disable-helm-controller":           true,
+	"disable-apiserver":                 true,
+	"disable-controller-manager":        true,
+	"disable-etcd":                      true,
+	"embedded-registry":                 true,
+	"supervisor-metrics":                true,
+	"secrets-encryption":                true,
+	"secrets-encryption-provider":       true,
+}
+
+// etcdSnapshotsFlags lists the flags rendered from the etcd_snapshots attribute of the master nodes.
+var etcdSnapshotsFlags = map[string]bool{
+	"etcd-snapshot-schedule-cron": true,
+	"etcd-snapshot-retention":     true,
+	"etcd-snapshot-dir":           true,
+	"etcd-snapshot-compress":      true,
+	"etcd-s3":                     true,
+	"etcd-s3-endpoint":            true,
+	"etcd-s3-bucket":              true,
+	"etcd-s3-folder":              true,
+	"etcd-s3-region":              true,
+	"etcd-s3-access-key":          true,
+	"etcd-s3-secret-key":          true,
+	"etcd-s3-insecure":            true,
+	"etcd-s3-skip-ssl-verify":     true,
+}
+
+// agentTokenFlags lists the flags rendered from the agent_token attribute of the clusters.
+var agentTokenFlags = map[string]bool{
+	"agent-token":      true,
+	"agent-token-file": true,
+}
+
+func isK3sServerFlag(flag string) bool {
+	return k3sServerOnlyFlags[flag] || k3sAgentFlags[flag]
+}
+
+func isK3sAgentFlag(flag string) bool {
+	return k3sAgentFlags[flag]
+}
+
+// validateK3sVersion checks that the value is a released K3S version.
+func validateK3sVersion(attributePath path.Path, value types.String) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if value.IsNull() || value.IsUnknown() {
+		return diags
+	}
+
+	if !k3sVersionRegex.MatchString(value.ValueString()) {
+		diags.AddAttributeError(
+			attributePath,
+			"Invalid K3S version",
+			fmt.Sprintf("The K3S version must follow the format v<major>.<minor>.<patch>+k3s<revision> (e.g. v1.30.2+k3s2), got: %q.", value.ValueString()),
+		)
+	}
+
+	return diags
+}
+
+// validateAddress checks that the value is a valid IP address or host name.
+func validateAddress(attributePath path.Path, value types.String) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if value.IsNull() || value.IsUnknown() {
+		return diags
+	}
+
+	if !isValidAddress(value.ValueString()) {
+		diags.AddAttributeError(
+			attributePath,
+			"Invalid address",
+			fmt.Sprintf("The address must be a valid IP address or host name, got: %q.", value.ValueString()),
+		)
+	}
+
+	return diags
+}
+
+// validatePort checks that the value is a number between 1 and 65535.
+func validatePort(attributePath path.Path, value types.String) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if value.IsNull() || value.IsUnknown() {
+		return diags
+	}
+
+	port, err := strconv.Atoi(value.ValueString())
+	if err != nil || port < 1 || port > 65535 {
+		diags.AddAttributeError(
+			attributePath,
+			"Invalid port",
+			fmt.Sprintf("The port must be a number between 1 and 65535, got: %q.", value.ValueString()),
+		)
+	}
+
+	return diags
+}
+
+// validateNodeOptions checks that every option starts with a flag known to K3S.
+func validateNodeOptions(ctx context.Context, attributePath path.Path, options types.List, isKnownFlag func(string) bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if options.IsNull() || options.IsUnknown() {
+		return diags
+	}
+
+	elements := make([]types.String, 0, len(options.Elements()))
+	diags.Append(options.ElementsAs(ctx, &elements, true)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	for index, element := range elements {
+		if element.IsNull() || element.IsUnknown() {
+			continue
+		}
+
+		flag, ok := parseNodeOptionFlag(element.ValueString())
+		if !ok {
+			diags.AddAttributeError(
+				attributePath.AtListIndex(index),
+				"Invalid node option",
+				fmt.Sprintf("Node options must start with a K3S flag (e.g. \"--node-label key=value\"), got: %q.", element.ValueString()),
+			)
+			continue
+		}
+
+		if !isKnownFlag(flag) {
+			diags.AddAttributeError(
+				attributePath.AtListIndex(index),
+				"Unknown node option",
+				fmt.Sprintf("The flag %q is not supported by K3S for this node type.", flag),
+			)
+		}
+	}
+
+	return diags
+}
+
+// validateWorkerClusterToken checks that the cluster nested in a worker node has a token to join with, either the
+// cluster token or the agent token.
+func validateWorkerClusterToken(ctx context.Context, attributePath path.Path, cluster types.Object) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if cluster.IsNull() || cluster.IsUnknown() {
+		return diags
+	}
+
+	clusterModel, clusterDiags := parseClusterObject(ctx, cluster)
+	diags.Append(clusterDiags...)
+	if diags.HasError() {
+		return diags
+	}
+
+	if clusterModel.ClusterToken.IsNull() && clusterModel.AgentToken.IsNull() {
+		diags.AddAttributeError(
+			attributePath.AtName("token"),
+			"Missing cluster token",
+			"The worker node needs a token to join the cluster, set either token or agent_token.",
+		)
+	}
+
+	return diags
+}
+
+// validateClusterObject validates the nested cluster attribute of the node resources.
+func validateClusterObject(ctx context.Context, attributePath path.Path, cluster types.Object) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if cluster.IsNull() || cluster.IsUnknown() {
+		return diags
+	}
+
+	clusterModel, clusterDiags := parseClusterObject(ctx, cluster)
+	diags.Append(clusterDiags...)
+	if diags.HasError() {
+		return diags
+	}
+
+	diags.Append(validateAddress(attributePath.AtName("address"), clusterModel.ClusterAddress)...)
+	diags.Append(validateK3sVersion(attributePath.AtName("k3s_version"), clusterModel.ClusterVersion)...)
+	diags.Append(validateCertificateAuthorityObject(ctx, attributePath.AtName("certificate_authority"), clusterModel.CertificateAuthority)...)
+
+	return diags
+}
+
+// validateConnectionObject validates the nested node_connection attribute of the node resources.
+func validateConnectionObject(ctx context.Context, attributePath path.Path, connection types.Object) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if connection.IsNull() || connection.IsUnknown() {
+		return diags
+	}
+
+	var connectionModel model.YoshiK3SConnectionModel
+	diags.Append(connection.As(ctx, &connectionModel, basetypes.ObjectAsOptions{})...)
+	if diags.HasError() {
+		return diags
+	}
+
+	diags.Append(validateAddress(attributePath.AtName("host"), connectionModel.Host)...)
+	diags.Append(validatePort(attributePath.AtName("port"), connectionModel.Port)...)
+
+	if !connectionModel.PrivateKeyPassphrase.IsNull() && connectionModel.PrivateKey.IsNull() {
+		diags.AddAttributeError(
+			attributePath.AtName("private_key_passphrase"),
+			"Missing private key",
+			"The private_key_passphrase attribute can only be used together with private_key.",
+		)
+	}
+
+	if _, err := retryPolicyFromObject(ctx, connectionModel.ConnectionRetry); err != nil {
+		diags.AddAttributeError(attributePath.AtName("connection_retry"), "Invalid connection retry settings", err.Error())
+	}
+	if _, err := becomeFromObject(ctx, connectionModel.Become); err != nil {
+		diags.AddAttributeError(attributePath.AtName("become"), "Invalid become settings", err.Error())
+	}
+
+	return diags
+}
+
+// validateEtcdSnapshotsObject validates the etcd_snapshots attribute of the master nodes, which must
+// not be combined with node options setting the same K3S flags.
+func validateEtcdSnapshotsObject(ctx context.Context, attributePath path.Path, snapshots types.Object, optionsPath path.Path, options types.List) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if snapshots.IsNull() || snapshots.IsUnknown() {
+		return diags
+	}
+
+	var snapshotsModel model.YoshiK3SEtcdSnapshotsModel
+	diags.Append(snapshots.As(ctx, &snapshotsModel, basetypes.ObjectAsOptions{})...)
+	if diags.HasError() {
+		return diags
+	}
+
+	if !snapshotsModel.ScheduleCron.IsNull() && !snapshotsModel.ScheduleCron.IsUnknown() {
+		if _, err := cron.ParseStandard(snapshotsModel.ScheduleCron.ValueString()); err != nil {
+			diags.AddAttributeError(
+				attributePath.AtName("schedule_cron"),
+				"Invalid cron expression",
+				fmt.Sprintf("The schedule must be a standard cron expression (e.g. \"0 */12 * * *\"), got: %q: %s.", snapshotsModel.ScheduleCron.ValueString(), err.Error()),
+			)
+		}
+	}
+
+	if !snapshotsModel.Retention.IsNull() && !snapshotsModel.Retention.IsUnknown() && snapshotsModel.Retention.ValueInt64() < 1 {
+		diags.AddAttributeError(
+			attributePath.AtName("retention"),
+			"Invalid retention",
+			fmt.Sprintf("The retention must be at least 1, got: %d.", snapshotsModel.Retention.ValueInt64()),
+		)
+	}
+
+	if !snapshotsModel.Directory.IsNull() && !snapshotsModel.Directory.IsUnknown() && !strings.HasPrefix(snapshotsModel.Directory.ValueString(), "/") {
+		diags.AddAttributeError(
+			attributePath.AtName("directory"),
+			"Invalid directory",
+			fmt.Sprintf("The directory must be an absolute path, got: %q.", snapshotsModel.Directory.ValueString()),
+		)
+	}
+
+	diags.Append(validateConflictingNodeOptions(ctx, optionsPath, options, "etcd_snapshots", etcdSnapshotsFlags)...)
+
+	return diags
+}
+
+// validateConflictingNodeOptions checks that the node options do not set flags rendered from another attribute.
+func validateConflictingNodeOptions(ctx context.Context, optionsPath path.Path, options types.List, attributeName string, flags map[string]bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if options.IsNull() || options.IsUnknown() {
+		return diags
+	}
+
+	elements := make([]types.String, 0, len(options.Elements()))
+	diags.Append(options.ElementsAs(ctx, &elements, true)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	for index, element := range elements {
+		if element.IsNull() || element.IsUnknown() {
+			continue
+		}
+
+		if flag, ok := parseNodeOptionFlag(element.ValueString()); ok && flags[flag] {
+			diags.AddAttributeError(
+				optionsPath.AtListIndex(index),
+				"Conflicting node option",
+				fmt.Sprintf("The flag %q is set by the %s attribute and cannot also be given as a node option.", flag, attributeName),
+			)
+		}
+	}
+
+	return diags
+}
+
+// validateManifestName checks that the value can be used as a file name in the K3S manifests directory.
+func validateManifestName(attributePath path.Path, value types.String) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if value.IsNull() || value.IsUnknown() {
+		return diags
+	}
+
+	name := value.ValueString()
+	if !manifestNameRegex.MatchString(name) {
+		diags.AddAttributeError(
+			attributePath,
+			"Invalid manifest name",
+			fmt.Sprintf("The manifest name must only contain letters, digits, '.', '_' and '-', got: %q.", name),
+		)
+		return diags
+	}
+
+	if k3sPackagedManifests[name] {
+		diags.AddAttributeError(
+			attributePath,
+			"Reserved manifest name",
+			fmt.Sprintf("The manifest %q is packaged with K3S and overwritten whenever K3S starts, use a HelmChartConfig to customize it instead.", name),
+		)
+	}
+
+	return diags
+}
+
+// validateKubernetesName checks that the value is a valid Kubernetes object name.
+func validateKubernetesName(attributePath path.Path, value types.String) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if value.IsNull() || value.IsUnknown() {
+		return diags
+	}
+
+	if !kubernetesNameRegex.MatchString(value.ValueString()) {
+		diags.AddAttributeError(
+			attributePath,
+			"Invalid name",
+			fmt.Sprintf("The name must consist of lower case alphanumeric characters, '-' or '.', got: %q.", value.ValueString()),
+		)
+	}
+
+	return diags
+}
+
+// validateAbsolutePath checks that the value is an absolute path.
+func validateAbsolutePath(attributePath path.Path, value types.String) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if value.IsNull() || value.IsUnknown() {
+		return diags
+	}
+
+	if !strings.HasPrefix(value.ValueString(), "/") || strings.HasSuffix(value.ValueString(), "/") {
+		diags.AddAttributeError(
+			attributePath,
+			"Invalid path",
+			fmt.Sprintf("The path must be an absolute file path, got: %q.", value.ValueString()),
+		)
+	}
+
+	return diags
+}
+
+// validateFileMode checks that the value is a file mode in octal notation.
+func validateFileMode(attributePath path.Path, value types.String) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if value.IsNull() || value.IsUnknown() {
+		return diags
+	}
+
+	if !fileModeRegex.MatchString(value.ValueString()) {
+		diags.AddAttributeError(
+			attributePath,
+			"Invalid file mode",
+			fmt.Sprintf("The mode must be given in octal notation (e.g. \"0644\"), got: %q.", value.ValueString()),
+		)
+	}
+
+	return diags
+}
+
+// validateFileOwner checks that the value is a valid chown owner.
+func validateFileOwner(attributePath path.Path, value types.String) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if value.IsNull() || value.IsUnknown() {
+		return diags
+	}
+
+	if !fileOwnerRegex.MatchString(value.ValueString()) {
+		diags.AddAttributeError(
+			attributePath,
+			"Invalid file owner",
+			fmt.Sprintf("The owner must follow the format user or user:group, got: %q.", value.ValueString()),
+		)
+	}
+
+	return diags
+}
+
+// validateK3sService checks that the value is a service installed by K3S.
+func validateK3sService(attributePath path.Path, value types.String) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if value.IsNull() || value.IsUnknown() {
+		return diags
+	}
+
+	if !k3sServices[value.ValueString()] {
+		diags.AddAttributeError(
+			attributePath,
+			"Invalid K3S service",
+			fmt.Sprintf("The service must be either k3s or k3s-agent, got: %q.", value.ValueString()),
+		)
+	}
+
+	return diags
+}
+
+// validateYamlContent checks that every document of the value is valid YAML.
+func validateYamlContent(attributePath path.Path, value types.String) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if value.IsNull() || value.IsUnknown() {
+		return diags
+	}
+
+	decoder := yaml.NewDecoder(strings.NewReader(value.ValueString()))
+	for {
+		var document interface{}
+		err := decoder.Decode(&document)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			diags.AddAttributeError(
+				attributePath,
+				"Invalid YAML",
+				fmt.Sprintf("The content must be valid YAML: %s.", err.Error()),
+			)
+			break
+		}
+	}
+
+	return diags
+}
+
+// validateConnectionCredentials checks that exactly one of password or private_key is set, for connections
+// nested in lists where the resource config validators cannot tell the elements apart.
+func validateConnectionCredentials(ctx context.Context, attributePath path.Path, connection types.Object) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if connection.IsNull() || connection.IsUnknown() {
+		return diags
+	}
+
+	var connectionModel model.YoshiK3SConnectionModel
+	diags.Append(connection.As(ctx, &connectionModel, basetypes.ObjectAsOptions{})...)
+	if diags.HasError() || connectionModel.Password.IsUnknown() || connectionModel.PrivateKey.IsUnknown() {
+		return diags
+	}
+
+	if connectionModel.Password.IsNull() == connectionModel.PrivateKey.IsNull() {
+		diags.AddAttributeError(
+			attributePath,
+			"Invalid connection credentials",
+			"Exactly one of password or private_key must be set.",
+		)
+	}
+
+	return diags
+}
+
+func isValidAddress(address string) bool {
+	if net.ParseIP(address) != nil {
+		return true
+	}
+
+	if len(address) == 0 || len(address) > 253 {
+		return false
+	}
+
+	for _, label := range strings.Split(strings.TrimSuffix(address, "."), ".") {
+		if !hostnameLabelRegex.MatchString(label) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseNodeOptionFlag extracts the flag name from options such as
+// "--disable traefik", "--node-label=key=value" or "--cluster-init".
+func parseNodeOptionFlag(option string) (string, bool) {
+	fields := strings.Fields(option)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "-") {
+		return "", false
+	}
+
+	flag := strings.TrimLeft(fields[0], "-")
+	flag, _, _ = strings.Cut(flag, "=")
+	if flag == "" {
+		return "", false
+	}
+
+	return flag, true
+}
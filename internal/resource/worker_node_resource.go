@@ -5,31 +5,43 @@ package resource
 
 import (
 	"context"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/executor"
 	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/model"
-	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/cluster"
-	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/resources"
-	"github.com/HideyoshiNakazone/yoshi-k3s/pkg/ssh_handler"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/providerdata"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/remote"
+	"github.com/hashicorp/terraform-plugin-framework-validators/resourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/identityschema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.Resource = &YoshiK3SWorkerNodeResource{}
 var _ resource.ResourceWithImportState = &YoshiK3SWorkerNodeResource{}
+var _ resource.ResourceWithConfigValidators = &YoshiK3SWorkerNodeResource{}
+var _ resource.ResourceWithValidateConfig = &YoshiK3SWorkerNodeResource{}
+var _ resource.ResourceWithIdentity = &YoshiK3SWorkerNodeResource{}
+var _ resource.ResourceWithUpgradeState = &YoshiK3SWorkerNodeResource{}
+var _ resource.ResourceWithConfigure = &YoshiK3SWorkerNodeResource{}
+var _ resource.ResourceWithModifyPlan = &YoshiK3SWorkerNodeResource{}
 
 func NewYoshiK3SWorkerNodeResource() resource.Resource {
 	return &YoshiK3SWorkerNodeResource{}
 }
 
 // YoshiK3SWorkerNodeResource defines the resource implementation.
-type YoshiK3SWorkerNodeResource struct{}
+type YoshiK3SWorkerNodeResource struct {
+	providerData *providerdata.Data
+}
 
 func (r *YoshiK3SWorkerNodeResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_worker_node"
+	// The identity follows the cluster name, which can be renamed in place.
+	resp.ResourceBehavior.MutableIdentity = true
 }
 
 func (r *YoshiK3SWorkerNodeResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
@@ -37,15 +49,93 @@ func (r *YoshiK3SWorkerNodeResource) Schema(ctx context.Context, req resource.Sc
 		// This description is used by the documentation generator and the language server.
 		MarkdownDescription: "K3S Master Node Resource",
 
+		Version: model.YoshiK3SWorkerNodeResourceModelSchemaVersion,
+
 		Attributes: model.YoshiK3SWorkerNodeResourceModelSchema,
 	}
 }
 
+func (r *YoshiK3SWorkerNodeResource) IdentitySchema(ctx context.Context, req resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = identityschema.Schema{
+		Attributes: model.YoshiK3SNodeIdentitySchema,
+	}
+}
+
+func (r *YoshiK3SWorkerNodeResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		// Version 0 stored the node and cluster ids wrapped in quotes.
+		0: newRawStateUpgrader(unquoteId, unquoteNestedClusterId, nodeAddressId, removeNestedCertificateAuthority),
+		// Version 1 identified the nodes by their host only.
+		1: newRawStateUpgrader(nodeAddressId, removeNestedCertificateAuthority),
+		// Version 2 copied the certificate authorities of the cluster into the worker nodes.
+		2: newRawStateUpgrader(removeNestedCertificateAuthority),
+	}
+}
+
 func (r *YoshiK3SWorkerNodeResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-	//	No configuration is needed for this resource.
+	r.providerData = configureProviderData(req.ProviderData, &resp.Diagnostics)
+}
+
+func (r *YoshiK3SWorkerNodeResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		resourcevalidator.ExactlyOneOf(
+			path.MatchRoot("cluster_id"),
+			path.MatchRoot("cluster"),
+		),
+		resourcevalidator.ExactlyOneOf(
+			path.MatchRoot("node_connection").AtName("password"),
+			path.MatchRoot("node_connection").AtName("private_key"),
+		),
+	}
+}
+
+func (r *YoshiK3SWorkerNodeResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data model.YoshiK3SWorkerNodeResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(validateClusterObject(ctx, path.Root("cluster"), data.Cluster)...)
+	resp.Diagnostics.Append(validateWorkerClusterToken(ctx, path.Root("cluster"), data.Cluster)...)
+	resp.Diagnostics.Append(validateConnectionObject(ctx, path.Root("node_connection"), data.Connection)...)
+	resp.Diagnostics.Append(validateNodeOptions(ctx, path.Root("node_options"), data.Options, isK3sAgentFlag)...)
+}
+
+func (r *YoshiK3SWorkerNodeResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to plan when the resource is being destroyed.
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan model.YoshiK3SWorkerNodeResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	priorCluster := types.ObjectNull(model.YoshiK3SWorkerNodeClusterAttributeTypes)
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("cluster"), &priorCluster)...)
+	}
+
+	cluster, diags := planClusterReference(ctx, r.providerData, plan.ClusterId, plan.Cluster, priorCluster)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("cluster"), cluster)...)
 }
 
 func (r *YoshiK3SWorkerNodeResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = remote.WithCommandLogging(ctx, "yoshik3s_worker_node", "create")
+
 	var data model.YoshiK3SWorkerNodeResourceModel
 
 	// Read Terraform plan data into the model
@@ -55,43 +145,67 @@ func (r *YoshiK3SWorkerNodeResource) Create(ctx context.Context, req resource.Cr
 		return
 	}
 
-	client := r.createClientFromModel(data)
-	if client == nil {
+	var diags diag.Diagnostics
+	data.Cluster, diags = applyClusterReference(ctx, r.providerData, data.ClusterId, data.Cluster)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	install := r.createInstallFromModel(data)
+	if install == nil {
 		resp.Diagnostics.AddError(
 			"Failed to create a master node",
 			"Invalid cluster configuration. Please check the cluster configuration.",
 		)
 		return
 	}
-	nodeConfig := r.createNodeConfigFromModel(data)
-	if nodeConfig == nil {
+	sshConfig := sshConfigFromConnection(ctx, data.Connection)
+	ctx = withConnectionSettings(ctx, r.providerData, sshConfig, data.Connection)
+	if sshConfig == nil {
 		resp.Diagnostics.AddError(
 			"Failed to create a master node",
 			"Invalid node configuration. Please check the node configuration.",
 		)
 		return
 	}
-	options := r.createNodeOptionsFromModel(data)
 
-	err := client.ConfigureWorkerNode(
-		*nodeConfig,
-		options,
-	)
+	unlock, err := lockHosts(ctx, r.providerData, sshConfig)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to lock the node", err.Error())
+		return
+	}
+	defer unlock()
+
+	_, err = nodeExecutor(r.providerData).Install(ctx, sshConfig, *install)
 	if err != nil {
 		resp.Diagnostics.AddError("failed to create a master node", err.Error())
 		return
 	}
+	if dryRunExecutor(r.providerData) != nil {
+		addDryRunError(&resp.Diagnostics)
+		return
+	}
 
 	//// Write logs using the tflog package
 	//// Documentation: https://terraform.io/plugin/log
 	tflog.Trace(ctx, "created a resource")
-	data.Id = types.StringValue(data.Connection.Attributes()["host"].String())
+	data.Id = types.StringValue(nodeId(sshConfig))
 	//
 	//// Save data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	identity, identityDiags := nodeIdentity(ctx, data.Cluster, data.Connection)
+	resp.Diagnostics.Append(identityDiags...)
+	resp.Diagnostics.Append(resp.Identity.Set(ctx, identity)...)
 }
 
 func (r *YoshiK3SWorkerNodeResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = remote.WithCommandLogging(ctx, "yoshik3s_worker_node", "read")
+	ctx, cancel := withReadTimeout(ctx, r.providerData)
+	defer cancel()
+
 	var data model.YoshiK3SWorkerNodeResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
@@ -100,10 +214,46 @@ func (r *YoshiK3SWorkerNodeResource) Read(ctx context.Context, req resource.Read
 		return
 	}
 
+	sshConfig := sshConfigFromConnection(ctx, data.Connection)
+	ctx = withConnectionSettings(ctx, r.providerData, sshConfig, data.Connection)
+
+	pendingDiscovery := isPendingDiscovery(ctx, data.Cluster)
+	nodeInfo := discoverNodeOnRead(ctx, nodeExecutor(r.providerData), sshConfig, data.Cluster, remote.NodeRoleAgent, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if nodeInfo != nil {
+		if nodeInfo.Role == remote.NodeRoleNone {
+			tflog.Warn(ctx, "k3s is no longer installed on the node, removing it from the state")
+			resp.State.RemoveResource(ctx)
+			return
+		}
+
+		var diags diag.Diagnostics
+		data.Cluster, diags = mergeDiscoveredCluster(ctx, data.Cluster, nodeInfo)
+		resp.Diagnostics.Append(diags...)
+
+		if pendingDiscovery && data.Options.IsNull() && len(nodeInfo.Options) > 0 {
+			data.Options, diags = types.ListValueFrom(ctx, types.StringType, nodeInfo.Options)
+			resp.Diagnostics.Append(diags...)
+		}
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	identity, identityDiags := nodeIdentity(ctx, data.Cluster, data.Connection)
+	resp.Diagnostics.Append(identityDiags...)
+	resp.Diagnostics.Append(resp.Identity.Set(ctx, identity)...)
 }
 
 func (r *YoshiK3SWorkerNodeResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = remote.WithCommandLogging(ctx, "yoshik3s_worker_node", "update")
+
 	var data model.YoshiK3SWorkerNodeResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -112,141 +262,173 @@ func (r *YoshiK3SWorkerNodeResource) Update(ctx context.Context, req resource.Up
 		return
 	}
 
-	client := r.createClientFromModel(data)
-	if client == nil {
+	var diags diag.Diagnostics
+	data.Cluster, diags = applyClusterReference(ctx, r.providerData, data.ClusterId, data.Cluster)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var prior model.YoshiK3SWorkerNodeResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The join token is only used to join the cluster, the node is not reinstalled when it alone changes, e.g. when
+	// an expired token is renewed.
+	if onlyJoinTokenChanged(prior, data) {
+		tflog.Debug(ctx, "only the join token changed, keeping the worker node installation")
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
+	}
+
+	install := r.createInstallFromModel(data)
+	if install == nil {
 		resp.Diagnostics.AddError(
 			"Failed to create a master node",
 			"Invalid cluster configuration. Please check the cluster configuration.",
 		)
 		return
 	}
-	nodeConfig := r.createNodeConfigFromModel(data)
-	if nodeConfig == nil {
+	sshConfig := sshConfigFromConnection(ctx, data.Connection)
+	ctx = withConnectionSettings(ctx, r.providerData, sshConfig, data.Connection)
+	if sshConfig == nil {
 		resp.Diagnostics.AddError(
 			"Failed to create a master node",
 			"Invalid node configuration. Please check the node configuration.",
 		)
 		return
 	}
-	options := r.createNodeOptionsFromModel(data)
 
-	err := client.ConfigureWorkerNode(
-		*nodeConfig,
-		options,
-	)
+	unlock, err := lockHosts(ctx, r.providerData, sshConfig)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to lock the node", err.Error())
+		return
+	}
+	defer unlock()
+
+	_, err = nodeExecutor(r.providerData).Install(ctx, sshConfig, *install)
 	if err != nil {
 		resp.Diagnostics.AddError("failed to update master node", err.Error())
 		return
 	}
+	if dryRunExecutor(r.providerData) != nil {
+		resp.State.Raw = req.State.Raw
+		addDryRunError(&resp.Diagnostics)
+		return
+	}
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	identity, identityDiags := nodeIdentity(ctx, data.Cluster, data.Connection)
+	resp.Diagnostics.Append(identityDiags...)
+	resp.Diagnostics.Append(resp.Identity.Set(ctx, identity)...)
 }
 
 func (r *YoshiK3SWorkerNodeResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = remote.WithCommandLogging(ctx, "yoshik3s_worker_node", "delete")
+
 	var data model.YoshiK3SWorkerNodeResourceModel
 
 	// Read Terraform prior state data into the model
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
 
-	client := r.createClientFromModel(data)
-	if client == nil {
+	sshConfig := sshConfigFromConnection(ctx, data.Connection)
+	ctx = withConnectionSettings(ctx, r.providerData, sshConfig, data.Connection)
+	if sshConfig == nil {
 		resp.Diagnostics.AddError(
 			"Failed to create a master node",
-			"Invalid cluster configuration. Please check the cluster configuration.",
+			"Invalid node configuration. Please check the node configuration.",
 		)
 		return
 	}
-	nodeConfig := r.createNodeConfigFromModel(data)
-	if nodeConfig == nil {
-		resp.Diagnostics.AddError(
-			"Failed to create a master node",
-			"Invalid node configuration. Please check the node configuration.",
-		)
+
+	unlock, err := lockHosts(ctx, r.providerData, sshConfig)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to lock the node", err.Error())
 		return
 	}
+	defer unlock()
 
-	err := client.DestroyWorkerNode(
-		*nodeConfig,
-	)
+	err = nodeExecutor(r.providerData).Uninstall(ctx, sshConfig, remote.NodeRoleAgent)
 	if err != nil {
 		resp.Diagnostics.AddError("failed to delete a master node", err.Error())
 		return
 	}
+	if dryRunExecutor(r.providerData) != nil {
+		addDryRunError(&resp.Diagnostics)
+		return
+	}
 
 	if resp.Diagnostics.HasError() {
 		return
 	}
 }
 
+// ImportState accepts IDs in the format `<user>@<host>[:<port>][/<cluster_name>]` or the resource identity,
+// the SSH credentials are read from the environment and the remaining attributes are discovered from the host on Read.
 func (r *YoshiK3SWorkerNodeResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
-}
+	importId, diags := nodeImportIdFromRequest(ctx, req)
+	resp.Diagnostics.Append(diags...)
 
-func (r *YoshiK3SWorkerNodeResource) createClientFromModel(data model.YoshiK3SWorkerNodeResourceModel) *cluster.K3sCluster {
-	if data.Cluster.IsNull() || data.Cluster.IsUnknown() {
-		return nil
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	var clusterModel model.YoshiK3SClusterResourceModel
-	diags := data.Cluster.As(context.Background(), &clusterModel, basetypes.ObjectAsOptions{})
-	if diags.HasError() {
-		return nil
-	}
+	connection, diags := importId.connectionObject()
+	resp.Diagnostics.Append(diags...)
 
-	k3sVersion := clusterModel.ClusterVersion.ValueString()
-	k3sToken := clusterModel.ClusterToken.ValueString()
-	k3sClusterAddress := clusterModel.ClusterAddress.ValueString()
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	return cluster.NewK3sClientWithVersion(k3sVersion, k3sToken, k3sClusterAddress)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), importId.address())...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("node_connection"), connection)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("cluster"), importId.clusterObject(model.YoshiK3SWorkerNodeClusterAttributeTypes))...)
+	if importId.ClusterName != "" {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("cluster_id"), importId.ClusterName)...)
+	}
 }
 
-func (r *YoshiK3SWorkerNodeResource) createNodeConfigFromModel(data model.YoshiK3SWorkerNodeResourceModel) *resources.NodeConfig {
-	return resources.NewNodeConfig(
-		data.Connection.Attributes()["host"].String(),
-		r.createSshConfigFromModel(data),
-	)
+// onlyJoinTokenChanged reports whether the join token is the only attribute of the worker node that changed.
+func onlyJoinTokenChanged(prior model.YoshiK3SWorkerNodeResourceModel, data model.YoshiK3SWorkerNodeResourceModel) bool {
+	return !prior.JoinToken.Equal(data.JoinToken) &&
+		prior.ClusterId.Equal(data.ClusterId) &&
+		prior.Cluster.Equal(data.Cluster) &&
+		prior.Connection.Equal(data.Connection) &&
+		prior.Options.Equal(data.Options)
 }
 
-func (r *YoshiK3SWorkerNodeResource) createSshConfigFromModel(data model.YoshiK3SWorkerNodeResourceModel) *ssh_handler.SshConfig {
-	if data.Connection.IsNull() || data.Connection.IsUnknown() {
+func (r *YoshiK3SWorkerNodeResource) createInstallFromModel(data model.YoshiK3SWorkerNodeResourceModel) *executor.Install {
+	if data.Cluster.IsNull() || data.Cluster.IsUnknown() {
 		return nil
 	}
 
-	var connectionModel model.YoshiK3SConnectionModel
-	diags := data.Connection.As(context.Background(), &connectionModel, basetypes.ObjectAsOptions{})
+	clusterModel, diags := parseClusterObject(context.Background(), data.Cluster)
 	if diags.HasError() {
 		return nil
 	}
 
-	var password string
-	if connectionModel.Password.IsNull() || connectionModel.Password.IsUnknown() {
-		password = ""
-	} else {
-		password = connectionModel.Password.ValueString()
-	}
-
-	var privateKey string
-	if connectionModel.PrivateKey.IsNull() || connectionModel.PrivateKey.IsUnknown() {
-		privateKey = ""
-	} else {
-		privateKey = connectionModel.PrivateKey.ValueString()
+	k3sVersion := clusterModel.ClusterVersion.ValueString()
+	// The join and agent tokens only allow joining worker nodes, the cluster token is kept for clusters without them.
+	k3sToken := clusterModel.ClusterToken.ValueString()
+	if !data.JoinToken.IsNull() && data.JoinToken.ValueString() != "" {
+		k3sToken = data.JoinToken.ValueString()
+	} else if !clusterModel.AgentToken.IsNull() && clusterModel.AgentToken.ValueString() != "" {
+		k3sToken = clusterModel.AgentToken.ValueString()
 	}
+	k3sClusterAddress := clusterModel.ClusterAddress.ValueString()
 
-	var privateKeyPassphrase string
-	if connectionModel.PrivateKeyPassphrase.IsNull() || connectionModel.PrivateKeyPassphrase.IsUnknown() {
-		privateKeyPassphrase = ""
-	} else {
-		privateKeyPassphrase = connectionModel.PrivateKeyPassphrase.ValueString()
+	return &executor.Install{
+		Role:    remote.NodeRoleAgent,
+		Version: k3sVersion,
+		Token:   k3sToken,
+		Address: k3sClusterAddress,
+		Options: r.createNodeOptionsFromModel(data),
 	}
-
-	return ssh_handler.NewSshConfig(
-		connectionModel.Host.ValueString(),
-		connectionModel.Port.ValueString(),
-		connectionModel.User.ValueString(),
-		password,
-		privateKey,
-		privateKeyPassphrase,
-	)
 }
 
 func (r *YoshiK3SWorkerNodeResource) createNodeOptionsFromModel(model model.YoshiK3SWorkerNodeResourceModel) []string {
@@ -0,0 +1,265 @@
+package resource
+
+import (
+	"context"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/model"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/remote"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestWorkerNodeResourceCreate(t *testing.T) {
+	testCases := map[string]struct {
+		agentToken    types.String
+		joinToken     types.String
+		expectedToken string
+	}{
+		"cluster_token": {
+			agentToken:    types.StringNull(),
+			joinToken:     types.StringNull(),
+			expectedToken: testClusterToken,
+		},
+		"agent_token": {
+			agentToken:    types.StringValue("agent-secret"),
+			joinToken:     types.StringNull(),
+			expectedToken: "agent-secret",
+		},
+		"join_token": {
+			agentToken:    types.StringValue("agent-secret"),
+			joinToken:     types.StringValue("K10abc::abcdef.0123456789abcdef"),
+			expectedToken: "K10abc::abcdef.0123456789abcdef",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			server := newK3sServer(t, remote.NodeRoleAgent)
+
+			r := &YoshiK3SWorkerNodeResource{}
+			s := resourceSchema(t, r)
+
+			req := resource.CreateRequest{
+				Plan: newPlan(t, s, map[string]attr.Value{
+					"cluster":         testWorkerCluster(testCase.agentToken),
+					"node_connection": testConnection(server),
+					"node_options":    testOptions("--node-label role=worker"),
+					"join_token":      testCase.joinToken,
+				}),
+			}
+			resp := &resource.CreateResponse{
+				State:    emptyState(s),
+				Identity: emptyIdentity(resourceIdentitySchema(t, r)),
+			}
+
+			r.Create(ctx, req, resp)
+			requireNoErrors(t, resp.Diagnostics)
+
+			install := requireCommand(t, server, `get\.k3s\.io`)
+			for _, expected := range []string{
+				`K3S_TOKEN="` + testCase.expectedToken + `"`,
+				`K3S_URL="https://` + testClusterAddress + `:6443"`,
+				"agent --node-label role=worker",
+			} {
+				if !strings.Contains(install.Command, expected) {
+					t.Errorf("expected the install command to contain %q, got: %s", expected, install.Command)
+				}
+			}
+
+			if id := getStringAttribute(t, resp.State, path.Root("id")); id.ValueString() != net.JoinHostPort(server.Host(), server.Port()) {
+				t.Errorf("expected the id %q, got: %s", net.JoinHostPort(server.Host(), server.Port()), id)
+			}
+		})
+	}
+}
+
+func TestWorkerNodeResourceValidateConfigToken(t *testing.T) {
+	withTokens := func(token types.String, agentToken types.String) types.Object {
+		attributes := testWorkerCluster(agentToken).Attributes()
+		attributes["token"] = token
+		return types.ObjectValueMust(model.YoshiK3SWorkerNodeClusterAttributeTypes, attributes)
+	}
+
+	for name, testCase := range map[string]struct {
+		cluster  types.Object
+		expected bool
+	}{
+		"token": {
+			cluster:  withTokens(types.StringValue(testClusterToken), types.StringNull()),
+			expected: false,
+		},
+		"agent_token": {
+			cluster:  withTokens(types.StringNull(), types.StringValue("agent-secret")),
+			expected: false,
+		},
+		"no token": {
+			cluster:  withTokens(types.StringNull(), types.StringNull()),
+			expected: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := &YoshiK3SWorkerNodeResource{}
+			s := resourceSchema(t, r)
+
+			req := resource.ValidateConfigRequest{
+				Config: tfsdk.Config{
+					Schema: s,
+					Raw: newRaw(t, s, map[string]attr.Value{
+						"cluster":         testCase.cluster,
+						"node_connection": types.ObjectNull(model.YoshiK3SConnectionModelAttributeTypes),
+					}),
+				},
+			}
+			resp := &resource.ValidateConfigResponse{}
+
+			r.ValidateConfig(context.Background(), req, resp)
+			if resp.Diagnostics.HasError() != testCase.expected {
+				t.Errorf("expected an error: %t, got: %v", testCase.expected, resp.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestWorkerNodeResourceRead(t *testing.T) {
+	ctx := context.Background()
+	server := newK3sServer(t, remote.NodeRoleAgent)
+
+	r := &YoshiK3SWorkerNodeResource{}
+	s := resourceSchema(t, r)
+
+	state := newState(t, s, map[string]attr.Value{
+		"id":              types.StringValue(server.Host()),
+		"cluster":         testWorkerCluster(types.StringNull()),
+		"node_connection": testConnection(server),
+	})
+	resp := &resource.ReadResponse{
+		State:    state,
+		Identity: emptyIdentity(resourceIdentitySchema(t, r)),
+	}
+
+	r.Read(ctx, resource.ReadRequest{State: state}, resp)
+	requireNoErrors(t, resp.Diagnostics)
+
+	if resp.State.Raw.IsNull() {
+		t.Fatal("expected the node to be kept in the state")
+	}
+	requireCommand(t, server, `^k3s --version$`)
+}
+
+func TestWorkerNodeResourceReadServer(t *testing.T) {
+	ctx := context.Background()
+	server := newK3sServer(t, remote.NodeRoleServer)
+
+	r := &YoshiK3SWorkerNodeResource{}
+	s := resourceSchema(t, r)
+
+	state := newState(t, s, map[string]attr.Value{
+		"id":              types.StringValue(server.Host()),
+		"cluster":         testWorkerCluster(types.StringNull()),
+		"node_connection": testConnection(server),
+	})
+	resp := &resource.ReadResponse{
+		State:    state,
+		Identity: emptyIdentity(resourceIdentitySchema(t, r)),
+	}
+
+	r.Read(ctx, resource.ReadRequest{State: state}, resp)
+
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected a server installation to be reported on a worker node")
+	}
+}
+
+func TestWorkerNodeResourceUpdate(t *testing.T) {
+	ctx := context.Background()
+	server := newK3sServer(t, remote.NodeRoleAgent)
+
+	r := &YoshiK3SWorkerNodeResource{}
+	s := resourceSchema(t, r)
+
+	attributes := map[string]attr.Value{
+		"id":              types.StringValue(server.Host()),
+		"cluster":         testWorkerCluster(types.StringNull()),
+		"node_connection": testConnection(server),
+	}
+	state := newState(t, s, attributes)
+
+	attributes["node_options"] = testOptions("--node-taint dedicated=gpu:NoSchedule")
+	plan := newPlan(t, s, attributes)
+
+	resp := &resource.UpdateResponse{
+		State:    emptyState(s),
+		Identity: emptyIdentity(resourceIdentitySchema(t, r)),
+	}
+
+	r.Update(ctx, resource.UpdateRequest{Plan: plan, State: state}, resp)
+	requireNoErrors(t, resp.Diagnostics)
+
+	install := requireCommand(t, server, `get\.k3s\.io`)
+	if !strings.HasSuffix(install.Command, "agent --node-taint dedicated=gpu:NoSchedule") {
+		t.Errorf("expected the install command to use the new options, got: %s", install.Command)
+	}
+}
+
+func TestWorkerNodeResourceUpdateJoinToken(t *testing.T) {
+	ctx := context.Background()
+	server := newK3sServer(t, remote.NodeRoleAgent)
+
+	r := &YoshiK3SWorkerNodeResource{}
+	s := resourceSchema(t, r)
+
+	attributes := map[string]attr.Value{
+		"id":              types.StringValue(server.Host()),
+		"cluster":         testWorkerCluster(types.StringNull()),
+		"node_connection": testConnection(server),
+		"join_token":      types.StringValue("K10abc::abcdef.0123456789abcdef"),
+	}
+	state := newState(t, s, attributes)
+
+	// A renewed token does not reinstall the node, which already joined the cluster.
+	attributes["join_token"] = types.StringValue("K10abc::ghijkl.0123456789abcdef")
+	plan := newPlan(t, s, attributes)
+
+	resp := &resource.UpdateResponse{
+		State:    emptyState(s),
+		Identity: emptyIdentity(resourceIdentitySchema(t, r)),
+	}
+
+	r.Update(ctx, resource.UpdateRequest{Plan: plan, State: state}, resp)
+	requireNoErrors(t, resp.Diagnostics)
+
+	if commands := server.Commands(); len(commands) != 0 {
+		t.Errorf("expected no command to run, got: %v", commands)
+	}
+	if token := getStringAttribute(t, resp.State, path.Root("join_token")); token.ValueString() != "K10abc::ghijkl.0123456789abcdef" {
+		t.Errorf("expected the new join token to be stored, got: %s", token)
+	}
+}
+
+func TestWorkerNodeResourceDelete(t *testing.T) {
+	ctx := context.Background()
+	server := newK3sServer(t, remote.NodeRoleAgent)
+
+	r := &YoshiK3SWorkerNodeResource{}
+	s := resourceSchema(t, r)
+
+	state := newState(t, s, map[string]attr.Value{
+		"id":              types.StringValue(server.Host()),
+		"cluster":         testWorkerCluster(types.StringNull()),
+		"node_connection": testConnection(server),
+	})
+	resp := &resource.DeleteResponse{
+		State: state,
+	}
+
+	r.Delete(ctx, resource.DeleteRequest{State: state}, resp)
+	requireNoErrors(t, resp.Diagnostics)
+
+	requireCommand(t, server, `^sudo k3s-agent-uninstall\.sh$`)
+}
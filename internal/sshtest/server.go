@@ -0,0 +1,312 @@
+// Package sshtest provides an in-process SSH server for the unit tests of the resources, recording the
+// commands run by the provider and answering them with scripted outputs instead of executing them.
+package sshtest
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"golang.org/x/crypto/ssh"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	DefaultUser     = "yoshik3s"
+	DefaultPassword = "yoshik3s"
+)
+
+// Response is the scripted output of the commands matching its pattern.
+type Response struct {
+	pattern *regexp.Regexp
+
+	Stdout     string
+	Stderr     string
+	ExitStatus uint32
+	// Delay is waited before answering, e.g. to simulate a host that hangs.
+	Delay time.Duration
+}
+
+// Command is a command received by the server, together with the standard input sent with it.
+type Command struct {
+	Command string
+	Stdin   string
+}
+
+// Server is an SSH server listening on the loopback interface. Every command is answered by the last
+// registered response matching it, or by an empty output and a zero exit status when none does.
+type Server struct {
+	User     string
+	Password string
+
+	listener net.Listener
+	config   *ssh.ServerConfig
+
+	mutex       sync.Mutex
+	commands    []Command
+	responses   []*Response
+	connections map[net.Conn]bool
+	accepted    int
+
+	waitGroup sync.WaitGroup
+}
+
+// NewServer starts a server accepting the DefaultUser and DefaultPassword credentials, it is closed when
+// the test finishes.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate the host key: %s", err)
+	}
+	signer, err := ssh.NewSignerFromKey(hostKey)
+	if err != nil {
+		t.Fatalf("failed to create the host key signer: %s", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %s", err)
+	}
+
+	server := &Server{
+		User:        DefaultUser,
+		Password:    DefaultPassword,
+		listener:    listener,
+		connections: map[net.Conn]bool{},
+	}
+	server.config = &ssh.ServerConfig{
+		PasswordCallback: server.authenticate,
+	}
+	server.config.AddHostKey(signer)
+
+	// Like a host on which the privilege escalation works.
+	server.Respond(`id -un$`, "root\n")
+
+	server.waitGroup.Add(1)
+	go server.serve()
+
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// Host returns the address on which the server listens.
+func (s *Server) Host() string {
+	host, _, _ := net.SplitHostPort(s.listener.Addr().String())
+	return host
+}
+
+// Port returns the port on which the server listens.
+func (s *Server) Port() string {
+	return strconv.Itoa(s.listener.Addr().(*net.TCPAddr).Port)
+}
+
+// Respond answers the commands matching the regular expression with the given standard output.
+func (s *Server) Respond(pattern string, stdout string) *Response {
+	return s.register(&Response{
+		pattern: regexp.MustCompile(pattern),
+		Stdout:  stdout,
+	})
+}
+
+// Fail answers the commands matching the regular expression with the given standard error and exit status.
+func (s *Server) Fail(pattern string, stderr string, exitStatus uint32) *Response {
+	return s.register(&Response{
+		pattern:    regexp.MustCompile(pattern),
+		Stderr:     stderr,
+		ExitStatus: exitStatus,
+	})
+}
+
+// Commands returns the commands received so far, in order.
+func (s *Server) Commands() []Command {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]Command(nil), s.commands...)
+}
+
+// Received returns the commands received so far matching the regular expression.
+func (s *Server) Received(pattern string) []Command {
+	expression := regexp.MustCompile(pattern)
+
+	var matching []Command
+	for _, command := range s.Commands() {
+		if expression.MatchString(command.Command) {
+			matching = append(matching, command)
+		}
+	}
+
+	return matching
+}
+
+// Reset forgets the commands received so far, keeping the responses.
+func (s *Server) Reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.commands = nil
+}
+
+// Accepted returns the number of connections accepted so far.
+func (s *Server) Accepted() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.accepted
+}
+
+// Disconnect closes the open connections, like a host rebooting, and keeps accepting new ones.
+func (s *Server) Disconnect() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for conn := range s.connections {
+		_ = conn.Close()
+	}
+}
+
+// Close stops accepting connections and closes the open ones, which clients may never close themselves.
+func (s *Server) Close() {
+	_ = s.listener.Close()
+
+	s.mutex.Lock()
+	for conn := range s.connections {
+		_ = conn.Close()
+	}
+	s.mutex.Unlock()
+
+	s.waitGroup.Wait()
+}
+
+func (s *Server) register(response *Response) *Response {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.responses = append(s.responses, response)
+	return response
+}
+
+func (s *Server) authenticate(metadata ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+	if metadata.User() == s.User && string(password) == s.Password {
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("invalid credentials for %s", metadata.User())
+}
+
+func (s *Server) serve() {
+	defer s.waitGroup.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mutex.Lock()
+		s.connections[conn] = true
+		s.accepted++
+		s.mutex.Unlock()
+
+		s.waitGroup.Add(1)
+		go s.handleConnection(conn)
+	}
+}
+
+func (s *Server) handleConnection(conn net.Conn) {
+	defer s.waitGroup.Done()
+	defer func() {
+		_ = conn.Close()
+
+		s.mutex.Lock()
+		delete(s.connections, conn)
+		s.mutex.Unlock()
+	}()
+
+	_, channels, requests, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(requests)
+
+	var sessions sync.WaitGroup
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "only sessions are supported")
+			continue
+		}
+
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+
+		sessions.Add(1)
+		go func() {
+			defer sessions.Done()
+			s.handleSession(channel, channelRequests)
+		}()
+	}
+	sessions.Wait()
+}
+
+func (s *Server) handleSession(channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+
+	for request := range requests {
+		switch request.Type {
+		case "pty-req", "env":
+			_ = request.Reply(true, nil)
+		case "exec":
+			var payload struct {
+				Command string
+			}
+			if err := ssh.Unmarshal(request.Payload, &payload); err != nil {
+				_ = request.Reply(false, nil)
+				return
+			}
+			_ = request.Reply(true, nil)
+
+			s.exec(channel, payload.Command)
+			return
+		default:
+			_ = request.Reply(false, nil)
+		}
+	}
+}
+
+func (s *Server) exec(channel ssh.Channel, command string) {
+	// The client closes the standard input once it is sent, reading it entirely before answering
+	// keeps the session from being closed while the client still writes to it.
+	stdin, _ := io.ReadAll(channel)
+
+	s.mutex.Lock()
+	s.commands = append(s.commands, Command{Command: command, Stdin: string(stdin)})
+
+	response := &Response{}
+	for index := len(s.responses) - 1; index >= 0; index-- {
+		if s.responses[index].pattern.MatchString(command) {
+			response = s.responses[index]
+			break
+		}
+	}
+	s.mutex.Unlock()
+
+	time.Sleep(response.Delay)
+
+	_, _ = io.WriteString(channel, response.Stdout)
+	_, _ = io.WriteString(channel.Stderr(), response.Stderr)
+
+	status := struct {
+		Status uint32
+	}{response.ExitStatus}
+	_, _ = channel.SendRequest("exit-status", false, ssh.Marshal(&status))
+}
@@ -9,6 +9,7 @@ import (
 	"log"
 
 	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/provider"
+	"github.com/HideyoshiNakazone/terraform-provider-yoshik3s/internal/remote"
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 )
 
@@ -45,7 +46,11 @@ func main() {
 		Debug:   debug,
 	}
 
-	err := providerserver.Serve(context.Background(), provider.New(version), opts)
+	connections := remote.NewConnectionPool()
+	err := providerserver.Serve(context.Background(), provider.NewWithConnectionPool(version, connections), opts)
+
+	// Serve returns once Terraform is done with the provider, the cached SSH connections are closed before exiting.
+	connections.Close()
 
 	if err != nil {
 		log.Fatal(err.Error())